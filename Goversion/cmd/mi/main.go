@@ -0,0 +1,488 @@
+// Command mi prints the mutual information of two columns of a CSV or TSV
+// file for a range of shifts.
+//
+//	mi [flags] [file]
+//
+// The data is read from file, or from standard input if it is missing or
+// "-". Columns are selected by header name or 0-based index, and the bins
+// span the range of each column. The results are printed as a table, or
+// with -format csv or json for further processing. With -matrix, the MI of
+// all pairs of columns is printed as a CSV matrix instead.
+//
+//	mi serve [-addr localhost:8080] [-root dir]
+//
+// serves the shifted histogram MI over HTTP instead: POST a JSON object
+// with the arrays x and y, or a file below -root and its columns, and the
+// bins, shifts and options to /shifted to get the result as printed by
+// -format json. Invalid input gives status 400 with the error, the
+// offending parameter and its kind. -max-body, -max-shuffles and -max-dcor
+// bound the work a single request can cause.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdszx/modern-mutual-information/Goversion/mutualinfo"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := serve(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "mi:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	var (
+		colX      = flag.String("x", "0", "column of X, header name or 0-based index")
+		colY      = flag.String("y", "1", "column of Y, header name or 0-based index")
+		delimiter = flag.String("delim", "", `field delimiter, e.g. "," or "tab"; guessed from the input if empty`)
+		header    = flag.String("header", "auto", `whether the first line is a header: "yes", "no" or "auto"`)
+		bins      = flag.Int("bins", 10, "number of bins of both axes")
+		binsX     = flag.Int("bins-x", 0, "number of bins of X, overrides -bins")
+		binsY     = flag.Int("bins-y", 0, "number of bins of Y, overrides -bins")
+		shiftFrom = flag.Int("from", -10, "first shift")
+		shiftTo   = flag.Int("to", 10, "last shift")
+		shiftStep = flag.Int("step", 1, "shift step")
+		bias      = flag.String("bias", "none", `bias correction: "none", "miller-madow", "jackknife" or "analytic"`)
+		shuffles  = flag.Int("shuffles", 0, "subtract the mean MI of this many shuffles of Y from every shift")
+		seed      = flag.Int64("seed", 1, "seed of the shuffles; the same seed gives the same results")
+		skip      = flag.Bool("skip", false, "skip lines with a non-numeric value instead of failing")
+		format    = flag.String("format", "table", `output format: "table", "csv" or "json"`)
+		matrix    = flag.Bool("matrix", false, "print the MI matrix of all columns as CSV, using -bins")
+		progress  = flag.Bool("progress", false, "show the progress and remaining time on standard error")
+		corr      = flag.Bool("corr", false, "add the Pearson correlation of every shift")
+		dcor      = flag.Bool("dcor", false, "add the distance correlation of every shift, which takes O(n²) time per shift")
+		plot      = flag.String("plot", "", "also write the MI curve to this file: .svg, .png, .dat for gnuplot or .json for Vega-Lite")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: mi [flags] [file]\n       mi serve [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *binsX == 0 {
+		*binsX = *bins
+	}
+	if *binsY == 0 {
+		*binsY = *bins
+	}
+
+	if *matrix {
+		if err := runMatrix(flag.Arg(0), *delimiter, *header, *bins, *skip, *progress); err != nil {
+			fmt.Fprintln(os.Stderr, "mi:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := run(os.Stdout, flag.Arg(0), *colX, *colY, *delimiter, *header, *binsX, *binsY, *shiftFrom, *shiftTo, *shiftStep, *bias, *shuffles, *seed, *skip, *format, *progress, *corr, *dcor, *plot); err != nil {
+		fmt.Fprintln(os.Stderr, "mi:", err)
+		os.Exit(1)
+	}
+}
+
+// run writes the result of the shift sweep over the columns colX and colY
+// of path to w in the given format.
+func run(w io.Writer, path, colX, colY, delimiter, header string, binsX, binsY, shiftFrom, shiftTo, shiftStep int, bias string, shuffles int, seed int64, skip bool, format string, progress, corr, dcor bool, plot string) error {
+	if format != "table" && format != "csv" && format != "json" {
+		return fmt.Errorf("format must be table, csv or json, not %q", format)
+	}
+	if plot != "" && plotWriter(plot) == nil {
+		return fmt.Errorf("plot file %q must end in .svg, .png, .dat or .json", plot)
+	}
+	input, err := readInput(path)
+	if err != nil {
+		return err
+	}
+	dataX, dataY, err := loadPair(input, path, colX, colY, delimiter, header, skip)
+	if err != nil {
+		return err
+	}
+	minX, maxX, err := columnRange(dataX)
+	if err != nil {
+		return fmt.Errorf("column %s: %v", colX, err)
+	}
+	minY, maxY, err := columnRange(dataY)
+	if err != nil {
+		return fmt.Errorf("column %s: %v", colY, err)
+	}
+	opts := mutualinfo.ShiftOptions{Shuffles: shuffles, ShuffleSeed: seed}
+	if opts.BiasCorrection, err = parseBiasCorrection(bias); err != nil {
+		return err
+	}
+	if progress {
+		opts.Progress = progressBar(os.Stderr, "shifts")
+	}
+	result, err := mutualinfo.ShiftedMutualInformationResult(shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, opts)
+	if err != nil {
+		return err
+	}
+
+	if corr || dcor {
+		if err := result.AddCorrelations(dataX, dataY, dcor); err != nil {
+			return err
+		}
+		if !corr {
+			result.Correlation = nil
+		}
+	}
+	if plot != "" {
+		if err := writePlot(plot, result); err != nil {
+			return err
+		}
+	}
+	return write(w, format, result)
+}
+
+// plotWriter returns the writer of the plot format selected by the
+// extension of path, or nil.
+func plotWriter(path string) func(io.Writer, mutualinfo.Result) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		return func(w io.Writer, r mutualinfo.Result) error { return mutualinfo.WriteSVG(w, r, 800, 450) }
+	case ".png":
+		return func(w io.Writer, r mutualinfo.Result) error { return mutualinfo.WritePNG(w, r, 800, 450) }
+	case ".dat":
+		return mutualinfo.WriteGnuplot
+	case ".json":
+		return mutualinfo.WriteVegaLite
+	}
+	return nil
+}
+
+func writePlot(path string, result mutualinfo.Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := plotWriter(path)(file, result); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// loadPair returns the columns colX and colY of input, read from path.
+func loadPair(input []byte, path, colX, colY, delimiter, header string, skip bool) (dataX, dataY []float64, err error) {
+	comma, err := parseDelimiter(delimiter, path, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	first, err := firstRecord(input, comma)
+	if err != nil {
+		return nil, nil, err
+	}
+	hasHeader, err := detectHeader(header, first, colX, colY)
+	if err != nil {
+		return nil, nil, err
+	}
+	var names []string
+	if hasHeader {
+		names = first
+	}
+	indexX, err := columnIndex(colX, names)
+	if err != nil {
+		return nil, nil, err
+	}
+	indexY, err := columnIndex(colY, names)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mutualinfo.LoadDelimitedColumns(bytes.NewReader(input), comma, indexX, indexY, hasHeader, skip)
+}
+
+// runMatrix prints the MI matrix of all columns with a header row and
+// column of the column names, or of their indices without a header.
+func runMatrix(path, delimiter, header string, bins int, skip, progress bool) error {
+	input, err := readInput(path)
+	if err != nil {
+		return err
+	}
+	comma, err := parseDelimiter(delimiter, path, input)
+	if err != nil {
+		return err
+	}
+	first, err := firstRecord(input, comma)
+	if err != nil {
+		return err
+	}
+	hasHeader, err := detectHeader(header, first, "", "")
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(first))
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+		if hasHeader {
+			names[i] = strings.TrimSpace(first[i])
+		}
+	}
+	columns, err := readTable(input, comma, len(first), hasHeader, skip)
+	if err != nil {
+		return err
+	}
+	var opts mutualinfo.MatrixOptions
+	if progress {
+		opts.Progress = progressBar(os.Stderr, "pairs")
+	}
+	matrix, err := mutualinfo.MutualInformationMatrix(columns, bins, opts)
+	if err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(os.Stdout)
+	out.Write(append([]string{""}, names...))
+	for i, row := range matrix {
+		record := []string{names[i]}
+		for _, mi := range row {
+			record = append(record, strconv.FormatFloat(mi, 'g', -1, 64))
+		}
+		out.Write(record)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// readTable parses all columns of input, which must have width fields per
+// line. Lines with a non-numeric field are skipped if skip and an error
+// otherwise.
+func readTable(input []byte, comma rune, width int, hasHeader, skip bool) ([][]float64, error) {
+	reader := csv.NewReader(bytes.NewReader(input))
+	reader.Comma = comma
+	reader.FieldsPerRecord = width
+	if hasHeader {
+		if _, err := reader.Read(); err != nil {
+			return nil, err
+		}
+	}
+	columns := make([][]float64, width)
+	values := make([]float64, width)
+records:
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return columns, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, field := range record {
+			if values[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+				if skip {
+					continue records
+				}
+				line, _ := reader.FieldPos(i)
+				return nil, fmt.Errorf("line %d: %q is not a number", line, field)
+			}
+		}
+		for i, v := range values {
+			columns[i] = append(columns[i], v)
+		}
+	}
+}
+
+func write(w io.Writer, format string, result mutualinfo.Result) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "csv":
+		out := csv.NewWriter(w)
+		header := []string{"shift", "mi", "hx", "hy", "hxy", "pairs"}
+		if result.Correlation != nil {
+			header = append(header, "r")
+		}
+		if result.DistanceCorrelation != nil {
+			header = append(header, "dcor")
+		}
+		out.Write(header)
+		for i, shift := range result.Shifts {
+			record := []string{
+				strconv.Itoa(shift),
+				strconv.FormatFloat(result.MI[i], 'g', -1, 64),
+				strconv.FormatFloat(result.HX[i], 'g', -1, 64),
+				strconv.FormatFloat(result.HY[i], 'g', -1, 64),
+				strconv.FormatFloat(result.HXY[i], 'g', -1, 64),
+				strconv.Itoa(result.Pairs[i]),
+			}
+			if result.Correlation != nil {
+				record = append(record, strconv.FormatFloat(result.Correlation[i], 'g', -1, 64))
+			}
+			if result.DistanceCorrelation != nil {
+				record = append(record, strconv.FormatFloat(result.DistanceCorrelation[i], 'g', -1, 64))
+			}
+			out.Write(record)
+		}
+		out.Flush()
+		return out.Error()
+	}
+	fmt.Fprintf(w, "%6s  %-9s %-9s %-9s %-9s %-7s", "shift", "mi", "hx", "hy", "hxy", "pairs")
+	if result.Correlation != nil {
+		fmt.Fprintf(w, " %-10s", "r")
+	}
+	if result.DistanceCorrelation != nil {
+		fmt.Fprintf(w, " %-9s", "dcor")
+	}
+	fmt.Fprintln(w)
+	for i, shift := range result.Shifts {
+		fmt.Fprintf(w, "%6d  %.6f  %.6f  %.6f  %.6f  %-7d", shift, result.MI[i], result.HX[i], result.HY[i], result.HXY[i], result.Pairs[i])
+		if result.Correlation != nil {
+			fmt.Fprintf(w, " %+.6f ", result.Correlation[i])
+		}
+		if result.DistanceCorrelation != nil {
+			fmt.Fprintf(w, " %.6f", result.DistanceCorrelation[i])
+		}
+		fmt.Fprintln(w)
+	}
+	if _, err := fmt.Fprintf(w, "peak at shift %d: %.6f\n", result.PeakShift, result.PeakMI); err != nil {
+		return err
+	}
+	// Pairs outside the ranges are skipped, which the table would hide.
+	var outside mutualinfo.RangeCounts
+	worst := 0
+	for i, n := range result.OutOfRange {
+		outside.UnderX += result.Outside[i].UnderX
+		outside.OverX += result.Outside[i].OverX
+		outside.UnderY += result.Outside[i].UnderY
+		outside.OverY += result.Outside[i].OverY
+		if n > result.OutOfRange[worst] {
+			worst = i
+		}
+	}
+	if len(result.OutOfRange) > 0 && result.OutOfRange[worst] > 0 {
+		_, err := fmt.Fprintf(w, "skipped pairs outside the ranges: up to %d per shift; values below/above X %d/%d, Y %d/%d\n",
+			result.OutOfRange[worst], outside.UnderX, outside.OverX, outside.UnderY, outside.OverY)
+		return err
+	}
+	return nil
+}
+
+// progressBar returns a ProgressFunc drawing a bar with the estimated time
+// left on w, redrawn at most ten times a second and ended by a newline.
+func progressBar(w io.Writer, label string) mutualinfo.ProgressFunc {
+	const width = 30
+	start := time.Now()
+	var drawn time.Time
+	return func(done, total int) {
+		now := time.Now()
+		if done < total && now.Sub(drawn) < 100*time.Millisecond {
+			return
+		}
+		drawn = now
+		filled := width * done / total
+		elapsed := now.Sub(start)
+		left := time.Duration(float64(elapsed) * float64(total-done) / float64(done)).Round(time.Second)
+		fmt.Fprintf(w, "\r%s [%s%s] %d/%d, %v left ", label, strings.Repeat("#", filled), strings.Repeat(" ", width-filled), done, total, left)
+		if done == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// parseDelimiter returns the delimiter given by the -delim flag. If it is
+// empty, tabs are assumed for .tsv files and for input whose first line has
+// a tab but no comma, commas otherwise.
+func parseDelimiter(delimiter, path string, input []byte) (rune, error) {
+	switch delimiter {
+	case "":
+		firstLine, _, _ := bytes.Cut(input, []byte("\n"))
+		if strings.HasSuffix(strings.ToLower(path), ".tsv") ||
+			(bytes.IndexByte(firstLine, '\t') >= 0 && bytes.IndexByte(firstLine, ',') < 0) {
+			return '\t', nil
+		}
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	runes := []rune(delimiter)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("delimiter %q must be a single character", delimiter)
+	}
+	return runes[0], nil
+}
+
+func firstRecord(input []byte, comma rune) ([]string, error) {
+	reader := csv.NewReader(bytes.NewReader(input))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	record, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("input is empty")
+	}
+	return record, err
+}
+
+// detectHeader decides whether first is a header. In auto mode it is one if
+// it holds one of the selected column names or a non-numeric field.
+func detectHeader(mode string, first []string, colX, colY string) (bool, error) {
+	switch mode {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	case "auto":
+		for _, field := range first {
+			field = strings.TrimSpace(field)
+			if field == colX || field == colY {
+				return true, nil
+			}
+			if _, err := strconv.ParseFloat(field, 64); err != nil {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("header must be yes, no or auto, not %q", mode)
+}
+
+// columnIndex resolves a column given by header name or 0-based index.
+func columnIndex(column string, names []string) (int, error) {
+	for i, name := range names {
+		if strings.TrimSpace(name) == column {
+			return i, nil
+		}
+	}
+	index, err := strconv.Atoi(column)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("no column %q", column)
+	}
+	return index, nil
+}
+
+// columnRange returns the range of data for the bins, widened around
+// constant data.
+func columnRange(data []float64) (min, max float64, err error) {
+	min, max, err = mutualinfo.DataRange(data)
+	if errors.Is(err, mutualinfo.ErrInvalidRange) {
+		// DataRange rejects the zero range of a single distinct value.
+		for _, v := range data {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				return v - 0.5, v + 0.5, nil
+			}
+		}
+	}
+	return min, max, err
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zdszx/modern-mutual-information/Goversion/mutualinfo"
+)
+
+func TestColumnRange(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		data     []float64
+		min, max float64
+	}{
+		{[]float64{3, 1, 2}, 1, 3},
+		{[]float64{nan, 2, math.Inf(1), 2}, 1.5, 2.5},
+		{[]float64{-1}, -1.5, -0.5},
+	}
+	for _, test := range tests {
+		min, max, err := columnRange(test.data)
+		if err != nil || min != test.min || max != test.max {
+			t.Errorf("columnRange(%v) = %v, %v, %v, want %v, %v", test.data, min, max, err, test.min, test.max)
+		}
+	}
+	if _, _, err := columnRange([]float64{nan, math.Inf(-1)}); !errors.Is(err, mutualinfo.ErrTooFewSamples) {
+		t.Errorf("no finite value: got %v", err)
+	}
+
+	// A constant column also fills a request's missing bound.
+	lower := 0.0
+	if min, max, err := requestRange(&lower, nil, []float64{4, 4}); err != nil || min != 0 || max != 4.5 {
+		t.Errorf("requestRange = %v, %v, %v, want 0, 4.5", min, max, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.csv")
+	// X is set only in the first half and the constant Y only in the second,
+	// so shift 0 has no pairs.
+	input := "x,y\n1,NaN\n2,NaN\n3,NaN\nNaN,5\nNaN,5\nNaN,5\n"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"table", "csv", "json"} {
+		var out bytes.Buffer
+		if err := run(&out, path, "x", "y", "", "auto", 2, 2, -3, 3, 1, "none", 0, 1, false, format, false, true, false, ""); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if format != "json" {
+			continue
+		}
+		var result mutualinfo.Result
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out.Bytes())
+		}
+		if zero := 3; result.Shifts[zero] != 0 || result.Pairs[zero] != 0 || !math.IsNaN(result.MI[zero]) || !math.IsNaN(result.Correlation[zero]) {
+			t.Errorf("shift 0: %d pairs, MI %v, r %v, want 0 pairs and NaN", result.Pairs[zero], result.MI[zero], result.Correlation[zero])
+		}
+	}
+
+	for _, plot := range []string{"curve.svg", "curve.png", "curve.dat", "curve.json"} {
+		plot = filepath.Join(dir, plot)
+		if err := run(&bytes.Buffer{}, path, "x", "y", "", "auto", 2, 2, -3, 3, 1, "none", 0, 1, false, "csv", false, false, false, plot); err != nil {
+			t.Fatalf("%s: %v", plot, err)
+		}
+		if info, err := os.Stat(plot); err != nil || info.Size() == 0 {
+			t.Errorf("%s: no plot written: %v", plot, err)
+		}
+	}
+
+	for _, args := range [][2]string{{"xml", ""}, {"csv", "curve.pdf"}} {
+		err := run(&bytes.Buffer{}, path, "x", "y", "", "auto", 2, 2, -3, 3, 1, "none", 0, 1, false, args[0], false, false, false, args[1])
+		if err == nil || !strings.Contains(err.Error(), "must") {
+			t.Errorf("format %q, plot %q: got %v", args[0], args[1], err)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/zdszx/modern-mutual-information/Goversion/mutualinfo"
+)
+
+// shiftRequest is the body of a POST to /shifted. The data is either given
+// inline as x and y or as a file below the -root directory with the
+// columns column_x and column_y. Ranges that are not given span the data,
+// like on the command line. Only the histogram shift sweep is served; the
+// other estimators of the library are not exposed.
+type shiftRequest struct {
+	X             []float64 `json:"x"`
+	Y             []float64 `json:"y"`
+	File          string    `json:"file"`
+	ColumnX       string    `json:"column_x"`
+	ColumnY       string    `json:"column_y"`
+	Bins          int       `json:"bins"`
+	BinsX         int       `json:"bins_x"`
+	BinsY         int       `json:"bins_y"`
+	MinX          *float64  `json:"min_x"`
+	MaxX          *float64  `json:"max_x"`
+	MinY          *float64  `json:"min_y"`
+	MaxY          *float64  `json:"max_y"`
+	ShiftFrom     int       `json:"shift_from"`
+	ShiftTo       int       `json:"shift_to"`
+	ShiftStep     int       `json:"shift_step"`
+	Normalization string    `json:"normalization"`
+	Unit          string    `json:"unit"`
+	// BiasCorrection and Shuffles debias the MI, see mutualinfo.ShiftOptions,
+	// with the shuffles drawn from Seed.
+	BiasCorrection string `json:"bias_correction"`
+	Shuffles       int    `json:"shuffles"`
+	Seed           int64  `json:"seed"`
+	// Correlation and DistanceCorrelation add the Pearson and distance
+	// correlation of every shift to the result.
+	Correlation         bool `json:"correlation"`
+	DistanceCorrelation bool `json:"distance_correlation"`
+}
+
+// errorResponse is the body of a failed request. Param and Kind are set for
+// invalid input, see mutualinfo.ValidationError.
+type errorResponse struct {
+	Error string `json:"error"`
+	Param string `json:"param,omitempty"`
+	Kind  string `json:"kind,omitempty"`
+}
+
+// serve runs the HTTP service of the serve subcommand.
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	root := flags.String("root", "", "directory of the files requests may refer to; file references are rejected if empty")
+	maxBody := flags.Int64("max-body", 64<<20, "maximum size of a request body in bytes")
+	maxShuffles := flags.Int("max-shuffles", 100, "maximum number of shuffles of a request")
+	maxDcor := flags.Int("max-dcor", 10000, "maximum number of samples of a request with distance_correlation, which takes O(n²) time per shift")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: mi serve [flags]\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	log.Printf("mi: serving on %s", *addr)
+	return http.ListenAndServe(*addr, newHandler(*root, *maxBody, limits{shuffles: *maxShuffles, distanceCorrelation: *maxDcor}))
+}
+
+// limits bounds the work of a request beyond the size of its body.
+type limits struct {
+	// shuffles is the largest number of shuffles.
+	shuffles int
+	// distanceCorrelation is the largest number of samples for which the
+	// distance correlation is calculated.
+	distanceCorrelation int
+}
+
+// newHandler returns the handler of /shifted, reading files below root and
+// request bodies of up to maxBody bytes.
+func newHandler(root string, maxBody int64, lim limits) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shifted", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "use POST"})
+			return
+		}
+		var req shiftRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		result, err := handleShift(req, root, lim)
+		if err != nil {
+			writeJSON(w, errorStatus(err), errorOf(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+	return mux
+}
+
+// errorStatus returns 400 for invalid input and 500 for other errors.
+func errorStatus(err error) int {
+	var verr *mutualinfo.ValidationError
+	var rerr requestError
+	if errors.As(err, &verr) || errors.As(err, &rerr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// errorOf returns the response of a failed request, with the parameter and
+// kind of a ValidationError.
+func errorOf(err error) errorResponse {
+	var verr *mutualinfo.ValidationError
+	if errors.As(err, &verr) {
+		return errorResponse{Error: err.Error(), Param: verr.Param, Kind: verr.Kind.Error()}
+	}
+	return errorResponse{Error: err.Error()}
+}
+
+// requestError is an error in a request that is not a ValidationError of
+// the library, e.g. an unknown file.
+type requestError string
+
+func (e requestError) Error() string {
+	return string(e)
+}
+
+// handleShift returns the result of req, refusing requests beyond lim.
+func handleShift(req shiftRequest, root string, lim limits) (mutualinfo.Result, error) {
+	if req.Shuffles > lim.shuffles {
+		return mutualinfo.Result{}, requestError(fmt.Sprintf("shuffles must be at most %d", lim.shuffles))
+	}
+	dataX, dataY := req.X, req.Y
+	if req.File != "" {
+		if dataX != nil || dataY != nil {
+			return mutualinfo.Result{}, requestError("give either x and y or a file")
+		}
+		if root == "" {
+			return mutualinfo.Result{}, requestError("file references are disabled, start the server with -root")
+		}
+		if !filepath.IsLocal(req.File) {
+			return mutualinfo.Result{}, requestError("file must be a relative path below the root")
+		}
+		path := filepath.Join(root, req.File)
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return mutualinfo.Result{}, requestError(fmt.Sprintf("cannot read file %q", req.File))
+		}
+		colX, colY := req.ColumnX, req.ColumnY
+		if colX == "" {
+			colX = "0"
+		}
+		if colY == "" {
+			colY = "1"
+		}
+		if dataX, dataY, err = loadPair(input, path, colX, colY, "", "auto", false); err != nil {
+			return mutualinfo.Result{}, requestError(err.Error())
+		}
+	}
+
+	if req.DistanceCorrelation && len(dataX) > lim.distanceCorrelation {
+		return mutualinfo.Result{}, requestError(fmt.Sprintf("distance_correlation is limited to %d samples", lim.distanceCorrelation))
+	}
+
+	binsX, binsY := req.BinsX, req.BinsY
+	if req.Bins == 0 {
+		req.Bins = 10
+	}
+	if binsX == 0 {
+		binsX = req.Bins
+	}
+	if binsY == 0 {
+		binsY = req.Bins
+	}
+	if req.ShiftStep == 0 {
+		req.ShiftStep = 1
+	}
+	minX, maxX, err := requestRange(req.MinX, req.MaxX, dataX)
+	if err != nil {
+		return mutualinfo.Result{}, fmt.Errorf("x: %w", err)
+	}
+	minY, maxY, err := requestRange(req.MinY, req.MaxY, dataY)
+	if err != nil {
+		return mutualinfo.Result{}, fmt.Errorf("y: %w", err)
+	}
+	opts := mutualinfo.ShiftOptions{Shuffles: req.Shuffles, ShuffleSeed: req.Seed}
+	if opts.BiasCorrection, err = parseBiasCorrection(req.BiasCorrection); err != nil {
+		return mutualinfo.Result{}, err
+	}
+	if opts.Normalization, err = parseNormalization(req.Normalization); err != nil {
+		return mutualinfo.Result{}, err
+	}
+	if opts.Unit, err = parseUnit(req.Unit); err != nil {
+		return mutualinfo.Result{}, err
+	}
+	result, err := mutualinfo.ShiftedMutualInformationResult(req.ShiftFrom, req.ShiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, req.ShiftStep, opts)
+	if err != nil {
+		return mutualinfo.Result{}, err
+	}
+	if req.Correlation || req.DistanceCorrelation {
+		if err := result.AddCorrelations(dataX, dataY, req.DistanceCorrelation); err != nil {
+			return mutualinfo.Result{}, err
+		}
+		if !req.Correlation {
+			result.Correlation = nil
+		}
+	}
+	return result, nil
+}
+
+// requestRange returns the range given in a request, with the bounds that
+// are missing taken from the range of data.
+func requestRange(min, max *float64, data []float64) (float64, float64, error) {
+	if min != nil && max != nil {
+		return *min, *max, nil
+	}
+	dataMin, dataMax, err := columnRange(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min != nil {
+		dataMin = *min
+	}
+	if max != nil {
+		dataMax = *max
+	}
+	return dataMin, dataMax, nil
+}
+
+func parseNormalization(name string) (mutualinfo.Normalization, error) {
+	if name == "" {
+		return mutualinfo.NormalizationNone, nil
+	}
+	for n := mutualinfo.NormalizationNone; n <= mutualinfo.NormalizationRedundancy; n++ {
+		if n.String() == name {
+			return n, nil
+		}
+	}
+	return 0, requestError(fmt.Sprintf("unknown normalization %q", name))
+}
+
+func parseBiasCorrection(name string) (mutualinfo.BiasCorrection, error) {
+	if name == "" {
+		return mutualinfo.BiasNone, nil
+	}
+	for c := mutualinfo.BiasNone; c <= mutualinfo.BiasAnalytic; c++ {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return 0, requestError(fmt.Sprintf("unknown bias correction %q", name))
+}
+
+func parseUnit(name string) (mutualinfo.Unit, error) {
+	if name == "" {
+		return mutualinfo.UnitBits, nil
+	}
+	for u := mutualinfo.UnitBits; u <= mutualinfo.UnitDits; u++ {
+		if u.String() == name {
+			return u, nil
+		}
+	}
+	return 0, requestError(fmt.Sprintf("unknown unit %q", name))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(errorResponse{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
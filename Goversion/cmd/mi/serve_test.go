@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zdszx/modern-mutual-information/Goversion/mutualinfo"
+)
+
+var testLimits = limits{shuffles: 10, distanceCorrelation: 100}
+
+func TestHandleShift(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.csv"), []byte("a,b\n1,2\n2,1\n3,4\n4,3\n5,5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(root), "secret.csv"), []byte("1,2\n2,1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile, err := handleShift(shiftRequest{File: "data.csv", ColumnX: "a", ColumnY: "b", Bins: 2, ShiftFrom: -1, ShiftTo: 1}, root, testLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inline, err := handleShift(shiftRequest{X: []float64{1, 2, 3, 4, 5}, Y: []float64{2, 1, 4, 3, 5}, Bins: 2, ShiftFrom: -1, ShiftTo: 1}, "", testLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range inline.MI {
+		if fromFile.MI[i] != inline.MI[i] {
+			t.Errorf("shift %d: MI %v from the file, %v inline", inline.Shifts[i], fromFile.MI[i], inline.MI[i])
+		}
+	}
+
+	for name, test := range map[string]struct {
+		req  shiftRequest
+		root string
+	}{
+		"no root":        {shiftRequest{File: "data.csv"}, ""},
+		"parent":         {shiftRequest{File: "../secret.csv"}, root},
+		"nested parent":  {shiftRequest{File: "sub/../../secret.csv"}, root},
+		"absolute":       {shiftRequest{File: filepath.Join(root, "data.csv")}, root},
+		"missing file":   {shiftRequest{File: "missing.csv"}, root},
+		"file and x":     {shiftRequest{File: "data.csv", X: []float64{1}}, root},
+		"unknown column": {shiftRequest{File: "data.csv", ColumnX: "c"}, root},
+		"unknown unit":   {shiftRequest{X: []float64{1, 2}, Y: []float64{1, 2}, Unit: "bytes"}, root},
+		"shuffles":       {shiftRequest{X: []float64{1, 2}, Y: []float64{1, 2}, Shuffles: 11}, root},
+		"dcor size":      {shiftRequest{X: make([]float64, 101), Y: make([]float64, 101), DistanceCorrelation: true}, root},
+	} {
+		_, err := handleShift(test.req, test.root, testLimits)
+		var rerr requestError
+		if !errors.As(err, &rerr) {
+			t.Errorf("%s: got %v, want a requestError", name, err)
+		}
+	}
+
+	_, err = handleShift(shiftRequest{X: []float64{1, 2, 3}, Y: []float64{1, 2}}, "", testLimits)
+	if !errors.Is(err, mutualinfo.ErrSizeMismatch) {
+		t.Errorf("different lengths: got %v", err)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	internal := errors.New("write failed")
+	for err, want := range map[error]int{
+		requestError("unknown file"): http.StatusBadRequest,
+		internal:                     http.StatusInternalServerError,
+	} {
+		if got := errorStatus(err); got != want {
+			t.Errorf("errorStatus(%v) = %d, want %d", err, got, want)
+		}
+	}
+	_, err := handleShift(shiftRequest{X: []float64{1, 2}, Y: []float64{1, 2}, Bins: -1}, "", testLimits)
+	if got := errorStatus(err); got != http.StatusBadRequest {
+		t.Errorf("errorStatus(%v) = %d, want 400", err, got)
+	}
+	if resp := errorOf(err); resp.Param == "" || resp.Kind != mutualinfo.ErrInvalidBins.Error() {
+		t.Errorf("errorOf(%v) = %+v", err, resp)
+	}
+}
+
+func TestServeShifted(t *testing.T) {
+	server := httptest.NewServer(newHandler(t.TempDir(), 1<<10, testLimits))
+	defer server.Close()
+	post := func(body string) (*http.Response, []byte) {
+		t.Helper()
+		resp, err := http.Post(server.URL+"/shifted", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var raw json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+			t.Fatalf("%s: invalid JSON response: %v", body, err)
+		}
+		return resp, raw
+	}
+
+	// X is in range only in its first half and Y only in its second, so
+	// that shift 0 has no pairs and its values are null.
+	resp, body := post(`{"x":[0.2,0.8,9,9],"y":[9,9,0.3,0.7],"min_x":0,"max_x":1,"min_y":0,"max_y":1,"bins":2,"shift_from":-2,"shift_to":2}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %s", resp.StatusCode, body)
+	}
+	var result mutualinfo.Result
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatal(err)
+	}
+	if zero := 2; result.Shifts[zero] != 0 || result.Pairs[zero] != 0 || !math.IsNaN(result.MI[zero]) {
+		t.Errorf("shift %d: %d pairs, MI %v, want 0 pairs and NaN", result.Shifts[zero], result.Pairs[zero], result.MI[zero])
+	}
+
+	for _, test := range []struct {
+		body   string
+		status int
+		kind   string
+	}{
+		{`{"x":[1,2],"y":[1,2],"bins":2,"unknown":1}`, http.StatusBadRequest, ""},
+		{`{"x":[` + strings.Repeat("1,", 1<<10) + `1]}`, http.StatusBadRequest, ""},
+		{`{"file":"../data.csv"}`, http.StatusBadRequest, ""},
+		{`{"x":[1,2],"y":[1,2],"bins":-1}`, http.StatusBadRequest, mutualinfo.ErrInvalidBins.Error()},
+	} {
+		resp, body := post(test.body)
+		var failure errorResponse
+		json.Unmarshal(body, &failure)
+		if resp.StatusCode != test.status || failure.Error == "" || failure.Kind != test.kind {
+			t.Errorf("%.40s: status %d, %+v, want %d with kind %q", test.body, resp.StatusCode, failure, test.status, test.kind)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/shifted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET: status %d, Allow %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/zdszx/modern-mutual-information/Goversion
+
+go 1.21
@@ -0,0 +1,146 @@
+package mutualinfo
+
+import (
+	"math"
+	"sort"
+)
+
+// adaptiveChiSquare3 is the 95% quantile of the chi-square distribution with
+// three degrees of freedom, the threshold of the uniformity test of the four
+// subcells of a cell.
+const adaptiveChiSquare3 = 7.814727903251178
+
+// tiedRanks returns for every value of data the 0-based rank of the first
+// of the values tied with it, and for every rank 0..len(data) whether a
+// group of tied values starts there.
+func tiedRanks(data []float64) (ranks []int, starts []bool) {
+	order := make([]int, len(data))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return data[order[a]] < data[order[b]] })
+	ranks = make([]int, len(data))
+	starts = make([]bool, len(data)+1)
+	starts[len(data)] = true
+	first := 0
+	for r, i := range order {
+		if r == 0 || data[i] != data[order[r-1]] {
+			first = r
+			starts[r] = true
+		}
+		ranks[i] = first
+	}
+	return ranks, starts
+}
+
+// splitRank returns the start of a group of ties closest to the midpoint of
+// the ranks lo..hi, lo and hi being group starts themselves, and false if
+// lo..hi is a single group that cannot be split.
+func splitRank(starts []bool, lo, hi int) (int, bool) {
+	mid := (lo + hi) / 2
+	for d := 0; mid-d > lo || mid+d < hi; d++ {
+		if mid-d > lo && starts[mid-d] {
+			return mid - d, true
+		}
+		if mid+d > lo && mid+d < hi && starts[mid+d] {
+			return mid + d, true
+		}
+	}
+	return 0, false
+}
+
+// AdaptiveMutualInformation estimates the mutual information in bits with
+// the adaptive partitioning of Darbellay and Vajda, which needs no bins.
+// The data is replaced by its ranks, so that both marginals are uniform, and
+// the rank square is split recursively into quarters at the midpoints of
+// each cell. A cell is split further as long as its pairs are not spread
+// over its quarters in proportion to their sizes by a chi-square test at
+// the 5% level, so that the partition is fine where the joint distribution
+// has structure and coarse elsewhere. Tied values share their ranks and are
+// never split apart: the split moves to the closest boundary between ties,
+// and a cell spanning a single value on either axis is not split, so a
+// constant series has MI 0.
+func AdaptiveMutualInformation(dataX, dataY []float64) (float64, error) {
+	if len(dataX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if len(dataX) < 4 {
+		return 0, invalid(ErrTooFewSamples, "data", "there must be at least four samples")
+	}
+	for i := range dataX {
+		if math.IsNaN(dataX[i]) || math.IsNaN(dataY[i]) {
+			return 0, invalid(ErrInvalidData, "dataX", "dataX and dataY must not hold NaN")
+		}
+	}
+
+	n := len(dataX)
+	ranksX, startsX := tiedRanks(dataX)
+	ranksY, startsY := tiedRanks(dataY)
+	points := make([]int, n)
+	for i := range points {
+		points[i] = i
+	}
+	var mi neumaierSum
+	leaf := func(count, x0, x1, y0, y1 int) {
+		p := float64(count) / float64(n)
+		px, py := float64(x1-x0)/float64(n), float64(y1-y0)/float64(n)
+		mi.Add(p * math.Log2(p/(px*py)))
+	}
+	var split func(points []int, x0, x1, y0, y1 int, first bool)
+	split = func(points []int, x0, x1, y0, y1 int, first bool) {
+		count := len(points)
+		if count == 0 {
+			return
+		}
+		midX, splitX := splitRank(startsX, x0, x1)
+		midY, splitY := splitRank(startsY, y0, y1)
+		if !splitX || !splitY {
+			leaf(count, x0, x1, y0, y1)
+			return
+		}
+		// Partition the points in place into the quarters
+		// [lowX lowY | lowX highY | highX lowY | highX highY].
+		lowX := partitionPoints(points, func(i int) bool { return ranksX[i] < midX })
+		lowXLowY := partitionPoints(points[:lowX], func(i int) bool { return ranksY[i] < midY })
+		highXLowY := partitionPoints(points[lowX:], func(i int) bool { return ranksY[i] < midY })
+		quarters := [4][]int{points[:lowXLowY], points[lowXLowY:lowX], points[lowX : lowX+highXLowY], points[lowX+highXLowY:]}
+
+		if !first {
+			// Under uniformity each quarter holds the share of the pairs
+			// given by its width and height.
+			fx := float64(midX-x0) / float64(x1-x0)
+			fy := float64(midY-y0) / float64(y1-y0)
+			var statistic float64
+			for k, share := range [4]float64{fx * fy, fx * (1 - fy), (1 - fx) * fy, (1 - fx) * (1 - fy)} {
+				expected := float64(count) * share
+				d := float64(len(quarters[k])) - expected
+				statistic += d * d / expected
+			}
+			if statistic <= adaptiveChiSquare3 {
+				leaf(count, x0, x1, y0, y1)
+				return
+			}
+		}
+		split(quarters[0], x0, midX, y0, midY, false)
+		split(quarters[1], x0, midX, midY, y1, false)
+		split(quarters[2], midX, x1, y0, midY, false)
+		split(quarters[3], midX, x1, midY, y1, false)
+	}
+	// The whole square is uniform in each marginal by construction, so it is
+	// always split once if it can be.
+	split(points, 0, n, 0, n, true)
+	return math.Max(mi.Value(), 0), nil
+}
+
+// partitionPoints reorders points so that those satisfying low come first
+// and returns their number.
+func partitionPoints(points []int, low func(i int) bool) int {
+	k := 0
+	for j, i := range points {
+		if low(i) {
+			points[j], points[k] = points[k], points[j]
+			k++
+		}
+	}
+	return k
+}
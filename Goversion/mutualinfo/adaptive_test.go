@@ -0,0 +1,73 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAdaptiveMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(45))
+	for _, rho := range []float64{0, 0.6, 0.9} {
+		dataX := make([]float64, 5000)
+		dataY := make([]float64, 5000)
+		for i := range dataX {
+			dataX[i] = rng.NormFloat64()
+			dataY[i] = rho*dataX[i] + math.Sqrt(1-rho*rho)*rng.NormFloat64()
+		}
+		got, err := AdaptiveMutualInformation(dataX, dataY)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := GaussianMutualInformation(rho)
+		if !almostEqual(got, want, 0.06) {
+			t.Errorf("rho=%v: estimate %v, analytic %v", rho, got, want)
+		}
+	}
+
+	// Ranks make the estimate invariant under monotone transformations.
+	dataX := make([]float64, 1000)
+	dataY := make([]float64, 1000)
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+		dataY[i] = math.Sin(3*dataX[i]) + 0.2*rng.NormFloat64()
+	}
+	mi, _ := AdaptiveMutualInformation(dataX, dataY)
+	for i := range dataX {
+		dataX[i] = math.Exp(dataX[i])
+	}
+	if transformed, _ := AdaptiveMutualInformation(dataX, dataY); transformed != mi {
+		t.Errorf("MI %v changed to %v under exp", mi, transformed)
+	}
+	if mi < 0.5 {
+		t.Errorf("MI %v of a non-monotone dependence, want clearly positive", mi)
+	}
+
+	// Ties must not turn the order of the samples into dependence: a
+	// constant series against a trend has MI 0 and four equally frequent
+	// labels that follow the trend have 2 bits.
+	constant := make([]float64, 1000)
+	labels := make([]float64, 1000)
+	trend := make([]float64, 1000)
+	for i := range trend {
+		constant[i] = 1
+		labels[i] = float64(i / 250)
+		trend[i] = float64(i)
+	}
+	if mi, err := AdaptiveMutualInformation(constant, trend); err != nil || mi != 0 {
+		t.Errorf("constant against a trend: MI %v, %v, want 0", mi, err)
+	}
+	if mi, err := AdaptiveMutualInformation(labels, trend); err != nil || !almostEqual(mi, 2, 1e-12) {
+		t.Errorf("labels against a trend: MI %v, %v, want 2", mi, err)
+	}
+	if mi, _ := AdaptiveMutualInformation(trend, labels); !almostEqual(mi, 2, 1e-12) {
+		t.Errorf("trend against labels: MI %v, want 2", mi)
+	}
+
+	if _, err := AdaptiveMutualInformation([]float64{1, 2, 3}, []float64{1, 2, 3}); err == nil {
+		t.Error("expected error for fewer than four samples")
+	}
+	if _, err := AdaptiveMutualInformation([]float64{1, 2, 3, math.NaN()}, []float64{1, 2, 3, 4}); err == nil {
+		t.Error("expected error for NaN")
+	}
+}
@@ -0,0 +1,61 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// GaussianMutualInformation returns the analytic mutual information in bits
+// of a bivariate normal distribution with correlation coefficient rho.
+func GaussianMutualInformation(rho float64) (float64, error) {
+	if math.IsNaN(rho) || rho <= -1 || rho >= 1 {
+		return 0, invalid(ErrInvalidParameter, "rho", "rho has to be in the open interval (-1, 1)")
+	}
+	return -0.5 * math.Log2(1-rho*rho), nil
+}
+
+// MutualInformationFromJoint returns the mutual information in bits of a
+// joint distribution given as a matrix of non-negative weights. The matrix
+// does not need to be normalized.
+func MutualInformationFromJoint(joint [][]float64) (float64, error) {
+	if len(joint) == 0 || len(joint[0]) == 0 {
+		return 0, invalid(ErrTooFewSamples, "joint", "joint distribution must not be empty")
+	}
+	cols := len(joint[0])
+	var totalSum neumaierSum
+	for _, row := range joint {
+		if len(row) != cols {
+			return 0, invalid(ErrSizeMismatch, "joint", "joint distribution must be rectangular")
+		}
+		for _, p := range row {
+			if p < 0 || math.IsNaN(p) || math.IsInf(p, 0) {
+				return 0, invalid(ErrInvalidData, "joint", "joint distribution must only contain finite non-negative values")
+			}
+			totalSum.Add(p)
+		}
+	}
+	total := totalSum.Value()
+	if total == 0 {
+		return 0, invalid(ErrInvalidData, "joint", "joint distribution must not sum to zero")
+	}
+
+	pxSum := make([]neumaierSum, len(joint))
+	pySum := make([]neumaierSum, cols)
+	for i, row := range joint {
+		for j, p := range row {
+			pxSum[i].Add(p)
+			pySum[j].Add(p)
+		}
+	}
+
+	var mi neumaierSum
+	for i, row := range joint {
+		px := pxSum[i].Value() / total
+		for j, p := range row {
+			pxy := p / total
+			if pxy != 0 {
+				mi.Add(pxy * math.Log2(pxy/(px*(pySum[j].Value()/total))))
+			}
+		}
+	}
+	return mi.Value(), nil
+}
@@ -0,0 +1,69 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAlmostEqual(t *testing.T) {
+	if !almostEqual(1.0, 1.0+1e-12, 1e-9) {
+		t.Error("expected values within tolerance to be equal")
+	}
+	if almostEqual(1.0, 1.1, 1e-9) {
+		t.Error("expected values outside tolerance to differ")
+	}
+}
+
+func TestGaussianMutualInformation(t *testing.T) {
+	mi, err := GaussianMutualInformation(0)
+	if err != nil || !almostEqual(mi, 0, 1e-12) {
+		t.Errorf("rho=0: got %v, %v", mi, err)
+	}
+	mi, err = GaussianMutualInformation(math.Sqrt(0.75))
+	if err != nil || !almostEqual(mi, 1, 1e-12) {
+		t.Errorf("rho=sqrt(3/4): got %v, %v", mi, err)
+	}
+	if _, err := GaussianMutualInformation(1); err == nil {
+		t.Error("expected error for rho=1")
+	}
+}
+
+func TestMutualInformationFromJoint(t *testing.T) {
+	mi, err := MutualInformationFromJoint([][]float64{{1, 0}, {0, 1}})
+	if err != nil || !almostEqual(mi, 1, 1e-12) {
+		t.Errorf("perfect coupling: got %v, %v", mi, err)
+	}
+	mi, err = MutualInformationFromJoint([][]float64{{1, 1}, {1, 1}})
+	if err != nil || !almostEqual(mi, 0, 1e-12) {
+		t.Errorf("independent: got %v, %v", mi, err)
+	}
+	if _, err := MutualInformationFromJoint([][]float64{{0, 0}}); err == nil {
+		t.Error("expected error for zero total")
+	}
+	if _, err := MutualInformationFromJoint([][]float64{{1, 0}, {1}}); err == nil {
+		t.Error("expected error for ragged matrix")
+	}
+}
+
+func TestHistogramMatchesJoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	hist := NewHistogram2D(8, 8, 0, 1, 0, 1)
+	for i := 0; i < 5000; i++ {
+		hist.Increment(rng.Float64(), rng.Float64())
+	}
+	joint := make([][]float64, hist.BinsX)
+	for i := range joint {
+		joint[i] = make([]float64, hist.BinsY)
+		for j := range joint[i] {
+			joint[i][j] = float64(hist.Data[i][j])
+		}
+	}
+	want, err := MutualInformationFromJoint(joint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hist.CalculateMutualInformation(); !almostEqual(got, want, 1e-9) {
+		t.Errorf("histogram MI %v differs from joint MI %v", got, want)
+	}
+}
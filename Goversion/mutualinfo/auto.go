@@ -0,0 +1,93 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// Estimator identifies the method used to estimate mutual information.
+type Estimator int
+
+const (
+	EstimatorHistogram Estimator = iota
+	EstimatorGaussianCopula
+	EstimatorKSG
+	EstimatorAdaptive
+)
+
+func (e Estimator) String() string {
+	switch e {
+	case EstimatorHistogram:
+		return "histogram"
+	case EstimatorGaussianCopula:
+		return "gaussian-copula"
+	case EstimatorKSG:
+		return "ksg"
+	case EstimatorAdaptive:
+		return "adaptive"
+	}
+	return "unknown"
+}
+
+// ksgDefaultNeighbors is the k of the KSG estimate of Estimator.Estimate.
+const ksgDefaultNeighbors = 3
+
+// Estimate estimates the mutual information of dataX and dataY in bits
+// with e and parameters derived from the data: the bins of
+// AutoEstimatorWithChoice for EstimatorHistogram and k = 3 for EstimatorKSG,
+// see KSGMutualInformation and AdaptiveMutualInformation.
+func (e Estimator) Estimate(dataX, dataY []float64) (float64, error) {
+	switch e {
+	case EstimatorHistogram:
+		if len(dataX) != len(dataY) {
+			return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+		}
+		return rangedMutualInformation(autoEstimatorBins(len(dataX)), dataX, dataY)
+	case EstimatorGaussianCopula:
+		return GaussianCopulaMutualInformation(dataX, dataY)
+	case EstimatorKSG:
+		return KSGMutualInformation(dataX, dataY, ksgDefaultNeighbors)
+	case EstimatorAdaptive:
+		return AdaptiveMutualInformation(dataX, dataY)
+	}
+	return 0, invalid(ErrInvalidOption, "estimator", "unknown estimator")
+}
+
+// autoEstimatorBins returns the bins per axis for n samples, about five
+// samples per joint cell.
+func autoEstimatorBins(n int) int {
+	return maxInt(1, int(math.Sqrt(float64(n)/5)))
+}
+
+// autoEstimatorMinHistogramSamples is the sample size from which
+// AutoEstimator bins the data.
+const autoEstimatorMinHistogramSamples = 500
+
+// AutoEstimator estimates the mutual information of dataX and dataY without
+// requiring any estimator parameters. See AutoEstimatorWithChoice for the
+// decision rule.
+func AutoEstimator(dataX, dataY []float64) (float64, error) {
+	mi, _, err := AutoEstimatorWithChoice(dataX, dataY)
+	return mi, err
+}
+
+// AutoEstimatorWithChoice is AutoEstimator that also reports the estimator used.
+//
+// Below 500 samples histograms are dominated by finite-sample bias, so the
+// Gaussian-copula estimate is returned. From 500 samples on the data is
+// binned into floor(sqrt(N/5)) bins per axis spanning the data range, which
+// leaves about five samples per joint cell and also captures non-monotone
+// dependence that the copula estimate misses.
+func AutoEstimatorWithChoice(dataX, dataY []float64) (float64, Estimator, error) {
+	if len(dataX) != len(dataY) {
+		return 0, 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if len(dataX) < 2 {
+		return 0, 0, invalid(ErrTooFewSamples, "data", "there must be at least two samples")
+	}
+	if len(dataX) < autoEstimatorMinHistogramSamples {
+		mi, err := GaussianCopulaMutualInformation(dataX, dataY)
+		return mi, EstimatorGaussianCopula, err
+	}
+	mi, err := rangedMutualInformation(autoEstimatorBins(len(dataX)), dataX, dataY)
+	return mi, EstimatorHistogram, err
+}
@@ -0,0 +1,53 @@
+package mutualinfo
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestAutoEstimator(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	small := make([]float64, 100)
+	for i := range small {
+		small[i] = rng.NormFloat64()
+	}
+	_, estimator, err := AutoEstimatorWithChoice(small, small)
+	if err != nil || estimator != EstimatorGaussianCopula {
+		t.Errorf("small sample: got %v, %v", estimator, err)
+	}
+
+	dataX := make([]float64, 5000)
+	dataY := make([]float64, 5000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()*2 - 1
+		// Non-monotone dependence is invisible to the copula estimate.
+		dataY[i] = dataX[i] * dataX[i]
+	}
+	mi, estimator, err := AutoEstimatorWithChoice(dataX, dataY)
+	if err != nil || estimator != EstimatorHistogram {
+		t.Fatalf("large sample: got %v, %v", estimator, err)
+	}
+	if mi < 1 {
+		t.Errorf("expected strong dependence, got %v", mi)
+	}
+	if estimator.String() != "histogram" {
+		t.Errorf("unexpected name %q", estimator)
+	}
+
+	for e := EstimatorHistogram; e <= EstimatorAdaptive; e++ {
+		if e == EstimatorGaussianCopula {
+			continue
+		}
+		got, err := e.Estimate(dataX, dataY)
+		if err != nil || got < 0.5 {
+			t.Errorf("%v: MI %v, %v, want strong dependence", e, got, err)
+		}
+		if e == EstimatorHistogram && got != mi {
+			t.Errorf("histogram estimate %v differs from AutoEstimator %v", got, mi)
+		}
+	}
+	if _, err := Estimator(-1).Estimate(dataX, dataY); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("unknown estimator: got %v", err)
+	}
+}
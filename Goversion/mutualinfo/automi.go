@@ -0,0 +1,105 @@
+package mutualinfo
+
+import "errors"
+
+// firstLocalMinimum returns the first index i in 1..len(curve)-2 with
+// curve[i] < curve[i-1] and curve[i] <= curve[i+1].
+func firstLocalMinimum(curve []float64) (int, bool) {
+	for i := 1; i < len(curve)-1; i++ {
+		if curve[i] < curve[i-1] && curve[i] <= curve[i+1] {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// FirstAutoMIMinimum returns the lag of the first local minimum of the auto
+// mutual information I(X(t); X(t+lag)) for lags 0..maxLag, the standard
+// Fraser–Swinney choice of the embedding delay.
+//
+// A lag counts as the minimum if its MI is strictly smaller than at the
+// previous lag and not larger than at the next one, so on a plateau the
+// first lag of the plateau is returned. An error is returned if the curve
+// has no local minimum within 1..maxLag-1.
+func FirstAutoMIMinimum(data []float64, maxLag, bins int, min, max float64) (lag int, miAtMin float64, err error) {
+	if maxLag < 2 {
+		return 0, 0, invalid(ErrInvalidParameter, "maxLag", "maxLag must be at least 2")
+	}
+	mi, err := ShiftedMutualInformation(0, maxLag, bins, bins, min, max, min, max, data, data, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	lag, ok := firstLocalMinimum(mi)
+	if !ok {
+		return 0, 0, errors.New("auto mutual information has no local minimum up to maxLag")
+	}
+	return lag, mi[lag], nil
+}
+
+// PredictabilityProfile returns I(X(t); X(t+h)) for the horizons h = 1..maxHorizon,
+// element h-1 holding horizon h. A quickly decaying profile indicates that
+// the series is hard to predict beyond short horizons.
+func PredictabilityProfile(data []float64, maxHorizon, bins int, min, max float64) ([]float64, error) {
+	if maxHorizon < 1 {
+		return nil, invalid(ErrInvalidParameter, "maxHorizon", "maxHorizon must be greater or equal 1")
+	}
+	return ShiftedMutualInformation(1, maxHorizon, bins, bins, min, max, min, max, data, data, 1)
+}
+
+// maxBlockSymbols bounds the number of distinct block symbols bins^L.
+const maxBlockSymbols = 1 << 40
+
+// PredictiveInformation estimates the predictive information
+// I(X(t-L..t-1); X(t..t+L-1)) between past and future blocks of length L for
+// L = 1..maxL, element L-1 holding block length L. Each block is reduced to
+// a single symbol by binning its values into bins bins over [min, max] and
+// combining the bin indices, and only occupied symbol pairs are stored.
+// Blocks with a value outside the range are skipped. The growth with L
+// characterizes the complexity of the series; note that its estimate needs
+// far more samples than bins^(2L) to be reliable.
+func PredictiveInformation(data []float64, maxL, bins int, min, max float64) ([]float64, error) {
+	if maxL < 1 {
+		return nil, invalid(ErrInvalidParameter, "maxL", "maxL must be greater or equal 1")
+	}
+	if 2*maxL > len(data) {
+		return nil, invalid(ErrTooFewSamples, "data", "data must hold at least two blocks of length maxL")
+	}
+	indices, err := CalculateIndices1D(bins, min, max, data)
+	if err != nil {
+		return nil, err
+	}
+	symbols := 1
+	for l := 0; l < maxL; l++ {
+		if symbols > maxBlockSymbols/bins {
+			return nil, invalid(ErrInvalidBins, "bins", "bins^maxL exceeds the supported number of block symbols")
+		}
+		symbols *= bins
+	}
+
+	info := make([]float64, maxL)
+	for l := 1; l <= maxL; l++ {
+		counts := make(map[IndexPair]int)
+		for t := l; t+l <= len(indices); t++ {
+			past, okPast := blockSymbol(indices[t-l:t], bins)
+			future, okFuture := blockSymbol(indices[t:t+l], bins)
+			if okPast && okFuture {
+				counts[IndexPair{First: past, Second: future}]++
+			}
+		}
+		info[l-1] = sparseMutualInformation(counts, nil)
+	}
+	return info, nil
+}
+
+// blockSymbol combines the bin indices of a block into a single symbol, or
+// reports false if the block holds an out-of-range index.
+func blockSymbol(block []int, bins int) (int, bool) {
+	symbol := 0
+	for _, index := range block {
+		if index < 0 {
+			return 0, false
+		}
+		symbol = symbol*bins + index
+	}
+	return symbol, true
+}
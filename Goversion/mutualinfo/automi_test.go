@@ -0,0 +1,86 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFirstLocalMinimum(t *testing.T) {
+	cases := []struct {
+		curve []float64
+		want  int
+		ok    bool
+	}{
+		{[]float64{3, 2, 1, 2}, 2, true},
+		{[]float64{3, 2, 2, 2, 1}, 1, true},
+		{[]float64{3, 2, 1, 0}, 0, false},
+		{[]float64{1, 1, 1}, 0, false},
+	}
+	for _, c := range cases {
+		got, ok := firstLocalMinimum(c.curve)
+		if got != c.want || ok != c.ok {
+			t.Errorf("firstLocalMinimum(%v) = %d, %v, want %d, %v", c.curve, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFirstAutoMIMinimum(t *testing.T) {
+	data := make([]float64, 4000)
+	for i := range data {
+		data[i] = math.Sin(2 * math.Pi * float64(i) / 40.7)
+	}
+	lag, mi, err := FirstAutoMIMinimum(data, 30, 8, -1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curve, _ := ShiftedMutualInformation(0, 30, 8, 8, -1, 1, -1, 1, data, data, 1)
+	if lag < 1 || mi != curve[lag] || mi >= curve[lag-1] || mi > curve[lag+1] {
+		t.Errorf("lag %d with MI %v is not a local minimum of %v", lag, mi, curve)
+	}
+	if _, _, err := FirstAutoMIMinimum(data, 1, 8, -1, 1); err == nil {
+		t.Error("expected error for maxLag < 2")
+	}
+}
+
+func TestPredictabilityProfile(t *testing.T) {
+	data := make([]float64, 1000)
+	for i := range data {
+		data[i] = float64(i % 10)
+	}
+	profile, err := PredictabilityProfile(data, 10, 10, 0, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile) != 10 {
+		t.Fatalf("expected 10 horizons, got %d", len(profile))
+	}
+	// A periodic sequence is fully predictable at every horizon.
+	for h, mi := range profile {
+		if !almostEqual(mi, math.Log2(10), 1e-3) {
+			t.Errorf("horizon %d: got %v", h+1, mi)
+		}
+	}
+	if _, err := PredictabilityProfile(data, 0, 10, 0, 9); err == nil {
+		t.Error("expected error for maxHorizon < 1")
+	}
+}
+
+func TestPredictiveInformation(t *testing.T) {
+	// A period-4 sequence: one past sample already determines the phase.
+	data := make([]float64, 4000)
+	for i := range data {
+		data[i] = float64(i % 4)
+	}
+	info, err := PredictiveInformation(data, 3, 4, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for l, v := range info {
+		if !almostEqual(v, 2, 1e-3) {
+			t.Errorf("L=%d: expected 2 bits, got %v", l+1, v)
+		}
+	}
+	if _, err := PredictiveInformation(data, 30, 4, 0, 3); err == nil {
+		t.Error("expected error for too many block symbols")
+	}
+}
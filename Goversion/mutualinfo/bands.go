@@ -0,0 +1,61 @@
+package mutualinfo
+
+import (
+	"fmt"
+)
+
+// BandPair holds two signals filtered to the same frequency band.
+type BandPair struct {
+	X, Y []float64
+}
+
+// Band is a frequency band in the unit used by the BandFilter.
+type Band struct {
+	Low, High float64
+}
+
+// BandFilter returns data band-pass filtered to band.
+type BandFilter func(data []float64, band Band) []float64
+
+// BandMutualInformation calculates the mutual information of every
+// band-filtered pair, giving a frequency-resolved dependence profile
+// analogous to coherence. The bins of each band span the range of its
+// signals.
+func BandMutualInformation(bands []BandPair, bins int) ([]float64, error) {
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	mi := make([]float64, len(bands))
+	for b, pair := range bands {
+		if len(pair.X) != len(pair.Y) {
+			return nil, invalid(ErrSizeMismatch, "bands", fmt.Sprintf("band %d: X and Y must have the same size", b))
+		}
+		if len(pair.X) == 0 {
+			return nil, invalid(ErrTooFewSamples, "bands", fmt.Sprintf("band %d: signals must not be empty", b))
+		}
+		var err error
+		if mi[b], err = rangedMutualInformation(bins, pair.X, pair.Y); err != nil {
+			return nil, fmt.Errorf("band %d: %w", b, err)
+		}
+	}
+	return mi, nil
+}
+
+// FilteredBandMutualInformation filters dataX and dataY to each band with
+// filter and returns the mutual information per band.
+func FilteredBandMutualInformation(dataX, dataY []float64, bands []Band, filter BandFilter, bins int) ([]float64, error) {
+	if len(dataX) != len(dataY) {
+		return nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if filter == nil {
+		return nil, invalid(ErrInvalidParameter, "filter", "filter must not be nil")
+	}
+	pairs := make([]BandPair, len(bands))
+	for b, band := range bands {
+		if band.Low >= band.High {
+			return nil, invalid(ErrInvalidRange, "bands", fmt.Sprintf("band %d: low has to be smaller than high", b))
+		}
+		pairs[b] = BandPair{X: filter(dataX, band), Y: filter(dataY, band)}
+	}
+	return BandMutualInformation(pairs, bins)
+}
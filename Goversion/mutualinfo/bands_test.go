@@ -0,0 +1,54 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// movingAverageFilter treats band {0, 1} as a moving-average low pass and any
+// other band as the high-pass residual.
+func movingAverageFilter(data []float64, band Band) []float64 {
+	const half = 10
+	out := make([]float64, len(data))
+	for i := range data {
+		lo, hi := i-half, i+half+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(data) {
+			hi = len(data)
+		}
+		var sum float64
+		for _, v := range data[lo:hi] {
+			sum += v
+		}
+		out[i] = sum / float64(hi-lo)
+		if band != (Band{0, 1}) {
+			out[i] = data[i] - out[i]
+		}
+	}
+	return out
+}
+
+func TestFilteredBandMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(15))
+	n := 4000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		slow := math.Sin(float64(i) * 0.01)
+		dataX[i] = slow + 0.3*rng.NormFloat64()
+		dataY[i] = slow + 0.3*rng.NormFloat64()
+	}
+	mi, err := FilteredBandMutualInformation(dataX, dataY, []Band{{0, 1}, {1, 2}}, movingAverageFilter, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mi[0] < 1 || mi[1] > 0.1 {
+		t.Errorf("expected coupling only in the low band, got %v", mi)
+	}
+	if _, err := BandMutualInformation([]BandPair{{X: dataX, Y: dataY[:5]}}, 8); err == nil {
+		t.Error("expected error for differing band sizes")
+	}
+}
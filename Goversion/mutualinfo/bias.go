@@ -0,0 +1,150 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// BiasCorrection selects a correction of the upward bias of the plug-in
+// mutual information.
+type BiasCorrection int
+
+const (
+	// BiasNone returns the plug-in estimate.
+	BiasNone BiasCorrection = iota
+	// BiasMillerMadow applies the Miller–Madow correction, see
+	// CalculateMutualInformationMM.
+	BiasMillerMadow
+	// BiasJackknife applies the leave-one-out jackknife over the pairs.
+	BiasJackknife
+	// BiasAnalytic subtracts the expected plug-in MI of independent data,
+	// see CalculateMutualInformationAnalytic.
+	BiasAnalytic
+)
+
+func (c BiasCorrection) String() string {
+	switch c {
+	case BiasNone:
+		return "none"
+	case BiasMillerMadow:
+		return "miller-madow"
+	case BiasJackknife:
+		return "jackknife"
+	case BiasAnalytic:
+		return "analytic"
+	}
+	return "unknown"
+}
+
+// CalculateMutualInformationMM returns the mutual information with the
+// Miller–Madow correction, which adds (m-1)/(2N ln 2) to each plug-in
+// entropy, m being the number of non-empty bins of the X marginal, the Y
+// marginal and the joint histogram respectively. This removes most of the
+// upward bias of the plug-in estimate when the number of cells approaches
+// the number of pairs. The result may be slightly negative for nearly
+// independent data.
+func (h *Histogram2D) CalculateMutualInformationMM() float64 {
+	hx, hy, hxy := h.Entropies()
+
+	h.Mutex.Lock()
+	rows, cols, total := h.marginalCounts()
+	occupied := 0
+	for i := range h.Data {
+		occupied += countNonZero(h.Data[i])
+	}
+	h.Mutex.Unlock()
+
+	correction := float64(countNonZero(rows)-1+countNonZero(cols)-1-(occupied-1)) / (2 * float64(total) * math.Ln2)
+	return hx + hy - hxy + correction
+}
+
+// CalculateMutualInformationJackknife returns the jackknife estimate
+// N*I - (N-1)/N * sum of I without pair k, over all N pairs of the
+// histogram. Leaving out a pair only changes its cell, so every term is
+// updated in constant time from the plug-in sums. It uses the counts in
+// Data, ignoring WeightedData, and returns 0 for fewer than two pairs.
+func (h *Histogram2D) CalculateMutualInformationJackknife() float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	rows, cols, total := h.marginalCounts()
+	if total < 2 {
+		return 0
+	}
+	// I = log2(N) - (Sx + Sy - Sxy)/N with S the sums of c*log2(c).
+	var sx, sy, sxy neumaierSum
+	for _, c := range rows {
+		sx.Add(cLogC(c))
+	}
+	for _, c := range cols {
+		sy.Add(cLogC(c))
+	}
+	for i := range h.Data {
+		for _, c := range h.Data[i] {
+			sxy.Add(cLogC(c))
+		}
+	}
+	n := float64(total)
+	full := math.Log2(n) - (sx.Value()+sy.Value()-sxy.Value())/n
+
+	var leaveOneOut neumaierSum
+	for i := range h.Data {
+		for j, c := range h.Data[i] {
+			if c == 0 {
+				continue
+			}
+			dx := cLogC(rows[i]-1) - cLogC(rows[i])
+			dy := cLogC(cols[j]-1) - cLogC(cols[j])
+			dxy := cLogC(c-1) - cLogC(c)
+			without := math.Log2(n-1) - (sx.Value()+dx+sy.Value()+dy-sxy.Value()-dxy)/(n-1)
+			leaveOneOut.Add(float64(c) * without)
+		}
+	}
+	return n*full - (n-1)/n*leaveOneOut.Value()
+}
+
+// CalculateMutualInformationAnalytic returns the plug-in mutual information
+// minus (Bx-1)(By-1)/(2N ln 2), the leading term of its expectation for
+// independent data, Bx and By being the numbers of non-empty bins of the X
+// and Y marginals. Unlike CalculateMutualInformationMM it does not depend on
+// the occupied joint cells, so it approximates the shuffle correction of
+// ShiftOptions.Shuffles without any shuffling. The result may be negative.
+func (h *Histogram2D) CalculateMutualInformationAnalytic() float64 {
+	mi := h.CalculateMutualInformation()
+
+	h.Mutex.Lock()
+	rows, cols, total := h.marginalCounts()
+	h.Mutex.Unlock()
+
+	if total == 0 {
+		return mi
+	}
+	return mi - float64((countNonZero(rows)-1)*(countNonZero(cols)-1))/(2*float64(total)*math.Ln2)
+}
+
+func cLogC(c int) float64 {
+	if c <= 0 {
+		return 0
+	}
+	return float64(c) * math.Log2(float64(c))
+}
+
+// CalculateMutualInformationCorrected returns the mutual information with
+// the given bias correction together with the correction applied, the
+// corrected minus the plug-in value. A large correction relative to the
+// result means that the histogram has too many cells for its pairs.
+func (h *Histogram2D) CalculateMutualInformationCorrected(correction BiasCorrection) (mi, applied float64, err error) {
+	raw := h.CalculateMutualInformation()
+	switch correction {
+	case BiasNone:
+		return raw, 0, nil
+	case BiasMillerMadow:
+		mi = h.CalculateMutualInformationMM()
+	case BiasJackknife:
+		mi = h.CalculateMutualInformationJackknife()
+	case BiasAnalytic:
+		mi = h.CalculateMutualInformationAnalytic()
+	default:
+		return 0, 0, invalid(ErrInvalidOption, "BiasCorrection", "unknown bias correction")
+	}
+	return mi, mi - raw, nil
+}
@@ -0,0 +1,161 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCalculateMutualInformationMM(t *testing.T) {
+	// 200 independent pairs in 10x10 bins are strongly biased upwards.
+	rng := rand.New(rand.NewSource(8))
+	hist := NewHistogram2D(10, 10, 0, 1, 0, 1)
+	for i := 0; i < 200; i++ {
+		hist.Increment(rng.Float64(), rng.Float64())
+	}
+	raw := hist.CalculateMutualInformation()
+	corrected := hist.CalculateMutualInformationMM()
+	if math.Abs(corrected) >= math.Abs(raw) {
+		t.Errorf("corrected MI %v is not closer to 0 than raw MI %v", corrected, raw)
+	}
+
+	// Two occupied cells on the diagonal: the correction is (1 + 1 - 1)/(2N ln 2).
+	diagonal := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	diagonal.Increment(0.5, 0.5)
+	diagonal.Increment(1.5, 1.5)
+	if got, want := diagonal.CalculateMutualInformationMM(), 1+1/(4*math.Ln2); !almostEqual(got, want, 1e-12) {
+		t.Errorf("diagonal corrected MI %v, want %v", got, want)
+	}
+}
+
+func TestCalculateMutualInformationJackknife(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	dataX := make([]float64, 120)
+	dataY := make([]float64, 120)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.5*dataX[i] + 0.5*rng.Float64()
+	}
+	hist := NewHistogram2D(5, 5, 0, 1, 0, 1)
+	for i := range dataX {
+		hist.Increment(dataX[i], dataY[i])
+	}
+
+	// Reference: refill the histogram without each pair in turn.
+	n := float64(len(dataX))
+	var sum float64
+	for k := range dataX {
+		without := NewHistogram2D(5, 5, 0, 1, 0, 1)
+		for i := range dataX {
+			if i != k {
+				without.Increment(dataX[i], dataY[i])
+			}
+		}
+		sum += without.CalculateMutualInformation()
+	}
+	want := n*hist.CalculateMutualInformation() - (n-1)/n*sum
+	if got := hist.CalculateMutualInformationJackknife(); !almostEqual(got, want, 1e-9) {
+		t.Errorf("jackknife %v, brute force %v", got, want)
+	}
+
+	mi, applied, err := hist.CalculateMutualInformationCorrected(BiasJackknife)
+	if err != nil || mi != hist.CalculateMutualInformationJackknife() || !almostEqual(applied, mi-hist.CalculateMutualInformation(), 1e-12) || applied >= 0 {
+		t.Errorf("corrected %v, applied %v, %v", mi, applied, err)
+	}
+	if _, applied, _ := hist.CalculateMutualInformationCorrected(BiasNone); applied != 0 {
+		t.Errorf("no correction applied %v, want 0", applied)
+	}
+	if _, _, err := hist.CalculateMutualInformationCorrected(BiasCorrection(9)); err == nil {
+		t.Error("expected error for an unknown correction")
+	}
+}
+
+func TestShiftedMutualInformationBiasCorrection(t *testing.T) {
+	rng := rand.New(rand.NewSource(32))
+	dataX := make([]float64, 300)
+	dataY := make([]float64, 300)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	raw, _ := ShiftedMutualInformation(-3, 3, 8, 8, 0.0, 1.0, 0.0, 1.0, dataX, dataY, 1)
+	for _, correction := range []BiasCorrection{BiasMillerMadow, BiasJackknife, BiasAnalytic} {
+		corrected, err := ShiftedMutualInformationWithOptions(-3, 3, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{BiasCorrection: correction})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range raw {
+			if math.Abs(corrected[i]) >= raw[i] {
+				t.Errorf("%v, shift %d: corrected %v not closer to 0 than %v", correction, i-3, corrected[i], raw[i])
+			}
+		}
+	}
+	opts := ShiftOptions{BiasCorrection: BiasJackknife, Normalization: NormalizationSymmetric}
+	if _, err := ShiftedMutualInformationWithOptions(-3, 3, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, opts); err == nil {
+		t.Error("expected error for a bias correction with a normalization")
+	}
+}
+
+func TestCalculateMutualInformationAnalytic(t *testing.T) {
+	// Two occupied cells on the diagonal: (2-1)(2-1)/(2N ln 2) with N = 2.
+	diagonal := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	diagonal.Increment(0.5, 0.5)
+	diagonal.Increment(1.5, 1.5)
+	if got, want := diagonal.CalculateMutualInformationAnalytic(), 1-1/(4*math.Ln2); !almostEqual(got, want, 1e-12) {
+		t.Errorf("diagonal corrected MI %v, want %v", got, want)
+	}
+	if got := NewHistogram2D(2, 2, 0, 1, 0, 1).CalculateMutualInformationAnalytic(); !math.IsNaN(got) {
+		t.Errorf("empty histogram: got %v, want NaN", got)
+	}
+}
+
+func TestShiftedMutualInformationShuffles(t *testing.T) {
+	rng := rand.New(rand.NewSource(543))
+	dataX := make([]float64, 400)
+	dataY := make([]float64, 400)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	dataY[5] = math.NaN()
+	raw, _ := ShiftedMutualInformationResult(-4, 4, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, ShiftOptions{})
+	opts := ShiftOptions{Shuffles: 30, ShuffleSeed: 7}
+	result, err := ShiftedMutualInformationResult(-4, 4, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Settings.Shuffles != 30 || len(result.ShuffleBias) != len(raw.MI) {
+		t.Fatalf("settings %+v, shuffle bias %v", result.Settings, result.ShuffleBias)
+	}
+	for i := range raw.MI {
+		if !almostEqual(result.MI[i]+result.ShuffleBias[i], raw.MI[i], 1e-12) {
+			t.Errorf("shift %d: corrected %v plus bias %v is not the raw MI %v", result.Shifts[i], result.MI[i], result.ShuffleBias[i], raw.MI[i])
+		}
+		// The shuffles approach the analytic bias (8-1)(8-1)/(2N ln 2).
+		analytic := 49 / (2 * float64(raw.Pairs[i]) * math.Ln2)
+		if math.Abs(result.ShuffleBias[i]-analytic) > 0.2*analytic || math.Abs(result.MI[i]) > 0.05 {
+			t.Errorf("shift %d: bias %v, analytic %v, corrected MI %v", result.Shifts[i], result.ShuffleBias[i], analytic, result.MI[i])
+		}
+		if result.HX[i] != raw.HX[i] || result.Pairs[i] != raw.Pairs[i] {
+			t.Errorf("shift %d: entropies or pairs of the shuffles leaked into the result", result.Shifts[i])
+		}
+	}
+
+	opts.Workers = 1
+	again, _ := ShiftedMutualInformationWithOptions(-4, 4, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, opts)
+	for i := range again {
+		if again[i] != result.MI[i] {
+			t.Fatalf("results depend on the workers: %v, %v", again, result.MI)
+		}
+	}
+
+	var calls, total int
+	opts.Progress = func(done, n int) { calls, total = done, n }
+	ShiftedMutualInformationWithOptions(-4, 4, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, opts)
+	if calls != 5*31 || total != 5*31 {
+		t.Errorf("progress ended at %d of %d, want %d", calls, total, 5*31)
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-4, 4, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, ShiftOptions{Shuffles: -1}); err == nil {
+		t.Error("expected error for negative shuffles")
+	}
+}
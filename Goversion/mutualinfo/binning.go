@@ -0,0 +1,119 @@
+package mutualinfo
+
+// BinningStrategy selects how bin edges are placed.
+type BinningStrategy int
+
+const (
+	// BinningUniform splits the given range into equally wide bins.
+	BinningUniform BinningStrategy = iota
+	// BinningQuantile places the edges at the quantiles of the data, see
+	// QuantileEdges, so that the bins are roughly equally populated. This
+	// keeps resolution where heavy-tailed data actually lies.
+	BinningQuantile
+)
+
+func (s BinningStrategy) String() string {
+	switch s {
+	case BinningUniform:
+		return "uniform"
+	case BinningQuantile:
+		return "quantile"
+	}
+	return "unknown"
+}
+
+// uniformEdges returns the bins+1 edges of equally wide bins over [min, max].
+func uniformEdges(bins int, min, max float64) []float64 {
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = min + (max-min)*float64(i)/float64(bins)
+	}
+	edges[bins] = max
+	return edges
+}
+
+// BinEdges returns the bin edges strategy places for bins bins. Uniform
+// edges span [min, max]; quantile edges span the finite values of data and
+// ignore min and max, and ties may merge them into fewer bins.
+func BinEdges(strategy BinningStrategy, bins int, min, max float64, data []float64) ([]float64, error) {
+	switch strategy {
+	case BinningUniform:
+		if !isFinite(min) || !isFinite(max) {
+			return nil, invalid(ErrInvalidRange, "min", "min and max must be finite")
+		}
+		if min >= max {
+			return nil, invalid(ErrInvalidRange, "min", "min has to be smaller than max")
+		}
+		if bins < 1 {
+			return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+		}
+		return uniformEdges(bins, min, max), nil
+	case BinningQuantile:
+		return QuantileEdges(bins, finiteValues(data))
+	}
+	return nil, invalid(ErrInvalidOption, "strategy", "unknown binning strategy")
+}
+
+// CalculateIndices1DBinning is CalculateIndices1D with the bins placed by
+// strategy. It also returns the edges used.
+func CalculateIndices1DBinning(strategy BinningStrategy, bins int, min, max float64, data []float64) ([]int, []float64, error) {
+	if strategy == BinningUniform {
+		indices, err := CalculateIndices1D(bins, min, max, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return indices, uniformEdges(bins, min, max), nil
+	}
+	edges, err := BinEdges(strategy, bins, min, max, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	indices := make([]int, len(data))
+	for i, value := range data {
+		indices[i] = edgeIndex(edges, value)
+	}
+	return indices, edges, nil
+}
+
+// CalculateIndices2DBinning is CalculateIndices2D with the bins of both axes
+// placed by strategy. It also returns the edges used.
+func CalculateIndices2DBinning(strategy BinningStrategy, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) ([]IndexPair, []float64, []float64, error) {
+	if len(dataX) != len(dataY) {
+		return nil, nil, nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	indicesX, edgesX, err := CalculateIndices1DBinning(strategy, binsX, minX, maxX, dataX)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	indicesY, edgesY, err := CalculateIndices1DBinning(strategy, binsY, minY, maxY, dataY)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	indices := make([]IndexPair, len(dataX))
+	for i := range indices {
+		if indicesX[i] < 0 || indicesY[i] < 0 {
+			indices[i] = IndexPair{First: -1, Second: -1}
+			continue
+		}
+		indices[i] = IndexPair{First: indicesX[i], Second: indicesY[i]}
+	}
+	return indices, edgesX, edgesY, nil
+}
+
+// NewHistogram2DBinning creates an empty histogram with the bins of both
+// axes placed by strategy for dataX and dataY, which are not added. The
+// edges used are stored in EdgesX and EdgesY for quantile binning.
+func NewHistogram2DBinning(strategy BinningStrategy, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (*Histogram2D, error) {
+	edgesX, err := BinEdges(strategy, binsX, minX, maxX, dataX)
+	if err != nil {
+		return nil, err
+	}
+	edgesY, err := BinEdges(strategy, binsY, minY, maxY, dataY)
+	if err != nil {
+		return nil, err
+	}
+	if strategy == BinningUniform {
+		return NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY), nil
+	}
+	return NewHistogram2DEdges(edgesX, edgesY)
+}
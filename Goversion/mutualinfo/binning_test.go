@@ -0,0 +1,120 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCalculateIndices1DBinning(t *testing.T) {
+	rng := rand.New(rand.NewSource(26))
+	data := make([]float64, 1000)
+	for i := range data {
+		// Cauchy samples, mostly near 0 with a few huge values.
+		data[i] = math.Tan(math.Pi * (rng.Float64() - 0.5))
+	}
+
+	indices, edges, err := CalculateIndices1DBinning(BinningQuantile, 10, 0, 0, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 11 {
+		t.Fatalf("got %d edges, want 11", len(edges))
+	}
+	counts := make([]int, 10)
+	for _, index := range indices {
+		counts[index]++
+	}
+	for bin, count := range counts {
+		if count < 99 || count > 101 {
+			t.Errorf("quantile bin %d holds %d samples, want about 100", bin, count)
+		}
+	}
+
+	indices, edges, err = CalculateIndices1DBinning(BinningUniform, 4, -2, 2, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := CalculateIndices1D(4, -2.0, 2.0, data)
+	for i := range want {
+		if indices[i] != want[i] {
+			t.Fatalf("uniform index %d = %d, CalculateIndices1D gives %d", i, indices[i], want[i])
+		}
+	}
+	if wantEdges := []float64{-2, -1, 0, 1, 2}; len(edges) != len(wantEdges) || edges[1] != -1 || edges[4] != 2 {
+		t.Errorf("uniform edges %v, want %v", edges, wantEdges)
+	}
+
+	if _, _, err := CalculateIndices1DBinning(BinningStrategy(9), 4, 0, 1, data); err == nil {
+		t.Error("expected error for an unknown strategy")
+	}
+}
+
+func TestCalculateIndices2DBinning(t *testing.T) {
+	dataX := []float64{0, 1, 2, 3, math.NaN(), 5}
+	dataY := []float64{5, 4, 3, 2, 1, 0}
+	indices, edgesX, edgesY, err := CalculateIndices2DBinning(BinningQuantile, 2, 2, 0, 0, 0, 0, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edgesX[0] != 0 || edgesX[2] != 5 || edgesY[0] != 0 || edgesY[2] != 5 {
+		t.Errorf("edges %v and %v do not span the finite data", edgesX, edgesY)
+	}
+	if indices[4] != (IndexPair{First: -1, Second: -1}) {
+		t.Errorf("NaN pair gives %v, want {-1, -1}", indices[4])
+	}
+	if indices[0] != (IndexPair{First: 0, Second: 1}) || indices[5] != (IndexPair{First: 1, Second: 0}) {
+		t.Errorf("unexpected indices %v", indices)
+	}
+	if _, _, _, err := CalculateIndices2DBinning(BinningQuantile, 2, 2, 0, 0, 0, 0, dataX, dataY[:3]); err == nil {
+		t.Error("expected error for different sizes")
+	}
+
+	hist, err := NewHistogram2DBinning(BinningQuantile, 2, 2, 0, 0, 0, 0, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hist.BinsX != 2 || hist.EdgesX[1] != edgesX[1] || hist.EdgesY[1] != edgesY[1] {
+		t.Errorf("histogram edges %v and %v, want %v and %v", hist.EdgesX, hist.EdgesY, edgesX, edgesY)
+	}
+	if hist, err := NewHistogram2DBinning(BinningUniform, 3, 3, 0, 5, 0, 5, dataX, dataY); err != nil || hist.EdgesX != nil || hist.MaxX != 5 {
+		t.Errorf("uniform histogram %+v, %v", hist, err)
+	}
+}
+
+func TestShiftedMutualInformationQuantile(t *testing.T) {
+	rng := rand.New(rand.NewSource(27))
+	dataX := make([]float64, 800)
+	dataY := make([]float64, 800)
+	for i := range dataX {
+		dataX[i] = rng.ExpFloat64()
+		dataY[i] = dataX[i]*dataX[i] + rng.ExpFloat64()
+	}
+	opts := ShiftOptions{Binning: BinningQuantile}
+	result, err := ShiftedMutualInformationResult(-2, 2, 6, 6, 0, 1, 0, 1, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantX, _ := QuantileEdges(6, dataX)
+	wantY, _ := QuantileEdges(6, dataY)
+	if len(result.EdgesX) != len(wantX) || result.EdgesX[3] != wantX[3] || result.EdgesY[3] != wantY[3] {
+		t.Errorf("reported edges %v and %v, want %v and %v", result.EdgesX, result.EdgesY, wantX, wantY)
+	}
+	direct, _ := MutualInformationWithEdges(wantX, wantY, dataX, dataY)
+	if !almostEqual(result.MI[2], direct, 1e-12) {
+		t.Errorf("shift 0 gives %v, MutualInformationWithEdges %v", result.MI[2], direct)
+	}
+
+	uniform, err := ShiftedMutualInformationResult(0, 0, 4, 4, 0, 2, 0, 8, dataX, dataY, 1, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uniform.EdgesX) != 5 || uniform.EdgesX[2] != 1 || uniform.EdgesY[4] != 8 {
+		t.Errorf("uniform edges %v and %v", uniform.EdgesX, uniform.EdgesY)
+	}
+
+	opts.RecalibrateRange = true
+	if _, err := ShiftedMutualInformationWithOptions(-2, 2, 6, 6, 0, 1, 0, 1, dataX, dataY, 1, opts); err == nil {
+		t.Error("expected error for quantile binning with RecalibrateRange")
+	}
+}
@@ -0,0 +1,108 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// BinRule identifies a rule of thumb for the number of bins.
+type BinRule int
+
+const (
+	// BinRuleSturges uses ceil(log2(n) + 1) bins, suited to roughly normal data.
+	BinRuleSturges BinRule = iota
+	// BinRuleFreedmanDiaconis uses bins of width 2*IQR/n^(1/3), which is
+	// robust against outliers.
+	BinRuleFreedmanDiaconis
+	// BinRuleScott uses bins of width 3.49*sd/n^(1/3), which is optimal for
+	// normal data.
+	BinRuleScott
+	// BinRuleDoane extends Sturges by log2(1 + |g1|/sd(g1)) bins for the
+	// sample skewness g1, suited to skewed data.
+	BinRuleDoane
+)
+
+func (r BinRule) String() string {
+	switch r {
+	case BinRuleSturges:
+		return "sturges"
+	case BinRuleFreedmanDiaconis:
+		return "freedman-diaconis"
+	case BinRuleScott:
+		return "scott"
+	case BinRuleDoane:
+		return "doane"
+	}
+	return "unknown"
+}
+
+// SuggestBins returns the number of bins for data proposed by rule, at least
+// 1 and at most len(data). Constant data gives a single bin. If the
+// interquartile range is zero while the data is not constant, the
+// Freedman–Diaconis rule falls back to Sturges, as does Doane for fewer than
+// three samples.
+func SuggestBins(data []float64, rule BinRule) (int, error) {
+	if len(data) == 0 {
+		return 0, invalid(ErrTooFewSamples, "data", "data must not be empty")
+	}
+	for _, v := range data {
+		if !isFinite(v) {
+			return 0, invalid(ErrInvalidData, "data", "data must be finite")
+		}
+	}
+	sorted := sortedCopy(data)
+	dataRange := sorted[len(sorted)-1] - sorted[0]
+	if dataRange == 0 {
+		return 1, nil
+	}
+	n := float64(len(data))
+
+	var bins float64
+	switch rule {
+	case BinRuleSturges:
+		bins = math.Ceil(math.Log2(n) + 1)
+	case BinRuleFreedmanDiaconis:
+		iqr := quantile(sorted, 0.75) - quantile(sorted, 0.25)
+		if iqr == 0 {
+			return SuggestBins(data, BinRuleSturges)
+		}
+		bins = math.Ceil(dataRange / (2 * iqr / math.Cbrt(n)))
+	case BinRuleScott:
+		bins = math.Ceil(dataRange / (3.49 * math.Sqrt(variance(data)) / math.Cbrt(n)))
+	case BinRuleDoane:
+		if len(data) < 3 {
+			return SuggestBins(data, BinRuleSturges)
+		}
+		sigma := math.Sqrt(6 * (n - 2) / ((n + 1) * (n + 3)))
+		bins = math.Ceil(1 + math.Log2(n) + math.Log2(1+math.Abs(skewness(data))/sigma))
+	default:
+		return 0, invalid(ErrInvalidOption, "rule", "unknown bin rule")
+	}
+	return int(clamp(bins, 1, n)), nil
+}
+
+// AutoBins is SuggestBins for callers that have validated data already. It
+// returns 0 where SuggestBins returns an error.
+func AutoBins(data []float64, rule BinRule) int {
+	bins, err := SuggestBins(data, rule)
+	if err != nil {
+		return 0
+	}
+	return bins
+}
+
+// skewness returns the sample skewness g1 of data, which must not be constant.
+func skewness(data []float64) float64 {
+	var sum neumaierSum
+	for _, v := range data {
+		sum.Add(v)
+	}
+	mean := sum.Value() / float64(len(data))
+	var m2, m3 neumaierSum
+	for _, v := range data {
+		d := v - mean
+		m2.Add(d * d)
+		m3.Add(d * d * d)
+	}
+	n := float64(len(data))
+	return (m3.Value() / n) / math.Pow(m2.Value()/n, 1.5)
+}
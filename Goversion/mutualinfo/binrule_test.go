@@ -0,0 +1,99 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSuggestBins(t *testing.T) {
+	data := make([]float64, 1000)
+	for i := range data {
+		data[i] = float64(i) / 999
+	}
+	if bins, err := SuggestBins(data, BinRuleSturges); err != nil || bins != 11 {
+		t.Errorf("Sturges = %d, %v, want 11", bins, err)
+	}
+	// Uniform data on [0, 1] has IQR 0.5, so the width is 1/cbrt(1000) = 0.1.
+	if bins, err := SuggestBins(data, BinRuleFreedmanDiaconis); err != nil || bins != 10 {
+		t.Errorf("Freedman–Diaconis = %d, %v, want 10", bins, err)
+	}
+
+	// A single outlier widens the range but not the IQR.
+	rng := rand.New(rand.NewSource(1))
+	for i := range data {
+		data[i] = rng.NormFloat64()
+	}
+	data[0] = 1000
+	if bins, err := SuggestBins(data, BinRuleFreedmanDiaconis); err != nil || bins < 100 || bins > len(data) {
+		t.Errorf("Freedman–Diaconis with outlier = %d, %v", bins, err)
+	}
+
+	for _, rule := range []BinRule{BinRuleSturges, BinRuleFreedmanDiaconis} {
+		if bins, err := SuggestBins([]float64{2, 2, 2}, rule); err != nil || bins != 1 {
+			t.Errorf("%v: constant data gives %d, %v, want 1", rule, bins, err)
+		}
+	}
+	ties := []float64{0, 1, 1, 1, 1, 1, 1, 2}
+	if bins, err := SuggestBins(ties, BinRuleFreedmanDiaconis); err != nil || bins != 4 {
+		t.Errorf("zero IQR gives %d, %v, want the Sturges count 4", bins, err)
+	}
+	if _, err := SuggestBins(nil, BinRuleSturges); err == nil {
+		t.Error("expected error for empty data")
+	}
+	if _, err := SuggestBins(data, BinRule(7)); err == nil {
+		t.Error("expected error for an unknown rule")
+	}
+}
+
+func TestSuggestBinsScottDoane(t *testing.T) {
+	data := make([]float64, 1000)
+	for i := range data {
+		data[i] = float64(i) / 999
+	}
+	// Uniform data on [0, 1] has sd 0.2888, so the width is 3.49*0.2888/10.
+	if bins, err := SuggestBins(data, BinRuleScott); err != nil || bins != 10 {
+		t.Errorf("Scott = %d, %v, want 10", bins, err)
+	}
+	// Symmetric data has no skewness, so Doane matches Sturges.
+	if bins := AutoBins(data, BinRuleDoane); bins != AutoBins(data, BinRuleSturges) {
+		t.Errorf("Doane = %d for symmetric data, want the Sturges count", bins)
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	for i := range data {
+		data[i] = rng.ExpFloat64()
+	}
+	if doane, sturges := AutoBins(data, BinRuleDoane), AutoBins(data, BinRuleSturges); doane <= sturges {
+		t.Errorf("Doane = %d for skewed data, want more than Sturges %d", doane, sturges)
+	}
+	if bins := AutoBins([]float64{1, 2}, BinRuleDoane); bins != 2 {
+		t.Errorf("Doane with two samples = %d, want the Sturges count 2", bins)
+	}
+	if bins := AutoBins(nil, BinRuleScott); bins != 0 {
+		t.Errorf("AutoBins of empty data = %d, want 0", bins)
+	}
+}
+
+func TestShiftedMutualInformationAutoBins(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	dataX := make([]float64, 1000)
+	dataY := make([]float64, 1000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.ExpFloat64()
+	}
+	dataX[5] = math.NaN()
+	opts := ShiftOptions{AutoBins: true, BinRule: BinRuleDoane}
+	got, err := ShiftedMutualInformationWithOptions(-2, 2, 0, 0, 0, 1, 0, 10, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binsX, binsY := AutoBins(finiteValues(dataX), BinRuleDoane), AutoBins(dataY, BinRuleDoane)
+	want, _ := ShiftedMutualInformation(-2, 2, binsX, binsY, 0, 1, 0, 10, dataX, dataY, 1)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("shift %d: auto bins give %v, bins %d and %d give %v", i-2, got[i], binsX, binsY, want[i])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mutualinfo
+
+import (
+	"fmt"
+	"math"
+)
+
+// OutOfRangePolicy selects how values outside the configured range are handled.
+type OutOfRangePolicy int
+
+const (
+	// DropOutOfRange ignores pairs with a value outside the range.
+	DropOutOfRange OutOfRangePolicy = iota
+	// ClampOutOfRange moves values outside the range onto the nearest boundary.
+	ClampOutOfRange
+)
+
+// RangeFromCalibration returns a binning range derived from a calibration
+// batch: the range of its finite values widened by padding times its width
+// on each side. For constant data the width is taken as the magnitude of the
+// value, or 1 if the value is 0. Data without a finite value gives NaN
+// bounds.
+func RangeFromCalibration(calData []float64, padding float64) (min, max float64) {
+	finite := finiteValues(calData)
+	if len(finite) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	min, max = minMax(finite)
+	width := max - min
+	if width == 0 {
+		width = math.Abs(min)
+		if width == 0 {
+			width = 1
+		}
+		if padding == 0 {
+			padding = 0.5
+		}
+	}
+	return min - padding*width, max + padding*width
+}
+
+// DataRange returns the smallest and largest finite value of data. NaN and
+// infinite values, which are never binned, are ignored. An error is returned
+// if data holds no finite value or a single distinct one, which gives no range.
+func DataRange(data []float64) (min, max float64, err error) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, v := range data {
+		if isFinite(v) {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+	}
+	if min > max {
+		return 0, 0, invalid(ErrTooFewSamples, "data", "data holds no finite value")
+	}
+	if min == max {
+		return 0, 0, invalid(ErrInvalidRange, "data", "data has zero range")
+	}
+	return min, max, nil
+}
+
+// ClippedDataRange returns the clip and 1-clip quantiles of the finite
+// values of data, e.g. the 0.5% and 99.5% quantiles for a clip of 0.005, so
+// that a few outliers do not stretch the bins. A clip of 0 gives DataRange.
+func ClippedDataRange(data []float64, clip float64) (min, max float64, err error) {
+	if !(clip >= 0 && clip < 0.5) {
+		return 0, 0, invalid(ErrInvalidParameter, "clip", "clip must be in [0, 0.5)")
+	}
+	if clip == 0 {
+		return DataRange(data)
+	}
+	finite := finiteValues(data)
+	if len(finite) == 0 {
+		return 0, 0, invalid(ErrTooFewSamples, "data", "data holds no finite value")
+	}
+	sorted := sortedCopy(finite)
+	min, max = quantile(sorted, clip), quantile(sorted, 1-clip)
+	if min >= max {
+		return 0, 0, invalid(ErrInvalidRange, "data", "clipped data has zero range")
+	}
+	return min, max, nil
+}
+
+// AutoRangeShiftedMutualInformation is ShiftedMutualInformation with the
+// ranges taken from DataRange of dataX and dataY and widened by padding times
+// their width on each side, e.g. 0.01 for 1%. Padding keeps the maximum off
+// the upper boundary, where it would share the last bin with the values just
+// below.
+func AutoRangeShiftedMutualInformation(shiftFrom, shiftTo, binsX, binsY int, padding float64, dataX, dataY []float64, shiftStep int) ([]float64, error) {
+	if !(padding >= 0) || math.IsInf(padding, 1) {
+		return nil, invalid(ErrInvalidParameter, "padding", "padding must be finite and not negative")
+	}
+	minX, maxX, err := DataRange(dataX)
+	if err != nil {
+		return nil, fmt.Errorf("dataX: %w", err)
+	}
+	minY, maxY, err := DataRange(dataY)
+	if err != nil {
+		return nil, fmt.Errorf("dataY: %w", err)
+	}
+	padX, padY := padding*(maxX-minX), padding*(maxY-minY)
+	return ShiftedMutualInformation(shiftFrom, shiftTo, binsX, binsY, minX-padX, maxX+padX, minY-padY, maxY+padY, dataX, dataY, shiftStep)
+}
+
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,121 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRangeFromCalibration(t *testing.T) {
+	min, max := RangeFromCalibration([]float64{2, 4, 3}, 0.1)
+	if !almostEqual(min, 1.8, 1e-12) || !almostEqual(max, 4.2, 1e-12) {
+		t.Errorf("got [%v, %v], want [1.8, 4.2]", min, max)
+	}
+	min, max = RangeFromCalibration([]float64{0, 0}, 0)
+	if !(min < 0 && max > 0) {
+		t.Errorf("expected a non-empty range for constant data, got [%v, %v]", min, max)
+	}
+	if min, _ := RangeFromCalibration(nil, 0.1); !math.IsNaN(min) {
+		t.Errorf("expected NaN for empty data, got %v", min)
+	}
+	min, max = RangeFromCalibration([]float64{math.NaN(), 2, math.Inf(1), 4, 3}, 0.1)
+	if !almostEqual(min, 1.8, 1e-12) || !almostEqual(max, 4.2, 1e-12) {
+		t.Errorf("with NaN and Inf: got [%v, %v], want [1.8, 4.2]", min, max)
+	}
+	if min, max := RangeFromCalibration([]float64{math.NaN(), math.Inf(-1)}, 0.1); !math.IsNaN(min) || !math.IsNaN(max) {
+		t.Errorf("expected NaN without finite data, got [%v, %v]", min, max)
+	}
+}
+
+func TestMIMonitorOutOfRange(t *testing.T) {
+	for _, policy := range []OutOfRangePolicy{DropOutOfRange, ClampOutOfRange} {
+		monitor, err := NewMIMonitor(MIMonitorConfig{BinsX: 2, BinsY: 2, MaxX: 1, MaxY: 1, Decay: 1, OutOfRange: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		monitor.Observe(0.25, 0.25)
+		monitor.Observe(-5, 0.75)
+		monitor.Observe(5, 0.75)
+		// A sensor dropout is out of range and skipped, even when clamping.
+		monitor.Observe(math.NaN(), 0.25)
+		monitor.Observe(0.75, math.NaN())
+		if monitor.OutOfRange() != 4 {
+			t.Errorf("policy %v: expected 4 out-of-range pairs, got %d", policy, monitor.OutOfRange())
+		}
+		clamped := monitor.MI() > 0
+		if clamped != (policy == ClampOutOfRange) {
+			t.Errorf("policy %v: unexpected MI %v", policy, monitor.MI())
+		}
+	}
+}
+
+func TestDataRange(t *testing.T) {
+	min, max, err := DataRange([]float64{3, math.NaN(), -2, math.Inf(1), 7})
+	if err != nil || min != -2 || max != 7 {
+		t.Errorf("DataRange = %v, %v, %v, want -2, 7", min, max, err)
+	}
+	for _, bad := range [][]float64{nil, {4, 4, 4}, {math.NaN()}} {
+		if _, _, err := DataRange(bad); err == nil {
+			t.Errorf("DataRange(%v): expected error", bad)
+		}
+	}
+}
+
+func TestAutoRangeShiftedMutualInformation(t *testing.T) {
+	dataX := []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	dataY := []float64{10, 30, 20, 40, 50, 70, 60, 90, 80, 100}
+	got, err := AutoRangeShiftedMutualInformation(-1, 1, 4, 4, 0.01, dataX, dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ShiftedMutualInformation(-1, 1, 4, 4, -0.09, 9.09, 9.1, 100.9, dataX, dataY, 1)
+	for i := range want {
+		if !almostEqual(got[i], want[i], 1e-12) {
+			t.Errorf("shift %d: %v, want %v", i-1, got[i], want[i])
+		}
+	}
+	if _, err := AutoRangeShiftedMutualInformation(-1, 1, 4, 4, 0.01, dataX, make([]float64, 10), 1); err == nil {
+		t.Error("expected error for constant dataY")
+	}
+	if _, err := AutoRangeShiftedMutualInformation(-1, 1, 4, 4, -1, dataX, dataY, 1); err == nil {
+		t.Error("expected error for negative padding")
+	}
+}
+
+func TestAutoRangeOption(t *testing.T) {
+	dataX := make([]float64, 1000)
+	dataY := make([]float64, 1000)
+	for i := range dataX {
+		dataX[i] = float64(i)
+		dataY[i] = float64(i%50) - 20
+	}
+	dataX[3] = 1e6 // outlier
+	result, err := ShiftedMutualInformationResult(-1, 1, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{AutoRange: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := result.Settings
+	if s.MinX != 0 || s.MaxX != 1e6 || s.MinY != -20 || s.MaxY != 29 {
+		t.Errorf("ranges [%v, %v] and [%v, %v], want the data ranges", s.MinX, s.MaxX, s.MinY, s.MaxY)
+	}
+	if result.Pairs[1] != 1000 {
+		t.Errorf("%d pairs in range, want all 1000", result.Pairs[1])
+	}
+
+	clipped, err := ShiftedMutualInformationResult(-1, 1, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{AutoRange: true, AutoRangeClip: 0.005})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := clipped.Settings; !(s.MaxX < 1000) || !(s.MinX > 0) {
+		t.Errorf("clipped X range [%v, %v] still spans the outlier", s.MinX, s.MaxX)
+	}
+	if clipped.Pairs[1] >= 1000 {
+		t.Errorf("%d pairs in the clipped range, want the tails out of range", clipped.Pairs[1])
+	}
+
+	if _, err := ShiftedMutualInformationWithOptions(-1, 1, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{AutoRange: true, AutoRangeClip: 0.5}); err == nil {
+		t.Error("expected error for a clip of 0.5")
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-1, 1, 8, 8, 0, 1, 0, 1, dataX, make([]float64, 1000), 1, ShiftOptions{AutoRange: true}); err == nil {
+		t.Error("expected error for constant dataY")
+	}
+}
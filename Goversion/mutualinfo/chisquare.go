@@ -0,0 +1,152 @@
+package mutualinfo
+
+import "math"
+
+// independenceDOF returns the degrees of freedom of a test of independence on
+// a binsX×binsY contingency table.
+func independenceDOF(binsX, binsY int) int {
+	return (binsX - 1) * (binsY - 1)
+}
+
+// regularizedGammaP returns the regularized lower incomplete gamma function
+// P(a, x), using the series expansion for x < a+1 and the continued fraction
+// of the complement otherwise.
+func regularizedGammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lgammaA, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lgammaA)
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return prefix * sum
+	}
+
+	// Lentz's algorithm for the continued fraction of Q(a, x).
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 1000; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return 1 - prefix*h
+}
+
+// chiSquareCDF returns P(X <= x) for X following a chi-square distribution
+// with df degrees of freedom.
+func chiSquareCDF(x float64, df int) float64 {
+	return regularizedGammaP(float64(df)/2, x/2)
+}
+
+// noncentralChiSquareCDF returns P(X <= x) for a noncentral chi-square
+// distribution with df degrees of freedom and non-centrality lambda,
+// summing the Poisson mixture of central distributions.
+func noncentralChiSquareCDF(x float64, df int, lambda float64) float64 {
+	if lambda == 0 {
+		return chiSquareCDF(x, df)
+	}
+	half := lambda / 2
+	var sum float64
+	// Sum outwards from the Poisson mode so that no significant term is skipped.
+	mode := int(half)
+	lgammaMode, _ := math.Lgamma(float64(mode) + 1)
+	logWeight := -half + float64(mode)*math.Log(half) - lgammaMode
+	for j, lw := mode, logWeight; j >= 0; j-- {
+		term := math.Exp(lw) * regularizedGammaP(float64(df)/2+float64(j), x/2)
+		sum += term
+		if term < 1e-16 && j < mode {
+			break
+		}
+		lw -= math.Log(half) - math.Log(float64(j))
+	}
+	for j, lw := mode+1, logWeight+math.Log(half)-math.Log(float64(mode)+1); ; j++ {
+		term := math.Exp(lw) * regularizedGammaP(float64(df)/2+float64(j), x/2)
+		sum += term
+		if term < 1e-16 {
+			break
+		}
+		lw += math.Log(half) - math.Log(float64(j)+1)
+	}
+	return math.Min(sum, 1)
+}
+
+// bisect returns the x in [lo, hi] with f(x) = target for a monotonically increasing f.
+func bisect(f func(float64) float64, target, lo, hi float64) float64 {
+	for f(hi) < target {
+		lo, hi = hi, 2*hi
+	}
+	for i := 0; i < 200 && hi-lo > 1e-12*math.Max(1, hi); i++ {
+		mid := (lo + hi) / 2
+		if f(mid) < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// chiSquareQuantile returns the p-quantile of a chi-square distribution with
+// df degrees of freedom.
+func chiSquareQuantile(p float64, df int) float64 {
+	return bisect(func(x float64) float64 { return chiSquareCDF(x, df) }, p, 0, float64(df)+10)
+}
+
+// MinimumDetectableMI returns the smallest mutual information in bits that a
+// test of independence at significance level alpha detects with the given
+// power for n samples on a binsX×binsY histogram. It uses the asymptotic
+// distribution of the G statistic 2*n*ln(2)*MI, which is chi-square under
+// independence and noncentral chi-square with non-centrality 2*n*ln(2)*MI
+// otherwise. Invalid arguments give NaN.
+func MinimumDetectableMI(n, binsX, binsY int, alpha, power float64) float64 {
+	df := independenceDOF(binsX, binsY)
+	if n < 1 || df < 1 || !(alpha > 0 && alpha < 1) || !(power > 0 && power < 1) {
+		return math.NaN()
+	}
+	critical := chiSquareQuantile(1-alpha, df)
+	if power <= alpha {
+		return 0
+	}
+	// The power 1-F(critical; df, λ) increases with λ.
+	lambda := bisect(func(l float64) float64 { return 1 - noncentralChiSquareCDF(critical, df, l) }, power, 0, float64(df)+10)
+	return lambda / (2 * float64(n) * math.Ln2)
+}
+
+// GTestStatistic returns the likelihood-ratio statistic G = 2*N*MI (MI in nats)
+// of the test of independence and its degrees of freedom. Under independence
+// G asymptotically follows a chi-square distribution with dof degrees of freedom.
+func (h *Histogram2D) GTestStatistic() (g float64, dof int) {
+	h.Mutex.Lock()
+	_, _, total := h.marginalCounts()
+	h.Mutex.Unlock()
+
+	dof = independenceDOF(h.BinsX, h.BinsY)
+	if total == 0 {
+		return 0, dof
+	}
+	return 2 * float64(total) * math.Ln2 * h.CalculateMutualInformation(), dof
+}
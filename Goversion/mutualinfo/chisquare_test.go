@@ -0,0 +1,80 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChiSquareCDF(t *testing.T) {
+	// Reference values from standard chi-square tables.
+	cases := []struct {
+		x    float64
+		df   int
+		want float64
+	}{
+		{3.841458820694124, 1, 0.95},
+		{5.991464547107979, 2, 0.95},
+		{18.307038053275146, 10, 0.95},
+		{2, 2, 1 - math.Exp(-1)},
+	}
+	for _, c := range cases {
+		if got := chiSquareCDF(c.x, c.df); !almostEqual(got, c.want, 1e-9) {
+			t.Errorf("chiSquareCDF(%v, %d) = %v, want %v", c.x, c.df, got, c.want)
+		}
+	}
+	if q := chiSquareQuantile(0.95, 10); !almostEqual(q, 18.307038053275146, 1e-6) {
+		t.Errorf("chiSquareQuantile(0.95, 10) = %v", q)
+	}
+}
+
+func TestNoncentralChiSquareCDF(t *testing.T) {
+	if got, want := noncentralChiSquareCDF(5, 3, 0), chiSquareCDF(5, 3); !almostEqual(got, want, 1e-12) {
+		t.Errorf("zero non-centrality: got %v, want %v", got, want)
+	}
+	// For df=1, X = (Z+sqrt(λ))², so P(X <= x) = Φ(√x-√λ) - Φ(-√x-√λ).
+	phi := func(z float64) float64 { return 0.5 * math.Erfc(-z/math.Sqrt2) }
+	x, lambda := 7.0, 4.0
+	want := phi(math.Sqrt(x)-math.Sqrt(lambda)) - phi(-math.Sqrt(x)-math.Sqrt(lambda))
+	if got := noncentralChiSquareCDF(x, 1, lambda); !almostEqual(got, want, 1e-9) {
+		t.Errorf("noncentralChiSquareCDF = %v, want %v", got, want)
+	}
+}
+
+func TestMinimumDetectableMI(t *testing.T) {
+	small := MinimumDetectableMI(100, 5, 5, 0.05, 0.8)
+	large := MinimumDetectableMI(10000, 5, 5, 0.05, 0.8)
+	if !(small > 0 && large > 0 && large < small) {
+		t.Errorf("detectable MI must shrink with sample size: %v, %v", small, large)
+	}
+	// The non-centrality scales with n, so the detectable MI goes with 1/n.
+	if !almostEqual(small/large, 100, 1e-6) {
+		t.Errorf("expected 1/n scaling, got ratio %v", small/large)
+	}
+	if !math.IsNaN(MinimumDetectableMI(100, 1, 5, 0.05, 0.8)) {
+		t.Error("expected NaN for a single bin")
+	}
+}
+
+func TestGTestStatistic(t *testing.T) {
+	hist := NewHistogram2D(2, 3, 0, 2, 0, 3)
+	// Observed counts [[10, 20, 30], [30, 20, 10]].
+	counts := [][]int{{10, 20, 30}, {30, 20, 10}}
+	for i, row := range counts {
+		for j, c := range row {
+			for k := 0; k < c; k++ {
+				hist.Increment(float64(i)+0.5, float64(j)+0.5)
+			}
+		}
+	}
+	var want float64
+	for _, row := range counts {
+		for _, o := range row {
+			// Every cell expects 60*40/120 = 20 under independence.
+			want += 2 * float64(o) * math.Log(float64(o)/20)
+		}
+	}
+	g, dof := hist.GTestStatistic()
+	if !almostEqual(g, want, 1e-9) || dof != 2 {
+		t.Errorf("GTestStatistic = %v, %d, want %v, 2", g, dof, want)
+	}
+}
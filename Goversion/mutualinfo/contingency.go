@@ -0,0 +1,97 @@
+package mutualinfo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ReadContingencyTable parses a matrix of non-negative counts with one row per
+// line and the cells separated by commas, tabs or spaces. Empty lines are skipped.
+func ReadContingencyTable(r io.Reader) ([][]int, error) {
+	var table [][]int
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.FieldsFunc(scanner.Text(), func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t' || c == '\r'
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		if len(table) > 0 && len(fields) != len(table[0]) {
+			return nil, invalid(ErrSizeMismatch, "table", fmt.Sprintf("line %d: has %d cells, expected %d", line, len(fields), len(table[0])))
+		}
+		row := make([]int, len(fields))
+		for i, field := range fields {
+			c, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if c < 0 {
+				return nil, invalid(ErrInvalidData, "table", fmt.Sprintf("line %d: counts must not be negative", line))
+			}
+			row[i] = c
+		}
+		table = append(table, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, invalid(ErrTooFewSamples, "table", "contingency table is empty")
+	}
+	return table, nil
+}
+
+func countsToJoint(counts [][]int) [][]float64 {
+	joint := make([][]float64, len(counts))
+	for i, row := range counts {
+		joint[i] = make([]float64, len(row))
+		for j, c := range row {
+			joint[i][j] = float64(c)
+		}
+	}
+	return joint
+}
+
+// MutualInformationFromCounts returns the mutual information in bits of a
+// contingency table of co-occurrence counts.
+func MutualInformationFromCounts(counts [][]int) (float64, error) {
+	return MutualInformationFromJoint(countsToJoint(counts))
+}
+
+// PointwiseMutualInformation returns log2(p(x,y)/(p(x)p(y))) for every cell
+// of a contingency table, the classic word-association score. Empty cells
+// have a PMI of -Inf.
+func PointwiseMutualInformation(counts [][]int) ([][]float64, error) {
+	joint := countsToJoint(counts)
+	// Validates shape and contents.
+	if _, err := MutualInformationFromJoint(joint); err != nil {
+		return nil, err
+	}
+	rows := make([]float64, len(joint))
+	cols := make([]float64, len(joint[0]))
+	var total float64
+	for i, row := range joint {
+		for j, c := range row {
+			rows[i] += c
+			cols[j] += c
+			total += c
+		}
+	}
+	pmi := make([][]float64, len(joint))
+	for i, row := range joint {
+		pmi[i] = make([]float64, len(row))
+		for j, c := range row {
+			if c == 0 {
+				pmi[i][j] = math.Inf(-1)
+				continue
+			}
+			pmi[i][j] = math.Log2(c * total / (rows[i] * cols[j]))
+		}
+	}
+	return pmi, nil
+}
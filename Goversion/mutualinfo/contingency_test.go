@@ -0,0 +1,34 @@
+package mutualinfo
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadContingencyTable(t *testing.T) {
+	table, err := ReadContingencyTable(strings.NewReader("4, 0\n\n0\t4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 2 || table[0][0] != 4 || table[1][1] != 4 || table[0][1] != 0 {
+		t.Fatalf("unexpected table %v", table)
+	}
+	mi, err := MutualInformationFromCounts(table)
+	if err != nil || !almostEqual(mi, 1, 1e-12) {
+		t.Errorf("MutualInformationFromCounts = %v, %v", mi, err)
+	}
+	pmi, err := PointwiseMutualInformation(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(pmi[0][0], 1, 1e-12) || !math.IsInf(pmi[0][1], -1) {
+		t.Errorf("unexpected PMI %v", pmi)
+	}
+
+	for _, bad := range []string{"1 2\n3\n", "1 x\n", "1 -2\n", ""} {
+		if _, err := ReadContingencyTable(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
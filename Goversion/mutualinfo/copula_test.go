@@ -0,0 +1,66 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRanks(t *testing.T) {
+	got := ranks([]float64{3, 1, 2, 1})
+	want := []float64{4, 1.5, 3, 1.5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ranks = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestGaussianCopulaMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	rho := 0.8
+	n := 20000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		a, b := rng.NormFloat64(), rng.NormFloat64()
+		dataX[i] = a
+		// A monotone transform of the marginal must not change the estimate.
+		dataY[i] = math.Exp(rho*a + math.Sqrt(1-rho*rho)*b)
+	}
+	want, _ := GaussianMutualInformation(rho)
+	got, err := GaussianCopulaMutualInformation(dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(got, want, 0.02) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, err := GaussianCopulaMutualInformation(dataX, dataY[:10]); err == nil {
+		t.Error("expected error for differing sizes")
+	}
+}
+
+func TestRankMutualInformation(t *testing.T) {
+	n := 2000
+	dataX := make([]float64, n)
+	monotone := make([]float64, n)
+	parabola := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = float64(i)/float64(n-1)*2 - 1
+		monotone[i] = math.Exp(dataX[i])
+		parabola[i] = dataX[i] * dataX[i]
+	}
+	mi, rho, err := RankMutualInformation(dataX, monotone, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(rho, 1, 1e-12) || !almostEqual(mi, 3, 1e-9) {
+		t.Errorf("monotone: MI %v, rho %v", mi, rho)
+	}
+	mi, rho, _ = RankMutualInformation(dataX, parabola, 8)
+	if math.Abs(rho) > 0.05 || mi < 1 {
+		t.Errorf("non-monotone: expected large MI and small rho, got MI %v, rho %v", mi, rho)
+	}
+}
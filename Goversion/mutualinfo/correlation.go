@@ -0,0 +1,154 @@
+package mutualinfo
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// DistanceCorrelation returns the distance correlation of Székely, Rizzo and
+// Bakirov of dataX and dataY, which is 0 exactly if they are independent and
+// unlike the Pearson correlation also detects non-monotone dependence. It is
+// in [0, 1], with 0 for constant data. The distances of all pairs are
+// summed without storing them, which takes O(n²) time but only O(n) memory.
+func DistanceCorrelation(dataX, dataY []float64) (float64, error) {
+	if len(dataX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if len(dataX) < 2 {
+		return 0, invalid(ErrTooFewSamples, "data", "there must be at least two samples")
+	}
+	if err := CheckFinite("dataX", dataX); err != nil {
+		return 0, err
+	}
+	if err := CheckFinite("dataY", dataY); err != nil {
+		return 0, err
+	}
+	return distanceCorrelation(dataX, dataY), nil
+}
+
+// distanceCorrelation is DistanceCorrelation of finite data of the same size.
+//
+// With the distances a_ij = |x_i - x_j|, their row means a_i and grand mean
+// a, and likewise for y, the double-centered product sum is
+// Σ a_ij b_ij - 2n Σ a_i b_i + n² a b, as the centering terms of one matrix
+// vanish against the other, centered one.
+func distanceCorrelation(x, y []float64) float64 {
+	n := len(x)
+	rowX, rowY := make([]float64, n), make([]float64, n)
+	var sumXY, sumXX, sumYY float64
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			a, b := math.Abs(x[i]-x[j]), math.Abs(y[i]-y[j])
+			rowX[i] += a
+			rowY[i] += b
+			sumXY += a * b
+			sumXX += a * a
+			sumYY += b * b
+		}
+	}
+	var meanX, meanY, rowsXY, rowsXX, rowsYY float64
+	for i := range rowX {
+		rowX[i] /= float64(n)
+		rowY[i] /= float64(n)
+		meanX += rowX[i] / float64(n)
+		meanY += rowY[i] / float64(n)
+		rowsXY += rowX[i] * rowY[i]
+		rowsXX += rowX[i] * rowX[i]
+		rowsYY += rowY[i] * rowY[i]
+	}
+	centered := func(sum, rows, meanA, meanB float64) float64 {
+		nf := float64(n)
+		return sum/(nf*nf) - 2*rows/nf + meanA*meanB
+	}
+	covXY := centered(sumXY, rowsXY, meanX, meanY)
+	varX := centered(sumXX, rowsXX, meanX, meanX)
+	varY := centered(sumYY, rowsYY, meanY, meanY)
+	if !(varX > 0) || !(varY > 0) {
+		return 0
+	}
+	return clamp(math.Sqrt(math.Max(covXY, 0)/math.Sqrt(varX*varY)), 0, 1)
+}
+
+// ShiftedCrossCorrelation returns the Pearson correlation of dataX and dataY
+// for every shift from shiftFrom to shiftTo in steps of shiftStep, with the
+// pairs of a shift as in ShiftedMutualInformation, e.g. to check the MI
+// curve against linear dependence at the same lags. Pairs with a NaN or
+// infinite value are skipped. A shift with fewer than two pairs gives NaN,
+// constant data 0.
+func ShiftedCrossCorrelation(shiftFrom, shiftTo int, dataX, dataY []float64, shiftStep int) ([]float64, error) {
+	return shiftedCorrelation(shiftFrom, shiftTo, dataX, dataY, shiftStep, pearson)
+}
+
+// ShiftedDistanceCorrelation is ShiftedCrossCorrelation with the distance
+// correlation, see DistanceCorrelation, which takes O(n²) time per shift.
+func ShiftedDistanceCorrelation(shiftFrom, shiftTo int, dataX, dataY []float64, shiftStep int) ([]float64, error) {
+	return shiftedCorrelation(shiftFrom, shiftTo, dataX, dataY, shiftStep, distanceCorrelation)
+}
+
+// shiftedCorrelation evaluates measure on the finite pairs of every shift
+// on a pool of runtime.NumCPU() goroutines.
+func shiftedCorrelation(shiftFrom, shiftTo int, dataX, dataY []float64, shiftStep int, measure func(x, y []float64) float64) ([]float64, error) {
+	if len(dataX) != len(dataY) {
+		return nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	n := len(dataX)
+	if maxInt(abs(shiftFrom), abs(shiftTo)) >= n {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shifts must be smaller than the data size")
+	}
+
+	values := make([]float64, (shiftTo-shiftFrom)/shiftStep+1)
+	shifts := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < minInt(runtime.NumCPU(), len(values)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			x, y := make([]float64, 0, n), make([]float64, 0, n)
+			for shift := range shifts {
+				x, y = x[:0], y[:0]
+				for j := maxInt(0, -shift); j < minInt(n, n-shift); j++ {
+					if isFinite(dataX[j+shift]) && isFinite(dataY[j]) {
+						x, y = append(x, dataX[j+shift]), append(y, dataY[j])
+					}
+				}
+				value := math.NaN()
+				if len(x) >= 2 {
+					value = measure(x, y)
+				}
+				values[(shift-shiftFrom)/shiftStep] = value
+			}
+		}()
+	}
+	for shift := shiftFrom; shift <= shiftTo; shift += shiftStep {
+		shifts <- shift
+	}
+	close(shifts)
+	wg.Wait()
+	return values, nil
+}
+
+// AddCorrelations fills r.Correlation with ShiftedCrossCorrelation of dataX
+// and dataY over the shifts of r, and r.DistanceCorrelation with
+// ShiftedDistanceCorrelation if distance, so that r holds the linear,
+// distance and information measures side by side. dataX and dataY must be
+// the data r was estimated from.
+func (r *Result) AddCorrelations(dataX, dataY []float64, distance bool) error {
+	s := r.Settings
+	var err error
+	if r.Correlation, err = ShiftedCrossCorrelation(s.ShiftFrom, s.ShiftTo, dataX, dataY, s.ShiftStep); err != nil {
+		return err
+	}
+	if distance {
+		if r.DistanceCorrelation, err = ShiftedDistanceCorrelation(s.ShiftFrom, s.ShiftTo, dataX, dataY, s.ShiftStep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// naiveDistanceCorrelation double-centers the full distance matrices.
+func naiveDistanceCorrelation(x, y []float64) float64 {
+	n := len(x)
+	centered := func(data []float64) [][]float64 {
+		d := make([][]float64, n)
+		rows := make([]float64, n)
+		var mean float64
+		for i := range d {
+			d[i] = make([]float64, n)
+			for j := range d[i] {
+				d[i][j] = math.Abs(data[i] - data[j])
+				rows[i] += d[i][j] / float64(n)
+			}
+			mean += rows[i] / float64(n)
+		}
+		for i := range d {
+			for j := range d[i] {
+				d[i][j] += mean - rows[i] - rows[j]
+			}
+		}
+		return d
+	}
+	a, b := centered(x), centered(y)
+	var xy, xx, yy float64
+	for i := range a {
+		for j := range a[i] {
+			xy += a[i][j] * b[i][j]
+			xx += a[i][j] * a[i][j]
+			yy += b[i][j] * b[i][j]
+		}
+	}
+	return math.Sqrt(xy / math.Sqrt(xx*yy))
+}
+
+func TestDistanceCorrelation(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	x := make([]float64, 60)
+	y := make([]float64, len(x))
+	square := make([]float64, len(x))
+	for i := range x {
+		x[i] = rng.NormFloat64()
+		y[i] = 0.3*x[i] + rng.NormFloat64()
+		square[i] = x[i] * x[i]
+	}
+	got, err := DistanceCorrelation(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := naiveDistanceCorrelation(x, y); !almostEqual(got, want, 1e-9) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, _ := DistanceCorrelation(x, x); !almostEqual(got, 1, 1e-9) {
+		t.Errorf("identical data gives %v, want 1", got)
+	}
+	// The dependence of x² on x is invisible to the Pearson correlation.
+	if got, _ := DistanceCorrelation(x, square); got < 0.3 || math.Abs(pearson(x, square)) > 0.3 {
+		t.Errorf("got distance correlation %v and Pearson %v for x²", got, pearson(x, square))
+	}
+	if got, _ := DistanceCorrelation(x, make([]float64, len(x))); got != 0 {
+		t.Errorf("constant data gives %v, want 0", got)
+	}
+	if _, err := DistanceCorrelation(x, y[1:]); err == nil {
+		t.Error("expected error for data of different sizes")
+	}
+}
+
+func TestShiftedCorrelations(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	dataX := make([]float64, 300)
+	dataY := make([]float64, len(dataX))
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+	}
+	for j := range dataY {
+		dataY[j] = dataX[(j+4)%len(dataX)]
+	}
+	dataX[10] = math.NaN()
+	result, err := ShiftedMutualInformationResult(-6, 6, 8, 8, -4, 4, -4, 4, dataX, dataY, 2, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.AddCorrelations(dataX, dataY, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Correlation) != 7 || len(result.DistanceCorrelation) != 7 {
+		t.Fatalf("got %d and %d values, want 7", len(result.Correlation), len(result.DistanceCorrelation))
+	}
+	for i, shift := range result.Shifts {
+		r, dcor := result.Correlation[i], result.DistanceCorrelation[i]
+		if shift == 4 {
+			if !almostEqual(r, 1, 1e-12) || !almostEqual(dcor, 1, 1e-9) {
+				t.Errorf("shift 4 gives %v and %v, want 1", r, dcor)
+			}
+		} else if math.Abs(r) > 0.2 || dcor > 0.2 {
+			t.Errorf("shift %d gives %v and %v, want about 0", shift, r, dcor)
+		}
+	}
+	if _, err := ShiftedCrossCorrelation(-300, 0, dataX, dataY, 1); err == nil {
+		t.Error("expected error for a shift as large as the data")
+	}
+}
@@ -0,0 +1,84 @@
+package mutualinfo
+
+// DistinctBinsX returns the number of X bins holding at least one sample.
+// A value far below BinsX indicates that the X range is much wider than the data.
+func (h *Histogram2D) DistinctBinsX() int {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	rows, _, _ := h.marginalCounts()
+	return countNonZero(rows)
+}
+
+// DistinctBinsY returns the number of Y bins holding at least one sample.
+func (h *Histogram2D) DistinctBinsY() int {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	_, cols, _ := h.marginalCounts()
+	return countNonZero(cols)
+}
+
+// Joint returns the joint distribution p(x, y) of the histogram, the weights
+// of WeightedData if set and the counts otherwise divided by their total.
+// An empty histogram gives all zeros.
+func (h *Histogram2D) Joint() [][]float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	cell := func(i, j int) float64 { return float64(h.Data[i][j]) }
+	if h.WeightedData != nil {
+		cell = func(i, j int) float64 { return h.WeightedData[i][j] }
+	}
+	total := 0.0
+	for i := 0; i < h.BinsX; i++ {
+		for j := 0; j < h.BinsY; j++ {
+			total += cell(i, j)
+		}
+	}
+	joint := make([][]float64, h.BinsX)
+	for i := range joint {
+		joint[i] = make([]float64, h.BinsY)
+		if total == 0 {
+			continue
+		}
+		for j := range joint[i] {
+			joint[i][j] = cell(i, j) / total
+		}
+	}
+	return joint
+}
+
+// MarginalX returns the marginal distribution p(x), see Joint.
+func (h *Histogram2D) MarginalX() []float64 {
+	joint := h.Joint()
+	marginal := make([]float64, h.BinsX)
+	for i := range joint {
+		for _, p := range joint[i] {
+			marginal[i] += p
+		}
+	}
+	return marginal
+}
+
+// MarginalY returns the marginal distribution p(y), see Joint.
+func (h *Histogram2D) MarginalY() []float64 {
+	joint := h.Joint()
+	marginal := make([]float64, h.BinsY)
+	for i := range joint {
+		for j, p := range joint[i] {
+			marginal[j] += p
+		}
+	}
+	return marginal
+}
+
+func countNonZero(counts []int) int {
+	n := 0
+	for _, c := range counts {
+		if c != 0 {
+			n++
+		}
+	}
+	return n
+}
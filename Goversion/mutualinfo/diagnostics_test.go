@@ -0,0 +1,36 @@
+package mutualinfo
+
+import "testing"
+
+func TestDistinctBins(t *testing.T) {
+	hist := NewHistogram2D(100, 10, 0, 100, 0, 10)
+	for i := 0; i < 50; i++ {
+		hist.Increment(float64(i%3), float64(i%10))
+	}
+	if got := hist.DistinctBinsX(); got != 3 {
+		t.Errorf("DistinctBinsX = %d, want 3", got)
+	}
+	if got := hist.DistinctBinsY(); got != 10 {
+		t.Errorf("DistinctBinsY = %d, want 10", got)
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	hist := NewHistogram2D(2, 3, 0, 2, 0, 3)
+	if joint := hist.Joint(); joint[1][2] != 0 || len(joint) != 2 || len(joint[0]) != 3 {
+		t.Errorf("empty histogram gives %v, want zeros", joint)
+	}
+	for _, p := range [][2]float64{{0.5, 0.5}, {0.5, 2.5}, {1.5, 2.5}, {1.5, 2.5}} {
+		hist.Increment(p[0], p[1])
+	}
+	joint := hist.Joint()
+	if joint[0][0] != 0.25 || joint[0][2] != 0.25 || joint[1][2] != 0.5 {
+		t.Errorf("joint = %v", joint)
+	}
+	if mx := hist.MarginalX(); mx[0] != 0.5 || mx[1] != 0.5 {
+		t.Errorf("MarginalX = %v", mx)
+	}
+	if my := hist.MarginalY(); my[0] != 0.25 || my[1] != 0 || my[2] != 0.75 {
+		t.Errorf("MarginalY = %v", my)
+	}
+}
@@ -0,0 +1,89 @@
+package mutualinfo
+
+type discretePair[T comparable] struct {
+	x, y T
+}
+
+// discreteCounts counts the labels of x and y and their co-occurrences in
+// a contingency map.
+type discreteCounts[T comparable] struct {
+	x, y  map[T]int
+	joint map[discretePair[T]]int
+}
+
+func newDiscreteCounts[T comparable]() *discreteCounts[T] {
+	return &discreteCounts[T]{x: map[T]int{}, y: map[T]int{}, joint: map[discretePair[T]]int{}}
+}
+
+func (c *discreteCounts[T]) fill(x, y []T) {
+	clear(c.x)
+	clear(c.y)
+	clear(c.joint)
+	for i := range x {
+		c.x[x[i]]++
+		c.y[y[i]]++
+		c.joint[discretePair[T]{x[i], y[i]}]++
+	}
+}
+
+func (c *discreteCounts[T]) mutualInformation(norm Normalization) float64 {
+	return normalizeMutualInformation(countsEntropy(c.x), countsEntropy(c.y), countsEntropy(c.joint), norm)
+}
+
+// MutualInformationDiscrete calculates the mutual information in bits of
+// two label sequences, e.g. ints or strings, from the counts of every label
+// and pair of labels. No binning is involved; every distinct value is its
+// own category.
+func MutualInformationDiscrete[T comparable](x, y []T) (float64, error) {
+	return NormalizedMutualInformationDiscrete(x, y, NormalizationNone)
+}
+
+// NormalizedMutualInformationDiscrete is MutualInformationDiscrete divided
+// by the denominator selected by norm, see
+// CalculateNormalizedMutualInformation.
+func NormalizedMutualInformationDiscrete[T comparable](x, y []T, norm Normalization) (float64, error) {
+	if len(x) != len(y) {
+		return 0, invalid(ErrSizeMismatch, "x", "x and y must have the same size")
+	}
+	if len(x) == 0 {
+		return 0, invalid(ErrTooFewSamples, "x", "x and y must not be empty")
+	}
+	if norm < NormalizationNone || norm > NormalizationRedundancy {
+		return 0, invalid(ErrInvalidOption, "Normalization", "unknown normalization")
+	}
+	counts := newDiscreteCounts[T]()
+	counts.fill(x, y)
+	return counts.mutualInformation(norm), nil
+}
+
+// ShiftedMutualInformationDiscrete calculates the mutual information of the
+// label sequences x and y, normalized by norm, for every shift from
+// shiftFrom to shiftTo in steps of shiftStep. As in ShiftedMutualInformation,
+// a shift pairs x[j+shift] with y[j].
+func ShiftedMutualInformationDiscrete[T comparable](shiftFrom, shiftTo int, x, y []T, shiftStep int, norm Normalization) ([]float64, error) {
+	if len(x) != len(y) {
+		return nil, invalid(ErrSizeMismatch, "x", "x and y must have the same size")
+	}
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if abs(shiftFrom) >= len(x) || abs(shiftTo) >= len(x) {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shifts must be smaller than the data size")
+	}
+	if norm < NormalizationNone || norm > NormalizationRedundancy {
+		return nil, invalid(ErrInvalidOption, "Normalization", "unknown normalization")
+	}
+
+	n := len(x)
+	mi := make([]float64, 0, (shiftTo-shiftFrom)/shiftStep+1)
+	counts := newDiscreteCounts[T]()
+	for shift := shiftFrom; shift <= shiftTo; shift += shiftStep {
+		from, to := maxInt(0, -shift), minInt(n, n-shift)
+		counts.fill(x[from+shift:to+shift], y[from:to])
+		mi = append(mi, counts.mutualInformation(norm))
+	}
+	return mi, nil
+}
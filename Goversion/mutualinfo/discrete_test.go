@@ -0,0 +1,67 @@
+package mutualinfo
+
+import "testing"
+
+func TestMutualInformationDiscrete(t *testing.T) {
+	weather := []string{"sun", "rain", "sun", "snow", "rain", "sun", "sun", "snow"}
+	codes := []int{0, 1, 0, 2, 1, 0, 0, 2}
+	mi, err := MutualInformationDiscrete(codes, codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// H = -(1/2 log 1/2 + 2 * 1/4 log 1/4) = 1.5 bits.
+	if !almostEqual(mi, 1.5, 1e-12) {
+		t.Errorf("MI of identical labels = %v, want 1.5", mi)
+	}
+	// A relabeling carries all of the information.
+	nmi, err := NormalizedMutualInformationDiscrete(weather, []string{"a", "b", "a", "c", "b", "a", "a", "c"}, NormalizationSymmetric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(nmi, 1, 1e-12) {
+		t.Errorf("normalized MI of a relabeling = %v, want 1", nmi)
+	}
+
+	// Every combination once: independent.
+	mi, err = MutualInformationDiscrete([]string{"a", "a", "b", "b"}, []string{"x", "y", "x", "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(mi, 0, 1e-12) {
+		t.Errorf("MI of independent labels = %v, want 0", mi)
+	}
+
+	if _, err := MutualInformationDiscrete([]int{1, 2}, []int{1}); err == nil {
+		t.Error("expected error for a length mismatch")
+	}
+	if _, err := MutualInformationDiscrete([]int{}, []int{}); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
+
+func TestShiftedMutualInformationDiscrete(t *testing.T) {
+	// y repeats x two steps later, so shift -2 pairs every x with its copy.
+	x := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3, 2, 3, 8, 4}
+	y := make([]int, len(x))
+	for j := 2; j < len(y); j++ {
+		y[j] = x[j-2]
+	}
+	mi, err := ShiftedMutualInformationDiscrete(-3, 3, x, y, 1, NormalizationMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peak, value, err := PeakShift(-3, 3, 1, mi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak != -2 || !almostEqual(value, 1, 1e-12) {
+		t.Errorf("peak %v at shift %d, want 1 at shift -2", value, peak)
+	}
+	want, _ := NormalizedMutualInformationDiscrete(x[:len(x)-2], y[2:], NormalizationMin)
+	if !almostEqual(mi[1], want, 1e-12) {
+		t.Errorf("shift -2: %v, want %v", mi[1], want)
+	}
+	if _, err := ShiftedMutualInformationDiscrete(-3, 3, x, y, 1, Normalization(42)); err == nil {
+		t.Error("expected error for an unknown normalization")
+	}
+}
@@ -0,0 +1,167 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// smoothedDistributions validates the counts p and q of the same bins and
+// returns them normalized after adding smoothing to every bin of both.
+func smoothedDistributions(p, q []float64, smoothing float64) ([]float64, []float64, error) {
+	if len(p) != len(q) {
+		return nil, nil, invalid(ErrSizeMismatch, "p", "p and q must have the same number of bins")
+	}
+	if len(p) == 0 {
+		return nil, nil, invalid(ErrTooFewSamples, "p", "p and q must not be empty")
+	}
+	if !(smoothing >= 0) || math.IsInf(smoothing, 1) {
+		return nil, nil, invalid(ErrInvalidParameter, "smoothing", "smoothing must be finite and not negative")
+	}
+	normalize := func(counts []float64) ([]float64, error) {
+		var total neumaierSum
+		for _, c := range counts {
+			if !(c >= 0) || math.IsInf(c, 1) {
+				return nil, invalid(ErrInvalidData, "counts", "counts must be finite and not negative")
+			}
+			total.Add(c + smoothing)
+		}
+		if total.Value() == 0 {
+			return nil, invalid(ErrInvalidData, "counts", "counts must not all be zero")
+		}
+		dist := make([]float64, len(counts))
+		for i, c := range counts {
+			dist[i] = (c + smoothing) / total.Value()
+		}
+		return dist, nil
+	}
+	pn, err := normalize(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	qn, err := normalize(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pn, qn, nil
+}
+
+// CrossEntropy returns the cross-entropy -Σ p log2 q in bits of the
+// distributions given by the counts or weights p and q of the same bins,
+// which need not be normalized. smoothing, e.g. 0.5 or 1, is added to every
+// bin of both before normalizing, so that bins that are empty in q but not
+// in p do not make the result +Inf, which it is without smoothing.
+func CrossEntropy(p, q []float64, smoothing float64) (float64, error) {
+	pn, qn, err := smoothedDistributions(p, q, smoothing)
+	if err != nil {
+		return 0, err
+	}
+	var h neumaierSum
+	for i := range pn {
+		if pn[i] > 0 {
+			if qn[i] == 0 {
+				return math.Inf(1), nil
+			}
+			h.Add(-pn[i] * math.Log2(qn[i]))
+		}
+	}
+	return h.Value(), nil
+}
+
+// KLDivergence returns the Kullback–Leibler divergence D(p||q) = Σ p log2(p/q)
+// in bits, smoothing as in CrossEntropy. It is +Inf if q has an empty bin
+// where p has not, and not symmetric in p and q.
+func KLDivergence(p, q []float64, smoothing float64) (float64, error) {
+	pn, qn, err := smoothedDistributions(p, q, smoothing)
+	if err != nil {
+		return 0, err
+	}
+	var d neumaierSum
+	for i := range pn {
+		if pn[i] > 0 {
+			if qn[i] == 0 {
+				return math.Inf(1), nil
+			}
+			d.Add(pn[i] * math.Log2(pn[i]/qn[i]))
+		}
+	}
+	return math.Max(d.Value(), 0), nil
+}
+
+// JSDivergence returns the Jensen–Shannon divergence in bits, the mean KL
+// divergence of p and q from their mixture m = (p+q)/2. It is symmetric,
+// finite even for empty bins and at most 1; its square root is a metric.
+func JSDivergence(p, q []float64) (float64, error) {
+	pn, qn, err := smoothedDistributions(p, q, 0)
+	if err != nil {
+		return 0, err
+	}
+	var d neumaierSum
+	for i := range pn {
+		m := (pn[i] + qn[i]) / 2
+		if pn[i] > 0 {
+			d.Add(pn[i] / 2 * math.Log2(pn[i]/m))
+		}
+		if qn[i] > 0 {
+			d.Add(qn[i] / 2 * math.Log2(qn[i]/m))
+		}
+	}
+	return clamp(d.Value(), 0, 1), nil
+}
+
+// cells returns the counts of the histogram, or the weights of WeightedData
+// if set, row by row.
+func (h *Histogram2D) cells() []float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	cells := make([]float64, 0, h.BinsX*h.BinsY)
+	for i := 0; i < h.BinsX; i++ {
+		for j := 0; j < h.BinsY; j++ {
+			if h.WeightedData != nil {
+				cells = append(cells, h.WeightedData[i][j])
+			} else {
+				cells = append(cells, float64(h.Data[i][j]))
+			}
+		}
+	}
+	return cells
+}
+
+// sameBins returns an error unless h and other have the same bins.
+func (h *Histogram2D) sameBins(other *Histogram2D) error {
+	if h.BinsX != other.BinsX || h.BinsY != other.BinsY {
+		return invalid(ErrSizeMismatch, "histograms", "histograms must have the same number of bins")
+	}
+	if h.MinX != other.MinX || h.MaxX != other.MaxX || h.MinY != other.MinY || h.MaxY != other.MaxY {
+		return invalid(ErrSizeMismatch, "histograms", "histograms must have the same ranges")
+	}
+	return nil
+}
+
+// KLDivergence returns the KL divergence D(h||other) of the joint
+// distributions of two histograms with the same bins, see KLDivergence.
+// Pass h.MarginalX() and other.MarginalX() to the function to compare
+// marginals instead.
+func (h *Histogram2D) KLDivergence(other *Histogram2D, smoothing float64) (float64, error) {
+	if err := h.sameBins(other); err != nil {
+		return 0, err
+	}
+	return KLDivergence(h.cells(), other.cells(), smoothing)
+}
+
+// JSDivergence returns the Jensen–Shannon divergence of the joint
+// distributions of two histograms with the same bins.
+func (h *Histogram2D) JSDivergence(other *Histogram2D) (float64, error) {
+	if err := h.sameBins(other); err != nil {
+		return 0, err
+	}
+	return JSDivergence(h.cells(), other.cells())
+}
+
+// CrossEntropy returns the cross-entropy of the joint distribution of other
+// relative to that of h, two histograms with the same bins.
+func (h *Histogram2D) CrossEntropy(other *Histogram2D, smoothing float64) (float64, error) {
+	if err := h.sameBins(other); err != nil {
+		return 0, err
+	}
+	return CrossEntropy(h.cells(), other.cells(), smoothing)
+}
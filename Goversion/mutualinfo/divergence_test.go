@@ -0,0 +1,81 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDivergences(t *testing.T) {
+	p := []float64{1, 1, 2}
+	q := []float64{2, 1, 1}
+	// p = (1/4, 1/4, 1/2), q = (1/2, 1/4, 1/4).
+	kl, err := KLDivergence(p, q, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.25*math.Log2(0.5) + 0.5*math.Log2(2); !almostEqual(kl, want, 1e-12) {
+		t.Errorf("KL = %v, want %v", kl, want)
+	}
+	ce, _ := CrossEntropy(p, q, 0)
+	if h := entropyOf(p); !almostEqual(ce, h+kl, 1e-12) {
+		t.Errorf("cross-entropy %v, want H(p)+KL = %v", ce, h+kl)
+	}
+	if same, _ := KLDivergence(p, []float64{2, 2, 4}, 0); same != 0 {
+		t.Errorf("KL of proportional counts = %v, want 0", same)
+	}
+
+	// Disjoint support: KL is infinite unless smoothed, JS is 1.
+	p, q = []float64{3, 0}, []float64{0, 5}
+	if kl, _ := KLDivergence(p, q, 0); !math.IsInf(kl, 1) {
+		t.Errorf("KL of disjoint distributions = %v, want +Inf", kl)
+	}
+	if ce, _ := CrossEntropy(p, q, 0); !math.IsInf(ce, 1) {
+		t.Errorf("cross-entropy of disjoint distributions = %v, want +Inf", ce)
+	}
+	if kl, _ := KLDivergence(p, q, 0.5); math.IsInf(kl, 0) || !(kl > 0) {
+		t.Errorf("smoothed KL = %v, want finite and positive", kl)
+	}
+	js, err := JSDivergence(p, q)
+	if err != nil || js != 1 {
+		t.Errorf("JS of disjoint distributions = %v (%v), want 1", js, err)
+	}
+	a, _ := JSDivergence([]float64{1, 3}, []float64{2, 1})
+	b, _ := JSDivergence([]float64{2, 1}, []float64{1, 3})
+	if !almostEqual(a, b, 1e-15) {
+		t.Errorf("JS not symmetric: %v and %v", a, b)
+	}
+
+	for _, bad := range [][2][]float64{{{1}, {1, 2}}, {{0, 0}, {1, 2}}, {{-1, 2}, {1, 2}}} {
+		if _, err := KLDivergence(bad[0], bad[1], 0); err == nil {
+			t.Errorf("expected error for %v and %v", bad[0], bad[1])
+		}
+	}
+	if _, err := KLDivergence([]float64{1}, []float64{1}, -1); err == nil {
+		t.Error("expected error for negative smoothing")
+	}
+}
+
+func TestHistogramDivergences(t *testing.T) {
+	h := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	other := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	h.Increment(0.5, 0.5)
+	h.Increment(1.5, 1.5)
+	other.Increment(0.5, 0.5)
+	other.Increment(0.5, 1.5)
+	kl, err := h.KLDivergence(other, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := KLDivergence([]float64{1, 0, 0, 1}, []float64{1, 1, 0, 0}, 1); kl != want {
+		t.Errorf("histogram KL %v, want %v", kl, want)
+	}
+	if js, _ := h.JSDivergence(other); !almostEqual(js, 0.5, 1e-12) {
+		t.Errorf("histogram JS %v, want 0.5", js)
+	}
+	if _, err := h.CrossEntropy(NewHistogram2D(2, 3, 0, 2, 0, 2), 1); err == nil {
+		t.Error("expected error for different bins")
+	}
+	if _, err := h.KLDivergence(NewHistogram2D(2, 2, 0, 3, 0, 2), 1); err == nil {
+		t.Error("expected error for different ranges")
+	}
+}
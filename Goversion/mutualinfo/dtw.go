@@ -0,0 +1,136 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// DTWPath aligns dataX and dataY by dynamic time warping and returns the
+// warping path as index pairs {First: i, Second: j}, meaning dataX[i] is
+// aligned with dataY[j], ordered from {0, 0} to the last sample of both.
+//
+// The cost is the absolute difference of the z-scored values, so the series
+// may have different units. Only cells with |i-j| <= band are considered
+// (Sakoe–Chiba band), which bounds the local lag, keeps the alignment from
+// degenerating and limits the work to O(len * band). band must be at least
+// the difference of the lengths, and an error is returned for NaN or
+// infinite values.
+func DTWPath(dataX, dataY []float64, band int) ([]IndexPair, error) {
+	n, m := len(dataX), len(dataY)
+	if n == 0 || m == 0 {
+		return nil, invalid(ErrTooFewSamples, "dataX", "dataX and dataY must not be empty")
+	}
+	if band < abs(n-m) {
+		return nil, invalid(ErrInvalidParameter, "band", "band must be at least the difference of the lengths")
+	}
+	if err := CheckFinite("dataX", dataX); err != nil {
+		return nil, err
+	}
+	if err := CheckFinite("dataY", dataY); err != nil {
+		return nil, err
+	}
+	x := standardize(dataX)
+	y := standardize(dataY)
+
+	// cost[i][j-i+band] is the cheapest cost of aligning x[:i+1] with y[:j+1].
+	width := 2*band + 1
+	cost := make([][]float64, n)
+	for i := range cost {
+		cost[i] = make([]float64, width)
+		for k := range cost[i] {
+			cost[i][k] = math.Inf(1)
+		}
+	}
+	at := func(i, j int) float64 {
+		if i < 0 || j < 0 || abs(i-j) > band {
+			return math.Inf(1)
+		}
+		return cost[i][j-i+band]
+	}
+	for i := 0; i < n; i++ {
+		for j := maxInt(0, i-band); j < m && j <= i+band; j++ {
+			best := 0.0
+			if i > 0 || j > 0 {
+				best = math.Min(at(i-1, j-1), math.Min(at(i-1, j), at(i, j-1)))
+			}
+			cost[i][j-i+band] = best + math.Abs(x[i]-y[j])
+		}
+	}
+
+	path := []IndexPair{{First: n - 1, Second: m - 1}}
+	for i, j := n-1, m-1; i > 0 || j > 0; {
+		diagonal, up, left := at(i-1, j-1), at(i-1, j), at(i, j-1)
+		switch {
+		case i == 0:
+			j--
+		case j == 0:
+			i--
+		case diagonal <= up && diagonal <= left:
+			i, j = i-1, j-1
+		case up <= left:
+			i--
+		default:
+			j--
+		}
+		path = append(path, IndexPair{First: i, Second: j})
+	}
+	for a, b := 0, len(path)-1; a < b; a, b = a+1, b-1 {
+		path[a], path[b] = path[b], path[a]
+	}
+	return path, nil
+}
+
+// standardize returns data shifted to zero mean and scaled to unit standard
+// deviation. Constant data is only shifted.
+func standardize(data []float64) []float64 {
+	var sum neumaierSum
+	for _, v := range data {
+		sum.Add(v)
+	}
+	mean := sum.Value() / float64(len(data))
+	var sq neumaierSum
+	for _, v := range data {
+		sq.Add((v - mean) * (v - mean))
+	}
+	std := math.Sqrt(sq.Value() / float64(len(data)))
+	if std == 0 {
+		std = 1
+	}
+	result := make([]float64, len(data))
+	for i, v := range data {
+		result[i] = (v - mean) / std
+	}
+	return result
+}
+
+// warpedPairs is an XYSource over the pairs of a warping path.
+type warpedPairs struct {
+	x, y []float64
+	path []IndexPair
+}
+
+func (w warpedPairs) Len() int {
+	return len(w.path)
+}
+
+func (w warpedPairs) At(i int) (x, y float64) {
+	return w.x[w.path[i].First], w.y[w.path[i].Second]
+}
+
+// DTWMutualInformation aligns dataX and dataY with DTWPath and calculates
+// the mutual information of the aligned pairs. Unlike a global shift this
+// follows a lag that varies over time, up to band samples.
+//
+// Samples matched with several samples of the other series enter the
+// histogram once per match, and the alignment itself maximizes the
+// similarity of the pairs, so the MI is biased upwards compared to an
+// unaligned estimate. Compare against surrogates aligned the same way.
+func DTWMutualInformation(band, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, nil, nil); err != nil {
+		return 0, err
+	}
+	path, err := DTWPath(dataX, dataY, band)
+	if err != nil {
+		return 0, err
+	}
+	return MutualInformationSource(binsX, binsY, minX, maxX, minY, maxY, warpedPairs{x: dataX, y: dataY, path: path})
+}
@@ -0,0 +1,85 @@
+package mutualinfo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDTWPath(t *testing.T) {
+	// dataY is dataX delayed by two samples.
+	dataX := []float64{0, 0, 1, 3, 1, 0, 0, 0, 0}
+	dataY := []float64{0, 0, 0, 0, 1, 3, 1, 0, 0}
+	path, err := DTWPath(dataX, dataY, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, last := path[0], path[len(path)-1]
+	if first != (IndexPair{0, 0}) || last != (IndexPair{len(dataX) - 1, len(dataY) - 1}) {
+		t.Errorf("path runs from %v to %v", first, last)
+	}
+	for k := 1; k < len(path); k++ {
+		di, dj := path[k].First-path[k-1].First, path[k].Second-path[k-1].Second
+		if di < 0 || dj < 0 || di > 1 || dj > 1 || di+dj == 0 {
+			t.Fatalf("invalid step %v -> %v", path[k-1], path[k])
+		}
+	}
+	found := false
+	for _, p := range path {
+		if p == (IndexPair{3, 5}) {
+			found = true
+		}
+		if abs(p.First-p.Second) > 3 {
+			t.Errorf("pair %v leaves the band", p)
+		}
+	}
+	if !found {
+		t.Errorf("peaks are not aligned in %v", path)
+	}
+
+	if _, err := DTWPath(dataX, dataY[:4], 2); err == nil {
+		t.Error("expected error for a band below the length difference")
+	}
+
+	for _, bad := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		data := []float64{1, 2, 3, bad, 5, 6, 7, 8}
+		if _, err := DTWPath(data, dataY[:8], 2); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%v in dataX: got %v, want ErrInvalidData", bad, err)
+		}
+		if _, err := DTWMutualInformation(2, 4, 4, 0, 8, 0, 8, dataY[:8], data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%v in dataY: got %v, want ErrInvalidData", bad, err)
+		}
+	}
+
+	// A single row or column leaves only one direction to backtrack.
+	for _, lengths := range [][2]int{{1, 3}, {3, 1}} {
+		path, err := DTWPath(dataX[:lengths[0]], dataY[:lengths[1]], 2)
+		if err != nil || len(path) != 3 || path[0] != (IndexPair{0, 0}) {
+			t.Errorf("lengths %v: path %v, %v", lengths, path, err)
+		}
+	}
+}
+
+func TestDTWMutualInformationVariableLag(t *testing.T) {
+	// The lag between X and Y drifts from 0 to 6 samples, so no single
+	// shift aligns them.
+	n := 600
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = math.Sin(2 * math.Pi * float64(i) / 37)
+		lag := 6 * float64(i) / float64(n)
+		dataY[i] = math.Sin(2 * math.Pi * (float64(i) - lag) / 37)
+	}
+	aligned, err := DTWMutualInformation(8, 8, 8, -1, 1, -1, 1, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unaligned, err := MutualInformation(8, 8, -1, 1, -1, 1, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aligned <= unaligned {
+		t.Errorf("aligned MI %v should exceed unaligned MI %v", aligned, unaligned)
+	}
+}
@@ -0,0 +1,153 @@
+package mutualinfo
+
+import (
+	"sort"
+)
+
+// QuantileEdges returns bin edges placed at the quantiles of data so that
+// each of the bins bins holds roughly the same number of samples. Edges
+// that coincide because of repeated values are merged, so fewer than bins
+// bins may result. The first and last edge are the data minimum and maximum.
+func QuantileEdges(bins int, data []float64) ([]float64, error) {
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	if len(data) == 0 {
+		return nil, invalid(ErrTooFewSamples, "data", "data must not be empty")
+	}
+	sorted := sortedCopy(data)
+	edges := make([]float64, 0, bins+1)
+	for i := 0; i <= bins; i++ {
+		e := quantile(sorted, float64(i)/float64(bins))
+		if len(edges) == 0 || e > edges[len(edges)-1] {
+			edges = append(edges, e)
+		}
+	}
+	if len(edges) < 2 {
+		return nil, invalid(ErrInvalidRange, "data", "data has zero range")
+	}
+	return edges, nil
+}
+
+func validateEdges(edges []float64) error {
+	if len(edges) < 2 {
+		return invalid(ErrInvalidBins, "edges", "there must be at least two edges")
+	}
+	for i := 1; i < len(edges); i++ {
+		if !(edges[i] > edges[i-1]) {
+			return invalid(ErrInvalidRange, "edges", "edges must be strictly increasing")
+		}
+	}
+	return nil
+}
+
+// edgeIndex returns the bin of value for the given strictly increasing edges.
+// Bins include their lower edge, the last bin also its upper edge.
+// Values outside the edges give -1.
+func edgeIndex(edges []float64, value float64) int {
+	last := len(edges) - 1
+	if !(value >= edges[0] && value <= edges[last]) {
+		return -1
+	}
+	if value == edges[last] {
+		return last - 1
+	}
+	// The first edge greater than value closes the bin of value.
+	return sort.Search(len(edges), func(i int) bool { return edges[i] > value }) - 1
+}
+
+// CalculateIndicesQuantile bins data into bins equiprobable bins with edges
+// from QuantileEdges and returns the bin indices together with the edges.
+// Repeated values merge edges, so with fewer distinct values than bins, or
+// heavy ties, there are len(edges)-1 < bins bins.
+func CalculateIndicesQuantile(bins int, data []float64) ([]int, []float64, error) {
+	edges, err := QuantileEdges(bins, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	indices := make([]int, len(data))
+	for i, value := range data {
+		indices[i] = edgeIndex(edges, value)
+	}
+	return indices, edges, nil
+}
+
+// NewHistogram2DEdges creates a histogram with the given strictly increasing
+// bin edges, e.g. from QuantileEdges, instead of equally wide bins. MinX,
+// MaxX, MinY and MaxY are set to the outermost edges.
+func NewHistogram2DEdges(edgesX, edgesY []float64) (*Histogram2D, error) {
+	if err := validateEdges(edgesX); err != nil {
+		return nil, err
+	}
+	if err := validateEdges(edgesY); err != nil {
+		return nil, err
+	}
+	hist := NewHistogram2D(len(edgesX)-1, len(edgesY)-1, edgesX[0], edgesX[len(edgesX)-1], edgesY[0], edgesY[len(edgesY)-1])
+	hist.EdgesX = append([]float64(nil), edgesX...)
+	hist.EdgesY = append([]float64(nil), edgesY...)
+	return hist, nil
+}
+
+// MutualInformationWithEdges calculates the mutual information of dataX and
+// dataY binned by the given strictly increasing edges. Pairs outside the
+// edges are ignored.
+func MutualInformationWithEdges(edgesX, edgesY []float64, dataX, dataY []float64) (float64, error) {
+	if err := validateEdges(edgesX); err != nil {
+		return 0, err
+	}
+	if err := validateEdges(edgesY); err != nil {
+		return 0, err
+	}
+	if len(dataX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	return edgesMutualInformation(edgesX, edgesY, dataX, dataY)
+}
+
+func edgesMutualInformation(edgesX, edgesY []float64, dataX, dataY []float64) (float64, error) {
+	table := make([][]float64, len(edgesX)-1)
+	for i := range table {
+		table[i] = make([]float64, len(edgesY)-1)
+	}
+	for i := range dataX {
+		ix := edgeIndex(edgesX, dataX[i])
+		iy := edgeIndex(edgesY, dataY[i])
+		if ix >= 0 && iy >= 0 {
+			table[ix][iy]++
+		}
+	}
+	return MutualInformationFromJoint(table)
+}
+
+// WindowedMutualInformationWithEdges calculates the mutual information of
+// consecutive windows of windowSize pairs, starting every stride samples,
+// all binned by the same edges. Computing the edges once with QuantileEdges
+// on the whole series keeps the bins comparable across windows and avoids
+// re-sorting every window.
+func WindowedMutualInformationWithEdges(edgesX, edgesY []float64, dataX, dataY []float64, windowSize, stride int) ([]float64, error) {
+	if err := validateEdges(edgesX); err != nil {
+		return nil, err
+	}
+	if err := validateEdges(edgesY); err != nil {
+		return nil, err
+	}
+	if len(dataX) != len(dataY) {
+		return nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if windowSize < 1 || windowSize > len(dataX) {
+		return nil, invalid(ErrInvalidParameter, "windowSize", "windowSize must be between 1 and the data size")
+	}
+	if stride < 1 {
+		return nil, invalid(ErrInvalidParameter, "stride", "stride must be greater or equal 1")
+	}
+
+	var mi []float64
+	for start := 0; start+windowSize <= len(dataX); start += stride {
+		value, err := edgesMutualInformation(edgesX, edgesY, dataX[start:start+windowSize], dataY[start:start+windowSize])
+		if err != nil {
+			return nil, err
+		}
+		mi = append(mi, value)
+	}
+	return mi, nil
+}
@@ -0,0 +1,144 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestQuantileEdges(t *testing.T) {
+	edges, err := QuantileEdges(4, []float64{8, 1, 2, 3, 4, 5, 6, 7, 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1, 3, 5, 7, 9}
+	if len(edges) != len(want) {
+		t.Fatalf("edges = %v, want %v", edges, want)
+	}
+	for i := range want {
+		if edges[i] != want[i] {
+			t.Errorf("edges = %v, want %v", edges, want)
+			break
+		}
+	}
+	// Repeated values merge edges.
+	edges, err = QuantileEdges(4, []float64{0, 0, 0, 0, 0, 0, 1})
+	if err != nil || len(edges) != 2 {
+		t.Errorf("expected merged edges, got %v, %v", edges, err)
+	}
+	if _, err := QuantileEdges(4, []float64{2, 2}); err == nil {
+		t.Error("expected error for constant data")
+	}
+}
+
+func TestEdgeIndex(t *testing.T) {
+	edges := []float64{0, 1, 3}
+	cases := []struct {
+		value float64
+		want  int
+	}{{-0.1, -1}, {0, 0}, {0.5, 0}, {1, 1}, {3, 1}, {3.1, -1}}
+	for _, c := range cases {
+		if got := edgeIndex(edges, c.value); got != c.want {
+			t.Errorf("edgeIndex(%v) = %d, want %d", c.value, got, c.want)
+		}
+	}
+}
+
+func TestWindowedMutualInformationWithEdges(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	n := 1000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = rng.ExpFloat64()
+		dataY[i] = dataX[i]
+		if i >= n/2 {
+			dataY[i] = rng.ExpFloat64()
+		}
+	}
+	edgesX, _ := QuantileEdges(4, dataX)
+	edgesY, _ := QuantileEdges(4, dataY)
+	mi, err := WindowedMutualInformationWithEdges(edgesX, edgesY, dataX, dataY, 250, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mi) != 4 || mi[0] < 1 || mi[3] > 0.2 {
+		t.Errorf("expected coupled then independent windows, got %v", mi)
+	}
+	whole, _ := MutualInformationWithEdges(edgesX, edgesY, dataX[:250], dataY[:250])
+	if whole != mi[0] {
+		t.Errorf("first window %v differs from direct computation %v", mi[0], whole)
+	}
+	if _, err := WindowedMutualInformationWithEdges([]float64{1, 0}, edgesY, dataX, dataY, 250, 250); err == nil {
+		t.Error("expected error for decreasing edges")
+	}
+}
+
+func TestCalculateIndicesQuantile(t *testing.T) {
+	// Mostly small values with rare large spikes.
+	rng := rand.New(rand.NewSource(3))
+	data := make([]float64, 1000)
+	for i := range data {
+		data[i] = rng.ExpFloat64()
+		if i%100 == 0 {
+			data[i] *= 1000
+		}
+	}
+	indices, edges, err := CalculateIndicesQuantile(4, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 5 {
+		t.Fatalf("got %d edges, want 5", len(edges))
+	}
+	counts := make([]int, 4)
+	for _, index := range indices {
+		counts[index]++
+	}
+	for bin, c := range counts {
+		if c < 240 || c > 260 {
+			t.Errorf("bin %d holds %d samples, want about 250", bin, c)
+		}
+	}
+
+	// Fewer distinct values than bins merge the duplicate edges.
+	_, edges, err = CalculateIndicesQuantile(8, []float64{1, 1, 1, 2, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) >= 9 {
+		t.Errorf("edges %v were not merged", edges)
+	}
+	if err := validateEdges(edges); err != nil {
+		t.Errorf("edges %v: %v", edges, err)
+	}
+}
+
+func TestNewHistogram2DEdges(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	dataX := make([]float64, 500)
+	dataY := make([]float64, 500)
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+		dataY[i] = dataX[i]*dataX[i] + 0.1*rng.NormFloat64()
+	}
+	edgesX, _ := QuantileEdges(5, dataX)
+	edgesY, _ := QuantileEdges(5, dataY)
+	hist, err := NewHistogram2DEdges(edgesX, edgesY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range dataX {
+		hist.Increment(dataX[i], dataY[i])
+	}
+	want, err := MutualInformationWithEdges(edgesX, edgesY, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hist.CalculateMutualInformation(); !almostEqual(got, want, 1e-9) {
+		t.Errorf("histogram MI %v, want %v", got, want)
+	}
+
+	if _, err := NewHistogram2DEdges([]float64{0, 1, 1}, edgesY); err == nil {
+		t.Error("expected error for a zero-width bin")
+	}
+}
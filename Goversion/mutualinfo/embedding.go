@@ -0,0 +1,117 @@
+package mutualinfo
+
+import (
+	"errors"
+	"math"
+)
+
+// Embed returns the delay embedding of data with dimension dim and delay
+// tau, the vectors (x(t), x(t+tau), ..., x(t+(dim-1)tau)) for every t with
+// all components in data. Element d holds component d of all vectors, the
+// layout MutualInformationND and TotalCorrelation take. The components
+// share the memory of data.
+func Embed(data []float64, dim, tau int) ([][]float64, error) {
+	if dim < 1 {
+		return nil, invalid(ErrInvalidParameter, "dim", "dim must be greater or equal 1")
+	}
+	if tau < 1 {
+		return nil, invalid(ErrInvalidParameter, "tau", "tau must be greater or equal 1")
+	}
+	n := len(data) - (dim-1)*tau
+	if n < 1 {
+		return nil, invalid(ErrTooFewSamples, "data", "data is too short for the embedding")
+	}
+	embedded := make([][]float64, dim)
+	for d := range embedded {
+		embedded[d] = data[d*tau : d*tau+n]
+	}
+	return embedded, nil
+}
+
+// EmbeddingDelay proposes the delay of an embedding of data as the first
+// minimum of its auto mutual information up to maxLag, see
+// FirstAutoMIMinimum, with bins bins spanning the range of data.
+func EmbeddingDelay(data []float64, maxLag, bins int) (int, error) {
+	min, max, err := DataRange(data)
+	if err != nil {
+		return 0, err
+	}
+	lag, _, err := FirstAutoMIMinimum(data, maxLag, bins, min, max)
+	return lag, err
+}
+
+// FalseNearestNeighbors returns the fraction of false nearest neighbors of
+// Kennel et al. for the embedding dimensions 1..maxDim with delay tau,
+// element d-1 holding dimension d. The nearest neighbor of a vector is
+// false if adding the next component moves it away by more than ratio, e.g.
+// 15, times their distance, i.e. if the neighborhood was only due to the
+// projection onto too few dimensions. Every dimension uses the vectors that
+// still have a next component. Neighbors are found by comparing all pairs,
+// which takes O(n²) time per dimension.
+func FalseNearestNeighbors(data []float64, maxDim, tau int, ratio float64) ([]float64, error) {
+	if maxDim < 1 {
+		return nil, invalid(ErrInvalidParameter, "maxDim", "maxDim must be greater or equal 1")
+	}
+	if tau < 1 {
+		return nil, invalid(ErrInvalidParameter, "tau", "tau must be greater or equal 1")
+	}
+	if !(ratio > 0) {
+		return nil, invalid(ErrInvalidParameter, "ratio", "ratio must be positive")
+	}
+	if len(data)-maxDim*tau < 2 {
+		return nil, invalid(ErrTooFewSamples, "data", "data is too short for maxDim")
+	}
+	if err := CheckFinite("data", data); err != nil {
+		return nil, err
+	}
+
+	fractions := make([]float64, maxDim)
+	for dim := 1; dim <= maxDim; dim++ {
+		n := len(data) - dim*tau
+		falseCount, counted := 0, 0
+		for i := 0; i < n; i++ {
+			nearest, best := -1, math.Inf(1)
+			for j := 0; j < n; j++ {
+				if j == i {
+					continue
+				}
+				var sq float64
+				for d := 0; d < dim && sq < best; d++ {
+					diff := data[i+d*tau] - data[j+d*tau]
+					sq += diff * diff
+				}
+				if sq < best {
+					nearest, best = j, sq
+				}
+			}
+			// Duplicate vectors have no distance to compare against.
+			if nearest < 0 || best == 0 {
+				continue
+			}
+			counted++
+			if math.Abs(data[i+dim*tau]-data[nearest+dim*tau]) > ratio*math.Sqrt(best) {
+				falseCount++
+			}
+		}
+		if counted > 0 {
+			fractions[dim-1] = float64(falseCount) / float64(counted)
+		}
+	}
+	return fractions, nil
+}
+
+// EmbeddingDimension returns the smallest dimension up to maxDim whose
+// fraction of false nearest neighbors, see FalseNearestNeighbors, is below
+// threshold, e.g. 0.01. An error is returned if no dimension reaches it.
+func EmbeddingDimension(data []float64, maxDim, tau int, ratio, threshold float64) (int, error) {
+	fractions, err := FalseNearestNeighbors(data, maxDim, tau, ratio)
+	if err != nil {
+		return 0, err
+	}
+	for d, f := range fractions {
+		if f < threshold {
+			return d + 1, nil
+		}
+	}
+	return 0, errors.New("no dimension up to maxDim has few enough false nearest neighbors")
+}
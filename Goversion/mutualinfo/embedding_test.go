@@ -0,0 +1,76 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEmbed(t *testing.T) {
+	embedded, err := Embed([]float64{0, 1, 2, 3, 4, 5, 6}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{0, 1, 2}, {2, 3, 4}, {4, 5, 6}}
+	for d := range want {
+		for i := range want[d] {
+			if embedded[d][i] != want[d][i] {
+				t.Fatalf("got %v, want %v", embedded, want)
+			}
+		}
+	}
+	if _, err := Embed([]float64{0, 1, 2}, 3, 2); err == nil {
+		t.Error("expected error for data shorter than the embedding")
+	}
+	if _, err := Embed([]float64{0, 1, 2}, 1, 0); err == nil {
+		t.Error("expected error for tau 0")
+	}
+}
+
+// henon returns the x series of the Hénon map, which needs two dimensions.
+func henon(n int) []float64 {
+	data := make([]float64, n)
+	x, y := 0.1, 0.1
+	for i := -100; i < n; i++ {
+		x, y = 1-1.4*x*x+y, 0.3*x
+		if i >= 0 {
+			data[i] = x
+		}
+	}
+	return data
+}
+
+func TestEmbeddingDimension(t *testing.T) {
+	data := henon(1000)
+	fractions, err := FalseNearestNeighbors(data, 3, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fractions[0] < 0.5 || fractions[1] > 0.01 || fractions[2] > 0.01 {
+		t.Errorf("false nearest neighbors %v, want many in 1 and none from 2 dimensions", fractions)
+	}
+	dim, err := EmbeddingDimension(data, 3, 1, 10, 0.01)
+	if err != nil || dim != 2 {
+		t.Errorf("dimension %d (%v), want 2", dim, err)
+	}
+	if _, err := EmbeddingDimension(data, 1, 1, 10, 0.01); err == nil {
+		t.Error("expected error when no dimension reaches the threshold")
+	}
+}
+
+func TestEmbeddingDelay(t *testing.T) {
+	// The auto MI of a noisy sine first drops to a minimum at about a
+	// quarter period.
+	rng := rand.New(rand.NewSource(49))
+	data := make([]float64, 4000)
+	for i := range data {
+		data[i] = math.Sin(2*math.Pi*float64(i)/40.3) + 0.1*rng.NormFloat64()
+	}
+	tau, err := EmbeddingDelay(data, 30, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tau < 9 || tau > 12 {
+		t.Errorf("delay %d, want about 10", tau)
+	}
+}
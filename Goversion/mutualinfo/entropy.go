@@ -0,0 +1,56 @@
+package mutualinfo
+
+// ConditionalEntropies returns H(Y|X) = H(X,Y) - H(X) and
+// H(X|Y) = H(X,Y) - H(Y) in bits.
+func (h *Histogram2D) ConditionalEntropies() (hYGivenX, hXGivenY float64) {
+	hx, hy, hxy := h.Entropies()
+	return hxy - hx, hxy - hy
+}
+
+// Entropy1D calculates the entropy H(X) in bits of data binned into bins
+// equally wide bins over [min, max]. Values outside the range are ignored.
+// Like the other entropies it accepts slices of any Number type.
+func Entropy1D[T Number](bins int, min, max T, data []T) (float64, error) {
+	indices, err := CalculateIndices1D(bins, min, max, data)
+	if err != nil {
+		return 0, err
+	}
+	counts := make([]float64, bins)
+	for _, index := range indices {
+		if index >= 0 {
+			counts[index]++
+		}
+	}
+	return entropyOf(counts), nil
+}
+
+// JointEntropy2D calculates the joint entropy H(X,Y) in bits of dataX and
+// dataY. Pairs outside the given ranges are ignored.
+func JointEntropy2D[T Number](binsX, binsY int, minX, maxX, minY, maxY T, dataX, dataY []T) (float64, error) {
+	hist, err := filledHistogram(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, err
+	}
+	_, _, hxy := hist.Entropies()
+	return hxy, nil
+}
+
+// ConditionalEntropy calculates H(Y|X) in bits, the uncertainty left about
+// dataY once dataX is known. Pairs outside the given ranges are ignored, so
+// H(X) refers to the X values of the remaining pairs.
+func ConditionalEntropy[T Number](binsX, binsY int, minX, maxX, minY, maxY T, dataX, dataY []T) (float64, error) {
+	hist, err := filledHistogram(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, err
+	}
+	hYGivenX, _ := hist.ConditionalEntropies()
+	return hYGivenX, nil
+}
+
+func filledHistogram[T Number](binsX, binsY int, minX, maxX, minY, maxY T, dataX, dataY []T) (*Histogram2D, error) {
+	if err := validate2D(binsX, binsY, float64(minX), float64(maxX), float64(minY), float64(maxY), nil, nil); err != nil {
+		return nil, err
+	}
+	hist := NewHistogram2D(binsX, binsY, float64(minX), float64(maxX), float64(minY), float64(maxY))
+	return hist, FillHistogram2D(hist, dataX, dataY)
+}
@@ -0,0 +1,38 @@
+package mutualinfo
+
+import "testing"
+
+func TestEntropyFunctions(t *testing.T) {
+	// X is uniform over four bins and Y = X mod 2.
+	dataX := []float64{0.5, 1.5, 2.5, 3.5, 0.5, 1.5, 2.5, 3.5, 7}
+	dataY := []float64{0.5, 1.5, 0.5, 1.5, 0.5, 1.5, 0.5, 1.5, 0.5}
+	if h, err := Entropy1D(4, 0, 4, dataX); err != nil || !almostEqual(h, 2, 1e-12) {
+		t.Errorf("H(X) = %v, %v, want 2", h, err)
+	}
+	if h, err := JointEntropy2D(4, 2, 0, 4, 0, 2, dataX, dataY); err != nil || !almostEqual(h, 2, 1e-12) {
+		t.Errorf("H(X,Y) = %v, %v, want 2", h, err)
+	}
+	if h, err := ConditionalEntropy(4, 2, 0, 4, 0, 2, dataX, dataY); err != nil || !almostEqual(h, 0, 1e-12) {
+		t.Errorf("H(Y|X) = %v, %v, want 0", h, err)
+	}
+	if h, err := ConditionalEntropy(2, 4, 0, 2, 0, 4, dataY[:8], dataX[:8]); err != nil || !almostEqual(h, 1, 1e-12) {
+		t.Errorf("H(X|Y) = %v, %v, want 1", h, err)
+	}
+
+	hist := NewHistogram2D(4, 2, 0, 4, 0, 2)
+	for i := range dataX {
+		hist.Increment(dataX[i], dataY[i])
+	}
+	hYGivenX, hXGivenY := hist.ConditionalEntropies()
+	hx, _, _ := hist.Entropies()
+	if !almostEqual(hYGivenX, 0, 1e-12) || !almostEqual(hXGivenY, 1, 1e-12) || !almostEqual(hx-hXGivenY, hist.CalculateMutualInformation(), 1e-12) {
+		t.Errorf("H(Y|X) = %v, H(X|Y) = %v", hYGivenX, hXGivenY)
+	}
+
+	if _, err := Entropy1D(0, 0, 1, dataX); err == nil {
+		t.Error("expected error for zero bins")
+	}
+	if _, err := JointEntropy2D(4, 2, 0, 4, 0, 2, dataX, dataY[1:]); err == nil {
+		t.Error("expected error for different sizes")
+	}
+}
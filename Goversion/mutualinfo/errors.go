@@ -0,0 +1,50 @@
+package mutualinfo
+
+import "errors"
+
+// The kinds of invalid input reported by a ValidationError, which callers
+// can test for with errors.Is, e.g. to map them to API responses.
+var (
+	// ErrInvalidBins reports a bin count or bin edges that leave no bin, or
+	// that exceed the supported number of cells.
+	ErrInvalidBins = errors.New("invalid bins")
+	// ErrInvalidRange reports a value range that is not finite, empty or too
+	// narrow to be binned.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrSizeMismatch reports inputs that must have the same size but do not.
+	ErrSizeMismatch = errors.New("size mismatch")
+	// ErrInvalidShift reports shift or lag bounds outside the data.
+	ErrInvalidShift = errors.New("invalid shift")
+	// ErrTooFewSamples reports data too short or empty for the estimate.
+	ErrTooFewSamples = errors.New("too few samples")
+	// ErrInvalidData reports values an estimator cannot handle, e.g. NaN,
+	// infinite values or negative counts.
+	ErrInvalidData = errors.New("invalid data")
+	// ErrInvalidOption reports an unknown enum value or options that cannot
+	// be combined.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrInvalidParameter reports any other parameter out of its domain.
+	ErrInvalidParameter = errors.New("invalid parameter")
+)
+
+// ValidationError is the error returned for invalid input. Param names the
+// offending parameter or option, Reason describes the problem and Kind is
+// one of the Err* sentinels, which errors.Is finds through Unwrap.
+type ValidationError struct {
+	Param  string
+	Reason string
+	Kind   error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Kind
+}
+
+// invalid returns a *ValidationError of the given kind.
+func invalid(kind error, param, reason string) error {
+	return &ValidationError{Param: param, Reason: reason, Kind: kind}
+}
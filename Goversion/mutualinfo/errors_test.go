@@ -0,0 +1,46 @@
+package mutualinfo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidationErrors(t *testing.T) {
+	data := []float64{0.1, 0.5, 0.9}
+	cases := []struct {
+		name  string
+		err   error
+		kind  error
+		param string
+	}{
+		{"bins", second(MutualInformation(0, 4, 0, 1, 0, 1, data, data)), ErrInvalidBins, "bins"},
+		{"size", second(MutualInformation(4, 4, 0, 1, 0, 1, data, data[1:])), ErrSizeMismatch, "dataX"},
+		{"range", second(MutualInformation(4, 4, 1, 0, 0, 1, data, data)), ErrInvalidRange, "minX"},
+		{"shift", second(ShiftedMutualInformation(-1, 1, 4, 4, 0, 1, 0, 1, data, data, 0)), ErrInvalidShift, "shiftStep"},
+		{"samples", second(KSGMutualInformation(data, data, 5)), ErrTooFewSamples, "data"},
+		{"option", second(ShiftedMutualInformationWithOptions(0, 0, 4, 4, 0, 1, 0, 1, data, data, 1, ShiftOptions{Unit: -1})), ErrInvalidOption, "Unit"},
+		{"data", CheckFinite("dataX", []float64{0, math.Inf(1)}), ErrInvalidData, "dataX"},
+		{"parameter", second(ShiftedMutualInformationWithOptions(0, 0, 4, 4, 0, 1, 0, 1, data, data, 1, ShiftOptions{Workers: -1})), ErrInvalidParameter, "workers"},
+	}
+	for _, c := range cases {
+		if !errors.Is(c.err, c.kind) {
+			t.Errorf("%s: got %v, want an error of kind %v", c.name, c.err, c.kind)
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(c.err, &verr) || verr.Param != c.param {
+			t.Errorf("%s: got %#v, want param %q", c.name, c.err, c.param)
+		}
+	}
+
+	// Wrapped errors keep their kind.
+	_, err := AutoRangeShiftedMutualInformation(0, 0, 4, 4, 0, []float64{math.NaN()}, data[:1], 1)
+	if !errors.Is(err, ErrTooFewSamples) {
+		t.Errorf("got %v, want an error of kind %v", err, ErrTooFewSamples)
+	}
+}
+
+func second[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,25 @@
+package mutualinfo_test
+
+import (
+	"fmt"
+
+	"github.com/zdszx/modern-mutual-information/Goversion/mutualinfo"
+)
+
+func ExampleShiftedMutualInformationResult() {
+	// dataY follows dataX with a delay of two samples.
+	dataX := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3}
+	dataY := make([]float64, len(dataX))
+	for j := range dataY {
+		if j >= 2 {
+			dataY[j] = dataX[j-2]
+		}
+	}
+	result, err := mutualinfo.ShiftedMutualInformationResult(-4, 4, 5, 5, 0, 10, 0, 10, dataX, dataY, 1, mutualinfo.ShiftOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("peak at shift", result.PeakShift)
+	// Output: peak at shift -2
+}
@@ -0,0 +1,77 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// MutualInformationBinGradient estimates d(MI)/d(bins) at the given bin count
+// by a central difference of the MI calculated with bins-delta and bins+delta
+// bins on both axes. A value near zero indicates that the estimate sits on a
+// stable plateau, a large magnitude that it is sensitive to the bin choice.
+func MutualInformationBinGradient(bins, delta int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (float64, error) {
+	if delta < 1 {
+		return 0, invalid(ErrInvalidParameter, "delta", "delta must be greater or equal 1")
+	}
+	if bins-delta < 1 {
+		return 0, invalid(ErrInvalidBins, "delta", "bins-delta must leave at least one bin")
+	}
+	lower, err := MutualInformation(bins-delta, bins-delta, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, err
+	}
+	upper, err := MutualInformation(bins+delta, bins+delta, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, err
+	}
+	return (upper - lower) / float64(2*delta), nil
+}
+
+// FractionalShiftMutualInformation calculates the mutual information for a
+// possibly non-integer shift, following the convention of
+// ShiftedMutualInformation that dataX[j+shift] is paired with dataY[j].
+// dataY is linearly interpolated at the positions j-shift so that every
+// sample of dataX whose partner lies within dataY is used.
+func FractionalShiftMutualInformation(shift float64, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return 0, err
+	}
+	if math.IsNaN(shift) || math.Abs(shift) >= float64(len(dataX)-1) {
+		return 0, invalid(ErrInvalidShift, "shift", "shift must be smaller than the data size")
+	}
+
+	hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	last := float64(len(dataY) - 1)
+	for j, x := range dataX {
+		pos := float64(j) - shift
+		if pos < 0 || pos > last {
+			continue
+		}
+		k := int(pos)
+		y := dataY[k]
+		if frac := pos - float64(k); frac > 0 {
+			y += frac * (dataY[k+1] - dataY[k])
+		}
+		if x < minX || x > maxX || y < minY || y > maxY {
+			continue
+		}
+		hist.Increment(x, y)
+	}
+	return hist.CalculateMutualInformation(), nil
+}
+
+// FractionalShiftGradient estimates d(MI)/d(shift) at a fractional shift by a
+// central difference with step h, for gradient-based sub-sample alignment.
+func FractionalShiftGradient(shift, h float64, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (float64, error) {
+	if !(h > 0) {
+		return 0, invalid(ErrInvalidParameter, "h", "h must be positive")
+	}
+	lower, err := FractionalShiftMutualInformation(shift-h, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, err
+	}
+	upper, err := FractionalShiftMutualInformation(shift+h, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, err
+	}
+	return (upper - lower) / (2 * h), nil
+}
@@ -0,0 +1,153 @@
+package mutualinfo
+
+import (
+	"sync"
+)
+
+// Histogram3D counts (x, y, z) triples in BinsX x BinsY x BinsZ equally
+// wide bins, for conditional mutual information.
+type Histogram3D struct {
+	BinsX int
+	BinsY int
+	BinsZ int
+	MinX  float64
+	MaxX  float64
+	MinY  float64
+	MaxY  float64
+	MinZ  float64
+	MaxZ  float64
+	Data  [][][]int
+	// OutOfRange counts the triples passed to Increment with a value outside
+	// the ranges, which are not counted in Data.
+	OutOfRange int
+	Mutex      sync.Mutex
+}
+
+func NewHistogram3D(binsX, binsY, binsZ int, minX, maxX, minY, maxY, minZ, maxZ float64) *Histogram3D {
+	data := make([][][]int, binsX)
+	for i := range data {
+		data[i] = make([][]int, binsY)
+		for j := range data[i] {
+			data[i][j] = make([]int, binsZ)
+		}
+	}
+	return &Histogram3D{
+		BinsX: binsX,
+		BinsY: binsY,
+		BinsZ: binsZ,
+		MinX:  minX,
+		MaxX:  maxX,
+		MinY:  minY,
+		MaxY:  maxY,
+		MinZ:  minZ,
+		MaxZ:  maxZ,
+		Data:  data,
+	}
+}
+
+// Increment counts the triple (x, y, z) with the same binning as
+// Histogram2D.Increment. Triples with a value outside the ranges are only
+// counted in OutOfRange.
+func (h *Histogram3D) Increment(x, y, z float64) {
+	indexX := binIndex(x, h.MinX, h.MaxX, h.BinsX)
+	indexY := binIndex(y, h.MinY, h.MaxY, h.BinsY)
+	indexZ := binIndex(z, h.MinZ, h.MaxZ, h.BinsZ)
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	if indexX < 0 || indexY < 0 || indexZ < 0 {
+		h.OutOfRange++
+		return
+	}
+	h.Data[indexX][indexY][indexZ]++
+}
+
+// CalculateConditionalMutualInformation returns
+// I(X;Y|Z) = H(X,Z) + H(Y,Z) - H(X,Y,Z) - H(Z) in bits. Empty bins are skipped.
+func (h *Histogram3D) CalculateConditionalMutualInformation() float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	xz := make([]float64, h.BinsX*h.BinsZ)
+	yz := make([]float64, h.BinsY*h.BinsZ)
+	xyz := make([]float64, 0, h.BinsX*h.BinsY*h.BinsZ)
+	z := make([]float64, h.BinsZ)
+	for i := 0; i < h.BinsX; i++ {
+		for j := 0; j < h.BinsY; j++ {
+			for k := 0; k < h.BinsZ; k++ {
+				c := float64(h.Data[i][j][k])
+				xz[i*h.BinsZ+k] += c
+				yz[j*h.BinsZ+k] += c
+				xyz = append(xyz, c)
+				z[k] += c
+			}
+		}
+	}
+	return entropyOf(xz) + entropyOf(yz) - entropyOf(xyz) - entropyOf(z)
+}
+
+// ConditionalMutualInformation calculates I(X;Y|Z) of dataX and dataY given
+// dataZ, with every variable binned into equally wide bins over its range.
+// Triples with a value outside the ranges are ignored.
+func ConditionalMutualInformation(binsX, binsY, binsZ int, minX, maxX, minY, maxY, minZ, maxZ float64, dataX, dataY, dataZ []float64) (float64, error) {
+	if err := validate3D(binsX, binsY, binsZ, minX, maxX, minY, maxY, minZ, maxZ, dataX, dataY, dataZ); err != nil {
+		return 0, err
+	}
+	hist := NewHistogram3D(binsX, binsY, binsZ, minX, maxX, minY, maxY, minZ, maxZ)
+	for i := range dataX {
+		hist.Increment(dataX[i], dataY[i], dataZ[i])
+	}
+	return hist.CalculateConditionalMutualInformation(), nil
+}
+
+// ShiftedConditionalMutualInformation calculates I(X;Y|Z) for every shift
+// from shiftFrom to shiftTo in steps of shiftStep. A shift pairs
+// dataX[j+shift] with dataY[j] as in ShiftedMutualInformation, and dataZ
+// stays aligned with dataY, so the confounder is taken at the time of Y.
+func ShiftedConditionalMutualInformation(shiftFrom, shiftTo, binsX, binsY, binsZ int, minX, maxX, minY, maxY, minZ, maxZ float64, dataX, dataY, dataZ []float64, shiftStep int) ([]float64, error) {
+	if err := validate3D(binsX, binsY, binsZ, minX, maxX, minY, maxY, minZ, maxZ, dataX, dataY, dataZ); err != nil {
+		return nil, err
+	}
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if abs(shiftFrom) >= len(dataX) || abs(shiftTo) >= len(dataX) {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shifts must be smaller than the data size")
+	}
+
+	n := len(dataX)
+	cmi := make([]float64, 0, (shiftTo-shiftFrom)/shiftStep+1)
+	for shift := shiftFrom; shift <= shiftTo; shift += shiftStep {
+		hist := NewHistogram3D(binsX, binsY, binsZ, minX, maxX, minY, maxY, minZ, maxZ)
+		for j := maxInt(0, -shift); j < minInt(n, n-shift); j++ {
+			hist.Increment(dataX[j+shift], dataY[j], dataZ[j])
+		}
+		cmi = append(cmi, hist.CalculateConditionalMutualInformation())
+	}
+	return cmi, nil
+}
+
+func validate3D(binsX, binsY, binsZ int, minX, maxX, minY, maxY, minZ, maxZ float64, dataX, dataY, dataZ []float64) error {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return err
+	}
+	if !isFinite(minZ) || !isFinite(maxZ) {
+		return invalid(ErrInvalidRange, "minZ", "minZ and maxZ must be finite")
+	}
+	if minZ >= maxZ {
+		return invalid(ErrInvalidRange, "minZ", "minZ has to be smaller than maxZ")
+	}
+	if binsZ < 1 {
+		return invalid(ErrInvalidBins, "binsZ", "there must be at least one binZ")
+	}
+	if !resolvable(minZ, maxZ, binsZ) {
+		return invalid(ErrInvalidRange, "minZ", "Z range is too narrow for its magnitude, subtract a common offset from dataZ")
+	}
+	if len(dataZ) != len(dataX) {
+		return invalid(ErrSizeMismatch, "dataX", "dataX, dataY and dataZ must have the same size")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestConditionalMutualInformation(t *testing.T) {
+	// X and Y are both copies of Z plus independent noise: they share
+	// information only through Z.
+	rng := rand.New(rand.NewSource(12))
+	n := 20000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	dataZ := make([]float64, n)
+	for i := range dataZ {
+		z := rng.Intn(4)
+		dataZ[i] = float64(z)
+		dataX[i] = float64((z + rng.Intn(2)) % 4)
+		dataY[i] = float64((z + rng.Intn(2)) % 4)
+	}
+	mi, err := MutualInformation(4, 4, 0, 4, 0, 4, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmi, err := ConditionalMutualInformation(4, 4, 4, 0, 4, 0, 4, 0, 4, dataX, dataY, dataZ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mi < 0.2 || cmi > 0.01 {
+		t.Errorf("I(X;Y) = %v, I(X;Y|Z) = %v, want clearly positive and about 0", mi, cmi)
+	}
+
+	// Y = X XOR Z is independent of X alone but determined by X given Z.
+	for i := range dataZ {
+		dataX[i] = float64(rng.Intn(2))
+		dataZ[i] = float64(rng.Intn(2))
+		dataY[i] = float64(int(dataX[i]) ^ int(dataZ[i]))
+	}
+	cmi, err = ConditionalMutualInformation(2, 2, 2, 0, 1, 0, 1, 0, 1, dataX, dataY, dataZ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(cmi, 1, 1e-2) {
+		t.Errorf("XOR: I(X;Y|Z) = %v, want about 1", cmi)
+	}
+
+	if _, err := ConditionalMutualInformation(2, 2, 2, 0, 1, 0, 1, 0, 1, dataX, dataY, dataZ[1:]); err == nil {
+		t.Error("expected error for a dataZ length mismatch")
+	}
+	if _, err := ConditionalMutualInformation(2, 2, 0, 0, 1, 0, 1, 0, 1, dataX, dataY, dataZ); err == nil {
+		t.Error("expected error for zero Z bins")
+	}
+}
+
+func TestShiftedConditionalMutualInformation(t *testing.T) {
+	// Y copies X of three samples earlier, shift -3 pairs them up.
+	rng := rand.New(rand.NewSource(28))
+	n := 5000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	dataZ := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = float64(rng.Intn(4))
+		dataZ[i] = float64(rng.Intn(2))
+	}
+	for j := 3; j < n; j++ {
+		dataY[j] = dataX[j-3]
+	}
+	cmi, err := ShiftedConditionalMutualInformation(-4, 0, 4, 4, 2, 0, 4, 0, 4, 0, 2, dataX, dataY, dataZ, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shift, _, _ := PeakShift(-4, 0, 1, cmi); shift != -3 || !almostEqual(cmi[1], 2, 1e-2) {
+		t.Errorf("peak at shift %d with curve %v, want shift -3 with about 2 bits", shift, cmi)
+	}
+	direct, _ := ConditionalMutualInformation(4, 4, 2, 0, 4, 0, 4, 0, 2, dataX[:n-3], dataY[3:], dataZ[3:])
+	if cmi[1] != direct {
+		t.Errorf("shift -3 gives %v, direct %v", cmi[1], direct)
+	}
+	if _, err := ShiftedConditionalMutualInformation(1, 0, 4, 4, 2, 0, 4, 0, 4, 0, 2, dataX, dataY, dataZ, 1); err == nil {
+		t.Error("expected error for shiftFrom > shiftTo")
+	}
+}
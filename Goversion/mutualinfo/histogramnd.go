@@ -0,0 +1,187 @@
+package mutualinfo
+
+import (
+	"math"
+	"sync"
+)
+
+// HistogramND counts points of len(Bins) dimensions, dimension d binned into
+// Bins[d] equally wide bins over [Min[d], Max[d]]. Only occupied cells are
+// stored, keyed by the bin indices combined into one number, so many
+// dimensions do not need memory for every cell.
+type HistogramND struct {
+	Bins   []int
+	Min    []float64
+	Max    []float64
+	Counts map[int]int
+	// OutOfRange counts the points with a value outside the ranges, which
+	// are not counted in Counts.
+	OutOfRange int
+	Mutex      sync.Mutex
+}
+
+// NewHistogramND creates an empty histogram. The product of the bins must
+// not exceed maxBlockSymbols.
+func NewHistogramND(bins []int, min, max []float64) (*HistogramND, error) {
+	if len(bins) == 0 {
+		return nil, invalid(ErrInvalidParameter, "bins", "there must be at least one dimension")
+	}
+	if len(min) != len(bins) || len(max) != len(bins) {
+		return nil, invalid(ErrSizeMismatch, "bins", "bins, min and max must have the same size")
+	}
+	cells := 1
+	for d := range bins {
+		if bins[d] < 1 {
+			return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin in every dimension")
+		}
+		if !isFinite(min[d]) || !isFinite(max[d]) {
+			return nil, invalid(ErrInvalidRange, "min", "min and max must be finite in every dimension")
+		}
+		if min[d] >= max[d] {
+			return nil, invalid(ErrInvalidRange, "min", "min has to be smaller than max in every dimension")
+		}
+		if !resolvable(min[d], max[d], bins[d]) {
+			return nil, invalid(ErrInvalidRange, "min", "range is too narrow for its magnitude, subtract a common offset from the data")
+		}
+		if cells > maxBlockSymbols/bins[d] {
+			return nil, invalid(ErrInvalidBins, "bins", "the product of the bins exceeds the supported number of cells")
+		}
+		cells *= bins[d]
+	}
+	return &HistogramND{
+		Bins:   append([]int(nil), bins...),
+		Min:    append([]float64(nil), min...),
+		Max:    append([]float64(nil), max...),
+		Counts: make(map[int]int),
+	}, nil
+}
+
+// Increment counts the point, which must have one value per dimension.
+// Points with a value outside the ranges are only counted in OutOfRange.
+func (h *HistogramND) Increment(point []float64) error {
+	if len(point) != len(h.Bins) {
+		return invalid(ErrSizeMismatch, "point", "point must have one value per dimension")
+	}
+	key := 0
+	for d, v := range point {
+		index := binIndex(v, h.Min[d], h.Max[d], h.Bins[d])
+		if index < 0 {
+			h.Mutex.Lock()
+			h.OutOfRange++
+			h.Mutex.Unlock()
+			return nil
+		}
+		key = key*h.Bins[d] + index
+	}
+	h.Mutex.Lock()
+	h.Counts[key]++
+	h.Mutex.Unlock()
+	return nil
+}
+
+// Entropy returns the entropy in bits of the marginal distribution of the
+// given dimensions, all dimensions if dims is empty.
+func (h *HistogramND) Entropy(dims ...int) float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	return h.entropy(dims)
+}
+
+func (h *HistogramND) entropy(dims []int) float64 {
+	if len(dims) == 0 {
+		return countsEntropy(h.Counts)
+	}
+	// strides[d] is the factor of the index of dimension d in a key.
+	strides := make([]int, len(h.Bins))
+	stride := 1
+	for d := len(h.Bins) - 1; d >= 0; d-- {
+		strides[d] = stride
+		stride *= h.Bins[d]
+	}
+	marginal := make(map[int]int)
+	for key, c := range h.Counts {
+		projected := 0
+		for _, d := range dims {
+			projected = projected*h.Bins[d] + key/strides[d]%h.Bins[d]
+		}
+		marginal[projected] += c
+	}
+	return countsEntropy(marginal)
+}
+
+// MutualInformationND calculates the mutual information in bits between the
+// vectors X and Y, X[d] being the series of dimension d of X and likewise for
+// Y, all of the same length. Dimension d of X is binned into binsX[d] bins
+// over [minX[d], maxX[d]] and likewise for Y. Samples with a value outside
+// the ranges are ignored. With many dimensions the joint cells quickly
+// outnumber the samples, and the estimate is then strongly biased upwards.
+func MutualInformationND(X, Y [][]float64, binsX, binsY []int, minX, maxX, minY, maxY []float64) (float64, error) {
+	if len(X) == 0 || len(Y) == 0 {
+		return 0, invalid(ErrInvalidParameter, "X", "X and Y must have at least one dimension")
+	}
+	if len(binsX) != len(X) || len(binsY) != len(Y) {
+		return 0, invalid(ErrSizeMismatch, "bins", "there must be one bin count per dimension")
+	}
+	if len(minX) != len(X) || len(maxX) != len(X) {
+		return 0, invalid(ErrSizeMismatch, "minX", "minX and maxX must have one bound per dimension of X")
+	}
+	if len(minY) != len(Y) || len(maxY) != len(Y) {
+		return 0, invalid(ErrSizeMismatch, "minY", "minY and maxY must have one bound per dimension of Y")
+	}
+	hist, err := fillHistogramND(append(append([][]float64(nil), X...), Y...),
+		append(append([]int(nil), binsX...), binsY...),
+		append(append([]float64(nil), minX...), minY...),
+		append(append([]float64(nil), maxX...), maxY...))
+	if err != nil {
+		return 0, err
+	}
+	dimsX := make([]int, len(X))
+	for d := range dimsX {
+		dimsX[d] = d
+	}
+	dimsY := make([]int, len(Y))
+	for d := range dimsY {
+		dimsY[d] = len(X) + d
+	}
+	return hist.Entropy(dimsX...) + hist.Entropy(dimsY...) - hist.Entropy(), nil
+}
+
+// TotalCorrelation calculates the total correlation or multi-information
+// sum of H(X_d) minus H(X_1, ..., X_n) in bits of the series data[d], the
+// information shared among all of them. For two series it is their mutual
+// information. Samples with a value outside the ranges are ignored.
+func TotalCorrelation(data [][]float64, bins []int, min, max []float64) (float64, error) {
+	hist, err := fillHistogramND(data, bins, min, max)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for d := range data {
+		sum += hist.Entropy(d)
+	}
+	return math.Max(sum-hist.Entropy(), 0), nil
+}
+
+func fillHistogramND(data [][]float64, bins []int, min, max []float64) (*HistogramND, error) {
+	if len(data) != len(bins) {
+		return nil, invalid(ErrSizeMismatch, "bins", "there must be one bin count per dimension")
+	}
+	hist, err := NewHistogramND(bins, min, max)
+	if err != nil {
+		return nil, err
+	}
+	n := len(data[0])
+	for _, series := range data {
+		if len(series) != n {
+			return nil, invalid(ErrSizeMismatch, "data", "all dimensions must have the same size")
+		}
+	}
+	point := make([]float64, len(data))
+	for i := 0; i < n; i++ {
+		for d := range data {
+			point[d] = data[d][i]
+		}
+		hist.Increment(point)
+	}
+	return hist, nil
+}
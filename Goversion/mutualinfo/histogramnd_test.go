@@ -0,0 +1,90 @@
+package mutualinfo
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestMutualInformationND(t *testing.T) {
+	// Y = x1 XOR x2 carries no information about either x alone but one
+	// bit about the vector (x1, x2).
+	rng := rand.New(rand.NewSource(37))
+	n := 5000
+	x1 := make([]float64, n)
+	x2 := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		a, b := rng.Intn(2), rng.Intn(2)
+		x1[i], x2[i], y[i] = float64(a), float64(b), float64(a^b)
+	}
+	bins := []int{2, 2}
+	lo, hi := []float64{0, 0}, []float64{1, 1}
+	mi, err := MutualInformationND([][]float64{x1, x2}, [][]float64{y}, bins, []int{2}, lo, hi, []float64{0}, []float64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(mi, 1, 1e-2) {
+		t.Errorf("I((x1, x2); y) = %v, want 1", mi)
+	}
+	single, _ := MutualInformationND([][]float64{x1}, [][]float64{y}, []int{2}, []int{2}, []float64{0}, []float64{1}, []float64{0}, []float64{1})
+	if want, _ := MutualInformation(2, 2, 0, 1, 0, 1, x1, y); !almostEqual(single, want, 1e-12) {
+		t.Errorf("one dimension each: %v, MutualInformation %v", single, want)
+	}
+
+	// The three variables are pairwise independent, but any two determine
+	// the third: H = 1+1+1 - 2.
+	tc, err := TotalCorrelation([][]float64{x1, x2, y}, []int{2, 2, 2}, []float64{0, 0, 0}, []float64{1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(tc, 1, 1e-2) {
+		t.Errorf("total correlation %v, want 1", tc)
+	}
+
+	if _, err := MutualInformationND([][]float64{x1}, [][]float64{y[1:]}, []int{2}, []int{2}, []float64{0}, []float64{1}, []float64{0}, []float64{1}); err == nil {
+		t.Error("expected error for different sizes")
+	}
+	// One range too many for X and one too few for Y must not shift the
+	// ranges of Y onto X.
+	X := [][]float64{x1, x2}
+	for _, ranges := range [][4][]float64{
+		{{0, 0, 0}, {1, 1, 1}, nil, nil},
+		{lo, hi[:1], {0}, {1}},
+		{lo, hi, {0}, {1, 1}},
+	} {
+		if _, err := MutualInformationND(X, [][]float64{y}, bins, []int{2}, ranges[0], ranges[1], ranges[2], ranges[3]); !errors.Is(err, ErrSizeMismatch) {
+			t.Errorf("ranges %v: got %v, want ErrSizeMismatch", ranges, err)
+		}
+	}
+	if _, err := NewHistogramND([]int{2, 0}, lo, hi); err == nil {
+		t.Error("expected error for zero bins")
+	}
+	if _, err := NewHistogramND([]int{1 << 30, 1 << 30}, lo, hi); err == nil {
+		t.Error("expected error for too many cells")
+	}
+}
+
+func TestHistogramNDEntropy(t *testing.T) {
+	hist, err := NewHistogramND([]int{4, 2}, []float64{0, 0}, []float64{4, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][]float64{{0.5, 0.5}, {1.5, 1.5}, {2.5, 0.5}, {3.5, 1.5}, {9, 0}} {
+		if err := hist.Increment(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h := hist.Entropy(0); !almostEqual(h, 2, 1e-12) {
+		t.Errorf("H(x0) = %v, want 2", h)
+	}
+	if h := hist.Entropy(1); !almostEqual(h, 1, 1e-12) {
+		t.Errorf("H(x1) = %v, want 1", h)
+	}
+	if h := hist.Entropy(); !almostEqual(h, 2, 1e-12) || hist.OutOfRange != 1 {
+		t.Errorf("H = %v with %d out of range, want 2 and 1", h, hist.OutOfRange)
+	}
+	if err := hist.Increment([]float64{1}); err == nil {
+		t.Error("expected error for a point of the wrong dimension")
+	}
+}
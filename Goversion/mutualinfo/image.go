@@ -0,0 +1,92 @@
+package mutualinfo
+
+// ImageMutualInformation calculates the mutual information between the pixel
+// intensities of two row-major images of size width×height, restricted to the
+// pixels where mask is true. A nil mask selects every pixel. The bins of
+// each image span the intensity range of its masked pixels.
+func ImageMutualInformation(imgA, imgB []float64, width, height int, mask []bool, bins int) (float64, error) {
+	if width < 1 || height < 1 {
+		return 0, invalid(ErrInvalidParameter, "width", "width and height must be greater or equal 1")
+	}
+	size := width * height
+	if len(imgA) != size || len(imgB) != size {
+		return 0, invalid(ErrSizeMismatch, "images", "images must have width*height pixels")
+	}
+	if mask != nil && len(mask) != size {
+		return 0, invalid(ErrSizeMismatch, "mask", "mask must have width*height pixels")
+	}
+	if bins < 1 {
+		return 0, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+
+	var dataA, dataB []float64
+	for i := 0; i < size; i++ {
+		if mask == nil || mask[i] {
+			dataA = append(dataA, imgA[i])
+			dataB = append(dataB, imgB[i])
+		}
+	}
+	if len(dataA) == 0 {
+		return 0, invalid(ErrTooFewSamples, "mask", "mask must select at least one pixel")
+	}
+	return rangedMutualInformation(bins, dataA, dataB)
+}
+
+// rangedMutualInformation calculates the mutual information of dataX and
+// dataY with bins spanning the range of each series. A constant series
+// carries no information and yields 0.
+func rangedMutualInformation(bins int, dataX, dataY []float64) (float64, error) {
+	minX, maxX := minMax(dataX)
+	minY, maxY := minMax(dataY)
+	if minX == maxX || minY == maxY {
+		return 0, nil
+	}
+	return MutualInformation(bins, bins, minX, maxX, minY, maxY, dataX, dataY)
+}
+
+// SpatialShiftMutualInformation calculates the mutual information of two
+// row-major fields of size width×height for every offset (dx, dy) with
+// |dx| <= maxDX and |dy| <= maxDY, using only the overlapping pixels.
+// Like the temporal shift, offset (dx, dy) pairs fieldA at (col+dx, row+dy)
+// with fieldB at (col, row). The result is indexed [dy+maxDY][dx+maxDX].
+// The bins of each field span its full range, so that all offsets are binned alike.
+func SpatialShiftMutualInformation(fieldA, fieldB []float64, width, height, maxDX, maxDY, bins int) ([][]float64, error) {
+	if width < 1 || height < 1 {
+		return nil, invalid(ErrInvalidParameter, "width", "width and height must be greater or equal 1")
+	}
+	if len(fieldA) != width*height || len(fieldB) != width*height {
+		return nil, invalid(ErrSizeMismatch, "fieldA", "fields must have width*height pixels")
+	}
+	if maxDX < 0 || maxDY < 0 || maxDX >= width || maxDY >= height {
+		return nil, invalid(ErrInvalidShift, "maxDX", "maximum offsets must be non-negative and smaller than the field size")
+	}
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	minA, maxA := minMax(fieldA)
+	minB, maxB := minMax(fieldB)
+
+	surface := make([][]float64, 2*maxDY+1)
+	for dy := -maxDY; dy <= maxDY; dy++ {
+		surface[dy+maxDY] = make([]float64, 2*maxDX+1)
+		if minA == maxA || minB == maxB {
+			continue
+		}
+		for dx := -maxDX; dx <= maxDX; dx++ {
+			hist := NewHistogram2D(bins, bins, minA, maxA, minB, maxB)
+			for row := 0; row < height; row++ {
+				if row+dy < 0 || row+dy >= height {
+					continue
+				}
+				for col := 0; col < width; col++ {
+					if col+dx < 0 || col+dx >= width {
+						continue
+					}
+					hist.Increment(fieldA[(row+dy)*width+col+dx], fieldB[row*width+col])
+				}
+			}
+			surface[dy+maxDY][dx+maxDX] = hist.CalculateMutualInformation()
+		}
+	}
+	return surface, nil
+}
@@ -0,0 +1,73 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestImageMutualInformation(t *testing.T) {
+	width, height := 8, 8
+	imgA := make([]float64, width*height)
+	imgB := make([]float64, width*height)
+	mask := make([]bool, width*height)
+	for i := range imgA {
+		imgA[i] = float64(i % 4)
+		imgB[i] = 10 - 2*float64(i%4)
+		// Only the left half of every row is of interest.
+		mask[i] = i%width < width/2
+	}
+	mi, err := ImageMutualInformation(imgA, imgB, width, height, mask, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(mi, 2, 1e-12) {
+		t.Errorf("expected 2 bits, got %v", mi)
+	}
+	// Pixels outside the mask must not affect the result.
+	for i := range imgB {
+		if !mask[i] {
+			imgB[i] = math.Mod(float64(i)*7, 5)
+		}
+	}
+	if masked, _ := ImageMutualInformation(imgA, imgB, width, height, mask, 4); masked != mi {
+		t.Errorf("pixels outside the mask changed the result: %v", masked)
+	}
+	if _, err := ImageMutualInformation(imgA, imgB[:10], width, height, mask, 4); err == nil {
+		t.Error("expected error for mismatched image size")
+	}
+	if _, err := ImageMutualInformation(imgA, imgB, width, height, mask[:10], 4); err == nil {
+		t.Error("expected error for mismatched mask size")
+	}
+}
+
+func TestSpatialShiftMutualInformation(t *testing.T) {
+	width, height := 24, 20
+	fieldA := make([]float64, width*height)
+	fieldB := make([]float64, width*height)
+	value := func(col, row int) float64 { return float64((col*7 + row*13 + col*row) % 8) }
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			fieldA[row*width+col] = value(col, row)
+			// fieldB holds fieldA displaced by (2, -1).
+			fieldB[row*width+col] = value(col+2, row-1)
+		}
+	}
+	surface, err := SpatialShiftMutualInformation(fieldA, fieldB, width, height, 3, 3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bestDX, bestDY := 0, 0
+	for dy := range surface {
+		for dx := range surface[dy] {
+			if surface[dy][dx] > surface[bestDY][bestDX] {
+				bestDX, bestDY = dx, dy
+			}
+		}
+	}
+	if bestDX-3 != 2 || bestDY-3 != -1 {
+		t.Errorf("expected peak at offset (2, -1), got (%d, %d)", bestDX-3, bestDY-3)
+	}
+	if _, err := SpatialShiftMutualInformation(fieldA, fieldB, width, height, width, 0, 8); err == nil {
+		t.Error("expected error for offset beyond the field width")
+	}
+}
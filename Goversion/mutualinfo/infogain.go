@@ -0,0 +1,95 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// entropyOf returns the Shannon entropy in bits of a distribution given by
+// non-negative weights that need not be normalized.
+func entropyOf(weights []float64) float64 {
+	var total neumaierSum
+	for _, w := range weights {
+		total.Add(w)
+	}
+	var h neumaierSum
+	for _, w := range weights {
+		if w != 0 {
+			p := w / total.Value()
+			h.Add(-p * math.Log2(p))
+		}
+	}
+	return h.Value()
+}
+
+// featureLabelTable bins feature into bins bins over [min, max] and
+// cross-tabulates it against the distinct labels. Samples with a feature
+// outside the range are skipped.
+func featureLabelTable(feature []float64, labels []int, bins int, min, max float64) ([][]float64, error) {
+	if min >= max {
+		return nil, invalid(ErrInvalidRange, "min", "min has to be smaller than max")
+	}
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	if len(feature) != len(labels) {
+		return nil, invalid(ErrSizeMismatch, "feature", "feature and labels must have the same size")
+	}
+	indices, err := CalculateIndices1D(bins, min, max, feature)
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make(map[int]int)
+	for i, label := range labels {
+		if _, ok := classes[label]; !ok && indices[i] >= 0 {
+			classes[label] = len(classes)
+		}
+	}
+	if len(classes) == 0 {
+		return nil, invalid(ErrTooFewSamples, "feature", "no sample lies within the feature range")
+	}
+	table := make([][]float64, bins)
+	for i := range table {
+		table[i] = make([]float64, len(classes))
+	}
+	for i, index := range indices {
+		if index >= 0 {
+			table[index][classes[labels[i]]]++
+		}
+	}
+	return table, nil
+}
+
+// InformationGain returns the information gain in bits of splitting labels
+// by the binned feature, which is the mutual information between both.
+func InformationGain(feature []float64, labels []int, bins int, min, max float64) (float64, error) {
+	table, err := featureLabelTable(feature, labels, bins, min, max)
+	if err != nil {
+		return 0, err
+	}
+	return MutualInformationFromJoint(table)
+}
+
+// GainRatio returns the C4.5 gain ratio, the information gain divided by the
+// entropy of the binned feature. It is 0 if the feature falls into a single bin.
+func GainRatio(feature []float64, labels []int, bins int, min, max float64) (float64, error) {
+	table, err := featureLabelTable(feature, labels, bins, min, max)
+	if err != nil {
+		return 0, err
+	}
+	gain, err := MutualInformationFromJoint(table)
+	if err != nil {
+		return 0, err
+	}
+	rows := make([]float64, len(table))
+	for i, row := range table {
+		for _, c := range row {
+			rows[i] += c
+		}
+	}
+	splitInfo := entropyOf(rows)
+	if splitInfo == 0 {
+		return 0, nil
+	}
+	return gain / splitInfo, nil
+}
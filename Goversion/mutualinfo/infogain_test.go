@@ -0,0 +1,26 @@
+package mutualinfo
+
+import "testing"
+
+func TestInformationGain(t *testing.T) {
+	feature := []float64{0.1, 0.2, 0.3, 0.6, 0.7, 0.8, 0.9, 0.4}
+	labels := []int{7, 7, 7, -1, -1, -1, -1, 7}
+	gain, err := InformationGain(feature, labels, 2, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(gain, 1, 1e-12) {
+		t.Errorf("perfect split should gain 1 bit, got %v", gain)
+	}
+	// Four equally filled bins have a split information of 2 bits.
+	ratio, err := GainRatio(feature, labels, 4, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(ratio, 0.5, 1e-12) {
+		t.Errorf("expected gain ratio 0.5, got %v", ratio)
+	}
+	if _, err := InformationGain(feature, labels[:3], 2, 0, 1); err == nil {
+		t.Error("expected error for differing sizes")
+	}
+}
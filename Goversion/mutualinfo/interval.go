@@ -0,0 +1,137 @@
+package mutualinfo
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// DeltaMethodInterval returns the plug-in MI in bits with an approximate
+// confidence interval mi ± z*sqrt(v/N), where
+// v = Σ p(x,y)*log2(p(x,y)/(p(x)p(y)))² - MI² is the asymptotic variance of
+// the delta method. z = 1.96 gives a 95% interval. The lower bound is
+// clamped to 0.
+//
+// The approximation degenerates when MI is close to zero, where v vanishes and
+// the estimate follows a chi-square rather than a normal distribution. Use a
+// permutation test there instead.
+func (h *Histogram2D) DeltaMethodInterval(z float64) (mi, lower, upper float64, err error) {
+	if z < 0 {
+		return 0, 0, 0, invalid(ErrInvalidParameter, "z", "z must not be negative")
+	}
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	rows, cols, total := h.marginalCounts()
+	if total == 0 {
+		return 0, 0, 0, invalid(ErrTooFewSamples, "histogram", "histogram is empty")
+	}
+	n := float64(total)
+	var second float64
+	for i := 0; i < h.BinsX; i++ {
+		for j := 0; j < h.BinsY; j++ {
+			if h.Data[i][j] == 0 {
+				continue
+			}
+			p := float64(h.Data[i][j]) / n
+			pmi := math.Log2(p / (float64(rows[i]) / n * float64(cols[j]) / n))
+			mi += p * pmi
+			second += p * pmi * pmi
+		}
+	}
+	variance := math.Max(second-mi*mi, 0)
+	halfWidth := z * math.Sqrt(variance/n)
+	return mi, math.Max(mi-halfWidth, 0), mi + halfWidth, nil
+}
+
+// ShiftResult is the MI of one shift with the entropies and number of
+// pairs in its bins, and a confidence interval and p-value where computed.
+// Lower, Upper and PValue are NaN otherwise.
+type ShiftResult struct {
+	Shift int
+	MI    float64
+	// Pairs is the number of pairs counted in the bins, after the shift,
+	// the ranges and missing values have removed pairs. A low MI of a
+	// shift with few pairs says little.
+	Pairs        int
+	HX, HY, HXY  float64
+	Lower, Upper float64
+	PValue       float64
+}
+
+// BootstrapShiftedMutualInformation runs ShiftedMutualInformation and adds a
+// percentile bootstrap interval with the given confidence, e.g. 0.95 for the
+// 2.5% and 97.5% quantiles, to every shift. Each of the resamples resamples
+// the pairs of every shift with replacement; the resamples are spread across
+// GOMAXPROCS goroutines and the results only depend on seed.
+//
+// Resampling duplicates pairs, which raises the plug-in MI, so the interval
+// tends to lie above the point estimate for sparse histograms.
+func BootstrapShiftedMutualInformation(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep, resamples int, confidence float64, seed int64) ([]ShiftResult, error) {
+	return BootstrapShiftedMutualInformationWithProgress(shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, resamples, confidence, seed, nil)
+}
+
+// BootstrapShiftedMutualInformationWithProgress is
+// BootstrapShiftedMutualInformation calling progress after every resample.
+func BootstrapShiftedMutualInformationWithProgress(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep, resamples int, confidence float64, seed int64, progress ProgressFunc) ([]ShiftResult, error) {
+	if resamples < 1 {
+		return nil, invalid(ErrInvalidParameter, "resamples", "there must be at least one resample")
+	}
+	if !(confidence > 0 && confidence < 1) {
+		return nil, invalid(ErrInvalidParameter, "confidence", "confidence must be in (0, 1)")
+	}
+	var result Result
+	mi, err := shiftedMutualInformation(context.Background(), shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, ShiftOptions{}, &result)
+	if err != nil {
+		return nil, err
+	}
+	result.MI = mi
+
+	n := len(dataX)
+	boot := make([][]float64, resamples)
+	tick := progressTicker(progress, resamples)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+			for b := range jobs {
+				rng := permutationRand(seed, b)
+				values := make([]float64, len(mi))
+				for s := range values {
+					shift := shiftFrom + s*shiftStep
+					from, to := maxInt(0, -shift), minInt(n, n-shift)
+					hist.reset(minX, maxX, minY, maxY)
+					for k := from; k < to; k++ {
+						j := from + rng.Intn(to-from)
+						hist.increment(dataX[j+shift], dataY[j])
+					}
+					values[s] = hist.CalculateMutualInformation()
+				}
+				boot[b] = values
+				tick()
+			}
+		}()
+	}
+	for b := 0; b < resamples; b++ {
+		jobs <- b
+	}
+	close(jobs)
+	wg.Wait()
+
+	results := result.ShiftResults()
+	values := make([]float64, resamples)
+	for s := range results {
+		for b := range boot {
+			values[b] = boot[b][s]
+		}
+		sorted := sortedCopy(values)
+		results[s].Lower = quantile(sorted, (1-confidence)/2)
+		results[s].Upper = quantile(sorted, (1+confidence)/2)
+	}
+	return results, nil
+}
@@ -0,0 +1,78 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestDeltaMethodInterval(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	hist := NewHistogram2D(4, 4, 0, 1, 0, 1)
+	for i := 0; i < 5000; i++ {
+		x := rng.Float64()
+		y := x
+		if rng.Float64() < 0.5 {
+			y = rng.Float64()
+		}
+		hist.Increment(x, y)
+	}
+	mi, lower, upper, err := hist.DeltaMethodInterval(1.96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(mi, hist.CalculateMutualInformation(), 1e-9) {
+		t.Errorf("interval MI %v differs from plug-in MI", mi)
+	}
+	if !(lower < mi && mi < upper) || upper-lower > 0.2 {
+		t.Errorf("unexpected interval [%v, %v] around %v", lower, upper, mi)
+	}
+	if _, _, _, err := NewHistogram2D(4, 4, 0, 1, 0, 1).DeltaMethodInterval(1.96); err == nil {
+		t.Error("expected error for empty histogram")
+	}
+}
+
+func TestBootstrapShiftedMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(34))
+	dataX := make([]float64, 2000)
+	dataY := make([]float64, 2000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+	}
+	for j := range dataY {
+		dataY[j] = rng.Float64()
+		if j >= 1 {
+			dataY[j] = 0.5*dataX[j-1] + 0.5*dataY[j]
+		}
+	}
+	results, err := BootstrapShiftedMutualInformation(-2, 2, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, 200, 0.95, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if !(r.Lower <= r.Upper) || r.Lower > r.MI+0.02 || r.Upper < r.MI {
+			t.Errorf("shift %d: interval [%v, %v] does not cover MI %v", r.Shift, r.Lower, r.Upper, r.MI)
+		}
+	}
+	if results[2].Pairs != 2000 || results[0].Pairs != 1998 || !math.IsNaN(results[2].PValue) {
+		t.Errorf("shift 0 has %d pairs and p-value %v, shift -2 %d pairs", results[2].Pairs, results[2].PValue, results[0].Pairs)
+	}
+	if results[1].Lower <= results[0].Upper || results[1].Lower <= results[3].Upper {
+		t.Errorf("coupled shift -1 interval [%v, %v] overlaps the others: %+v", results[1].Lower, results[1].Upper, results)
+	}
+
+	previous := runtime.GOMAXPROCS(1)
+	defer runtime.GOMAXPROCS(previous)
+	again, _ := BootstrapShiftedMutualInformation(-2, 2, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, 200, 0.95, 3)
+	for i := range again {
+		if again[i].MI != results[i].MI || again[i].Lower != results[i].Lower || again[i].Upper != results[i].Upper {
+			t.Errorf("shift %d depends on GOMAXPROCS: %+v vs %+v", again[i].Shift, again[i], results[i])
+		}
+	}
+	for _, confidence := range []float64{0, 1, 1.5} {
+		if _, err := BootstrapShiftedMutualInformation(-2, 2, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, 10, confidence, 3); err == nil {
+			t.Errorf("expected error for confidence %v", confidence)
+		}
+	}
+}
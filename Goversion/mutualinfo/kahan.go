@@ -0,0 +1,35 @@
+package mutualinfo
+
+import "math"
+
+// neumaierSum accumulates float64 values with Neumaier's variant of Kahan
+// compensated summation, so that long runs of tiny weights are not lost
+// against a large running total.
+type neumaierSum struct {
+	sum          float64
+	compensation float64
+}
+
+func (s *neumaierSum) Add(v float64) {
+	t := s.sum + v
+	if math.Abs(s.sum) >= math.Abs(v) {
+		s.compensation += (s.sum - t) + v
+	} else {
+		s.compensation += (v - t) + s.sum
+	}
+	s.sum = t
+}
+
+func (s *neumaierSum) Value() float64 {
+	return s.sum + s.compensation
+}
+
+// addCompensated adds v to *sum like neumaierSum, but keeps *sum the
+// compensated total and *residual the part of it *sum cannot represent, so
+// that *sum can be read directly.
+func addCompensated(sum, residual *float64, v float64) {
+	s := neumaierSum{sum: *sum, compensation: *residual}
+	s.Add(v)
+	*sum = s.Value()
+	*residual = s.compensation - (*sum - s.sum)
+}
@@ -0,0 +1,31 @@
+package mutualinfo
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestNeumaierSumTinyWeights(t *testing.T) {
+	const n = 10000000
+	const weight = 1e-9
+
+	var compensated neumaierSum
+	naive := 0.0
+	reference := new(big.Float).SetPrec(256).SetFloat64(1e6)
+	compensated.Add(1e6)
+	naive += 1e6
+	w := new(big.Float).SetPrec(256).SetFloat64(weight)
+	for i := 0; i < n; i++ {
+		compensated.Add(weight)
+		naive += weight
+	}
+	reference.Add(reference, new(big.Float).SetPrec(256).Mul(w, new(big.Float).SetInt64(n)))
+	want, _ := reference.Float64()
+
+	if got := compensated.Value(); got != want {
+		t.Errorf("compensated sum = %.17g, want %.17g", got, want)
+	}
+	if naive == want {
+		t.Errorf("expected plain summation to lose precision, got exact %.17g", naive)
+	}
+}
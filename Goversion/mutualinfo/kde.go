@@ -0,0 +1,195 @@
+package mutualinfo
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// BandwidthRule selects how the kernel bandwidths of the KDE estimator are chosen.
+type BandwidthRule int
+
+const (
+	// BandwidthSilverman uses Silverman's rule for two dimensions,
+	// (4/(4n))^(1/6) times the robust spread min(sd, IQR/1.34) of each axis.
+	BandwidthSilverman BandwidthRule = iota
+	// BandwidthScott uses Scott's rule for two dimensions, n^(-1/6) times
+	// the standard deviation of each axis.
+	BandwidthScott
+	// BandwidthManual uses KDEOptions.BandwidthX and BandwidthY.
+	BandwidthManual
+)
+
+func (r BandwidthRule) String() string {
+	switch r {
+	case BandwidthSilverman:
+		return "silverman"
+	case BandwidthScott:
+		return "scott"
+	case BandwidthManual:
+		return "manual"
+	}
+	return "unknown"
+}
+
+// KDEOptions holds the settings of the KDE estimator.
+type KDEOptions struct {
+	Bandwidth BandwidthRule
+	// BandwidthX and BandwidthY are the kernel standard deviations used
+	// with BandwidthManual.
+	BandwidthX, BandwidthY float64
+	// Unit selects the unit of the estimates, bits by default.
+	Unit Unit
+	// Preprocess transforms dataX and dataY first, see PreprocessCopula.
+	// The bandwidths then refer to the transformed data.
+	Preprocess Preprocess
+}
+
+// bandwidth returns the kernel bandwidth of one axis of n samples.
+func (o KDEOptions) bandwidth(data []float64, manual float64) float64 {
+	n := float64(len(data))
+	sd := math.Sqrt(variance(data))
+	switch o.Bandwidth {
+	case BandwidthScott:
+		return sd * math.Pow(n, -1.0/6)
+	case BandwidthManual:
+		return manual
+	}
+	sorted := sortedCopy(data)
+	spread := sd
+	if iqr := (quantile(sorted, 0.75) - quantile(sorted, 0.25)) / 1.34; iqr > 0 && iqr < spread {
+		spread = iqr
+	}
+	return spread * math.Pow(4/(4*n), 1.0/6)
+}
+
+// variance returns the population variance of data.
+func variance(data []float64) float64 {
+	var sum neumaierSum
+	for _, v := range data {
+		sum.Add(v)
+	}
+	mean := sum.Value() / float64(len(data))
+	var sq neumaierSum
+	for _, v := range data {
+		sq.Add((v - mean) * (v - mean))
+	}
+	return sq.Value() / float64(len(data))
+}
+
+// KDEMutualInformation estimates the mutual information in bits from
+// Gaussian kernel density estimates of the joint and marginal densities, as
+// the mean of log2 f(x,y)/(f(x)f(y)) over the samples. Each density at a
+// sample leaves that sample out, and the marginals use the same bandwidths
+// as the joint density so that their smoothing biases largely cancel. It
+// needs no bins but takes O(n²) time.
+func KDEMutualInformation(dataX, dataY []float64, opts KDEOptions) (float64, error) {
+	if len(dataX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if len(dataX) < 3 {
+		return 0, invalid(ErrTooFewSamples, "data", "there must be at least three samples")
+	}
+	if opts.Bandwidth < BandwidthSilverman || opts.Bandwidth > BandwidthManual {
+		return 0, invalid(ErrInvalidOption, "Bandwidth", "unknown bandwidth rule")
+	}
+	if !opts.Unit.valid() {
+		return 0, invalid(ErrInvalidOption, "Unit", "unknown unit")
+	}
+	if !opts.Preprocess.valid() {
+		return 0, invalid(ErrInvalidOption, "Preprocess", "unknown preprocessing")
+	}
+	dataX, dataY = opts.Preprocess.apply(dataX), opts.Preprocess.apply(dataY)
+	hx := opts.bandwidth(dataX, opts.BandwidthX)
+	hy := opts.bandwidth(dataY, opts.BandwidthY)
+	if !(hx > 0) || !(hy > 0) || math.IsInf(hx, 1) || math.IsInf(hy, 1) {
+		return 0, invalid(ErrInvalidData, "data", "bandwidths must be positive and finite, constant data has none")
+	}
+	return opts.Unit.FromBits(kdeMutualInformation(dataX, dataY, hx, hy)), nil
+}
+
+func kdeMutualInformation(dataX, dataY []float64, hx, hy float64) float64 {
+	n := len(dataX)
+	cx, cy := -0.5/(hx*hx), -0.5/(hy*hy)
+	// The kernel normalizations cancel in f(x,y)/(f(x)f(y)), leaving
+	// (n-1) sum(kx*ky) / (sum(kx) sum(ky)).
+	var total neumaierSum
+	used := 0
+	for i := 0; i < n; i++ {
+		var sx, sy, sxy float64
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			dx, dy := dataX[i]-dataX[j], dataY[i]-dataY[j]
+			kx, ky := math.Exp(cx*dx*dx), math.Exp(cy*dy*dy)
+			sx += kx
+			sy += ky
+			sxy += kx * ky
+		}
+		if sxy == 0 {
+			continue // isolated sample, density underflows
+		}
+		total.Add(math.Log2(float64(n-1) * sxy / (sx * sy)))
+		used++
+	}
+	if used == 0 {
+		return 0
+	}
+	return total.Value() / float64(used)
+}
+
+// ShiftedKDEMutualInformation is KDEMutualInformation for every shift from
+// shiftFrom to shiftTo in steps of shiftStep, with the pairs of a shift as in
+// ShiftedMutualInformation. The bandwidths are chosen once from the whole
+// series so that all shifts are smoothed alike.
+func ShiftedKDEMutualInformation(shiftFrom, shiftTo int, dataX, dataY []float64, shiftStep int, opts KDEOptions) ([]float64, error) {
+	if len(dataX) != len(dataY) {
+		return nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if len(dataX)-maxInt(abs(shiftFrom), abs(shiftTo)) < 3 {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "every shift must leave at least three pairs")
+	}
+	if opts.Bandwidth < BandwidthSilverman || opts.Bandwidth > BandwidthManual {
+		return nil, invalid(ErrInvalidOption, "Bandwidth", "unknown bandwidth rule")
+	}
+	if !opts.Unit.valid() {
+		return nil, invalid(ErrInvalidOption, "Unit", "unknown unit")
+	}
+	if !opts.Preprocess.valid() {
+		return nil, invalid(ErrInvalidOption, "Preprocess", "unknown preprocessing")
+	}
+	dataX, dataY = opts.Preprocess.apply(dataX), opts.Preprocess.apply(dataY)
+	hx := opts.bandwidth(dataX, opts.BandwidthX)
+	hy := opts.bandwidth(dataY, opts.BandwidthY)
+	if !(hx > 0) || !(hy > 0) || math.IsInf(hx, 1) || math.IsInf(hy, 1) {
+		return nil, invalid(ErrInvalidData, "data", "bandwidths must be positive and finite, constant data has none")
+	}
+
+	n := len(dataX)
+	mi := make([]float64, (shiftTo-shiftFrom)/shiftStep+1)
+	shifts := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shift := range shifts {
+				from, to := maxInt(0, -shift), minInt(n, n-shift)
+				mi[(shift-shiftFrom)/shiftStep] = opts.Unit.FromBits(kdeMutualInformation(dataX[from+shift:to+shift], dataY[from:to], hx, hy))
+			}
+		}()
+	}
+	for shift := shiftFrom; shift <= shiftTo; shift += shiftStep {
+		shifts <- shift
+	}
+	close(shifts)
+	wg.Wait()
+	return mi, nil
+}
@@ -0,0 +1,60 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKDEMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(24))
+	for _, rho := range []float64{0, 0.6, 0.9} {
+		dataX := make([]float64, 1500)
+		dataY := make([]float64, 1500)
+		for i := range dataX {
+			dataX[i] = rng.NormFloat64()
+			dataY[i] = rho*dataX[i] + math.Sqrt(1-rho*rho)*rng.NormFloat64()
+		}
+		want, _ := GaussianMutualInformation(rho)
+		for _, rule := range []BandwidthRule{BandwidthSilverman, BandwidthScott} {
+			got, err := KDEMutualInformation(dataX, dataY, KDEOptions{Bandwidth: rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !almostEqual(got, want, 0.1) {
+				t.Errorf("rho=%v, %v: estimate %v, analytic %v", rho, rule, got, want)
+			}
+		}
+	}
+
+	if _, err := KDEMutualInformation([]float64{1, 2, 3}, []float64{1, 2, 3}, KDEOptions{Bandwidth: BandwidthManual}); err == nil {
+		t.Error("expected error for manual bandwidths of zero")
+	}
+	if _, err := KDEMutualInformation([]float64{1, 1, 1}, []float64{1, 2, 3}, KDEOptions{}); err == nil {
+		t.Error("expected error for constant data")
+	}
+}
+
+func TestShiftedKDEMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	dataX := make([]float64, 400)
+	dataY := make([]float64, 400)
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+	}
+	for j := 3; j < len(dataY); j++ {
+		dataY[j] = dataX[j-3] + 0.3*rng.NormFloat64()
+	}
+	opts := KDEOptions{Bandwidth: BandwidthManual, BandwidthX: 0.3, BandwidthY: 0.3}
+	mi, err := ShiftedKDEMutualInformation(-5, 5, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shift, _, _ := PeakShift(-5, 5, 1, mi); shift != -3 {
+		t.Errorf("peak at shift %d, want -3 in %v", shift, mi)
+	}
+	direct, _ := KDEMutualInformation(dataX[:397], dataY[3:], opts)
+	if !almostEqual(mi[2], direct, 1e-12) {
+		t.Errorf("shift -3 gives %v, direct estimate %v", mi[2], direct)
+	}
+}
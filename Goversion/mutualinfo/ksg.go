@@ -0,0 +1,261 @@
+package mutualinfo
+
+import (
+	"math"
+	"sort"
+)
+
+// Metric selects the distance used in the joint space of the KSG estimator.
+type Metric int
+
+const (
+	// MaxNorm is the Chebyshev distance max(|dx|, |dy|) assumed by the
+	// canonical KSG estimator.
+	MaxNorm Metric = iota
+	// Euclidean is the distance sqrt(dx²+dy²). The marginal counts then no
+	// longer match the joint balls exactly, which changes the bias of the
+	// estimate; use it only to reproduce results that were obtained with it.
+	Euclidean
+)
+
+func (m Metric) distance(dx, dy float64) float64 {
+	dx, dy = math.Abs(dx), math.Abs(dy)
+	if m == Euclidean {
+		return math.Hypot(dx, dy)
+	}
+	return math.Max(dx, dy)
+}
+
+// KSGAlgorithm selects between the two estimators of Kraskov et al.
+type KSGAlgorithm int
+
+const (
+	// KSGAlgorithm1 counts the marginal neighbors strictly within the
+	// distance of the k-th joint neighbor. It has a slightly lower
+	// statistical error.
+	KSGAlgorithm1 KSGAlgorithm = iota
+	// KSGAlgorithm2 counts the marginal neighbors within the x and y
+	// extents of the k nearest joint neighbors separately. It has a
+	// slightly lower bias, particularly in higher dimensions.
+	KSGAlgorithm2
+)
+
+// KSGOptions holds optional settings of the KSG estimator.
+type KSGOptions struct {
+	Metric    Metric
+	Algorithm KSGAlgorithm
+	Unit      Unit
+	// Preprocess transforms dataX and dataY first, see PreprocessCopula.
+	Preprocess Preprocess
+}
+
+// digamma returns ψ(x) for x > 0.
+func digamma(x float64) float64 {
+	var result float64
+	for x < 10 {
+		result -= 1 / x
+		x++
+	}
+	inv := 1 / x
+	inv2 := inv * inv
+	return result + math.Log(x) - 0.5*inv - inv2*(1.0/12-inv2*(1.0/120-inv2*(1.0/252-inv2*(1.0/240-inv2/132))))
+}
+
+// KSGMutualInformation estimates the mutual information in bits with the
+// k-nearest-neighbor estimator of Kraskov, Stögbauer and Grassberger
+// (algorithm 1), which needs no binning. Neighbors are searched along the
+// data sorted by x, which is far faster than comparing all pairs unless most
+// points share nearly the same x.
+func KSGMutualInformation(dataX, dataY []float64, k int) (float64, error) {
+	return KSGMutualInformationWithOptions(dataX, dataY, k, KSGOptions{})
+}
+
+// KSGMutualInformationWithOptions is KSGMutualInformation with a configurable
+// metric, algorithm and unit.
+func KSGMutualInformationWithOptions(dataX, dataY []float64, k int, opts KSGOptions) (float64, error) {
+	if len(dataX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	if k < 1 {
+		return 0, invalid(ErrInvalidParameter, "k", "k must be greater or equal 1")
+	}
+	if opts.Algorithm != KSGAlgorithm1 && opts.Algorithm != KSGAlgorithm2 {
+		return 0, invalid(ErrInvalidOption, "Algorithm", "unknown KSG algorithm")
+	}
+	if !opts.Unit.valid() {
+		return 0, invalid(ErrInvalidOption, "Unit", "unknown unit")
+	}
+	if !opts.Preprocess.valid() {
+		return 0, invalid(ErrInvalidOption, "Preprocess", "unknown preprocessing")
+	}
+	dataX, dataY = opts.Preprocess.apply(dataX), opts.Preprocess.apply(dataY)
+	n := len(dataX)
+	if n < k+1 {
+		return 0, invalid(ErrTooFewSamples, "data", "there must be at least k+1 samples")
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return dataX[order[a]] < dataX[order[b]] })
+	position := make([]int, n)
+	for p, i := range order {
+		position[i] = p
+	}
+	sortedX := sortedCopy(dataX)
+	sortedY := sortedCopy(dataY)
+
+	nearest := make([]float64, k)
+	neighbors := make([]int, k)
+	var sum float64
+	for i := 0; i < n; i++ {
+		// Walk outwards in x order, keeping the k smallest joint distances in
+		// ascending order. Both metrics are at least |dx|, so a side is
+		// done once |dx| reaches the current k-th distance.
+		for m := range nearest {
+			nearest[m] = math.Inf(1)
+		}
+		left, right := position[i]-1, position[i]+1
+		for {
+			dxLeft, dxRight := math.Inf(1), math.Inf(1)
+			if left >= 0 {
+				dxLeft = dataX[i] - dataX[order[left]]
+			}
+			if right < n {
+				dxRight = dataX[order[right]] - dataX[i]
+			}
+			var j int
+			if dxLeft <= dxRight {
+				if !(dxLeft < nearest[k-1]) {
+					break
+				}
+				j = order[left]
+				left--
+			} else {
+				if !(dxRight < nearest[k-1]) {
+					break
+				}
+				j = order[right]
+				right++
+			}
+			d := opts.Metric.distance(dataX[i]-dataX[j], dataY[i]-dataY[j])
+			if d >= nearest[k-1] {
+				continue
+			}
+			m := k - 1
+			for m > 0 && nearest[m-1] > d {
+				nearest[m] = nearest[m-1]
+				neighbors[m] = neighbors[m-1]
+				m--
+			}
+			nearest[m] = d
+			neighbors[m] = j
+		}
+
+		if opts.Algorithm == KSGAlgorithm2 {
+			var epsX, epsY float64
+			for _, j := range neighbors {
+				epsX = math.Max(epsX, math.Abs(dataX[i]-dataX[j]))
+				epsY = math.Max(epsY, math.Abs(dataY[i]-dataY[j]))
+			}
+			nx := countWithinOrAt(sortedX, dataX[i], epsX)
+			ny := countWithinOrAt(sortedY, dataY[i], epsY)
+			sum += digamma(float64(nx)) + digamma(float64(ny))
+			continue
+		}
+		eps := nearest[k-1]
+		nx := countWithin(sortedX, dataX[i], eps)
+		ny := countWithin(sortedY, dataY[i], eps)
+		sum += digamma(float64(nx+1)) + digamma(float64(ny+1))
+	}
+	mi := digamma(float64(k)) + digamma(float64(n)) - sum/float64(n)
+	if opts.Algorithm == KSGAlgorithm2 {
+		mi -= 1 / float64(k)
+	}
+	return opts.Unit.FromBits(mi / math.Ln2), nil
+}
+
+// countWithin returns the number of values v of sorted other than value
+// itself with |v - value| < eps, by binary search.
+func countWithin(sorted []float64, value, eps float64) int {
+	lo := sort.Search(len(sorted), func(j int) bool {
+		return !(sorted[j] < value && value-sorted[j] >= eps)
+	})
+	hi := sort.Search(len(sorted), func(j int) bool {
+		return sorted[j] >= value && sorted[j]-value >= eps
+	})
+	count := hi - lo
+	if eps > 0 {
+		count-- // value itself
+	}
+	return count
+}
+
+// countWithinOrAt returns the number of values v of sorted other than value
+// itself with |v - value| <= eps, by binary search.
+func countWithinOrAt(sorted []float64, value, eps float64) int {
+	lo := sort.Search(len(sorted), func(j int) bool {
+		return !(sorted[j] < value && value-sorted[j] > eps)
+	})
+	hi := sort.Search(len(sorted), func(j int) bool {
+		return sorted[j] > value && sorted[j]-value > eps
+	})
+	return hi - lo - 1
+}
+
+// KSGConditionalMutualInformation estimates I(X;Y|Z) in bits with the
+// k-nearest-neighbor estimator of Frenzel and Pompe, the conditional form of
+// KSG algorithm 1 with the max norm. It compares all pairs and so takes
+// O(n²) time.
+func KSGConditionalMutualInformation(dataX, dataY, dataZ []float64, k int) (float64, error) {
+	if len(dataX) != len(dataY) || len(dataX) != len(dataZ) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX, dataY and dataZ must have the same size")
+	}
+	if k < 1 {
+		return 0, invalid(ErrInvalidParameter, "k", "k must be greater or equal 1")
+	}
+	n := len(dataX)
+	if n < k+1 {
+		return 0, invalid(ErrTooFewSamples, "data", "there must be at least k+1 samples")
+	}
+
+	nearest := make([]float64, k)
+	var sum float64
+	for i := 0; i < n; i++ {
+		for m := range nearest {
+			nearest[m] = math.Inf(1)
+		}
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			d := math.Max(math.Abs(dataX[i]-dataX[j]), math.Max(math.Abs(dataY[i]-dataY[j]), math.Abs(dataZ[i]-dataZ[j])))
+			if d >= nearest[k-1] {
+				continue
+			}
+			m := k - 1
+			for m > 0 && nearest[m-1] > d {
+				nearest[m] = nearest[m-1]
+				m--
+			}
+			nearest[m] = d
+		}
+		eps := nearest[k-1]
+		nxz, nyz, nz := 0, 0, 0
+		for j := 0; j < n; j++ {
+			if j == i || !(math.Abs(dataZ[i]-dataZ[j]) < eps) {
+				continue
+			}
+			nz++
+			if math.Abs(dataX[i]-dataX[j]) < eps {
+				nxz++
+			}
+			if math.Abs(dataY[i]-dataY[j]) < eps {
+				nyz++
+			}
+		}
+		sum += digamma(float64(nxz+1)) + digamma(float64(nyz+1)) - digamma(float64(nz+1))
+	}
+	return (digamma(float64(k)) - sum/float64(n)) / math.Ln2, nil
+}
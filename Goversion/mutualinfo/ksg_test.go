@@ -0,0 +1,179 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestDigamma(t *testing.T) {
+	const eulerGamma = 0.5772156649015329
+	cases := []struct{ x, want float64 }{
+		{1, -eulerGamma},
+		{2, 1 - eulerGamma},
+		{0.5, -eulerGamma - 2*0.6931471805599453},
+		{10, 2.251752589066721},
+	}
+	for _, c := range cases {
+		if got := digamma(c.x); !almostEqual(got, c.want, 1e-12) {
+			t.Errorf("digamma(%v) = %v, want %v", c.x, got, c.want)
+		}
+	}
+}
+
+func TestKSGMetric(t *testing.T) {
+	rng := rand.New(rand.NewSource(18))
+	dataX := make([]float64, 500)
+	dataY := make([]float64, 500)
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+		dataY[i] = dataX[i] + rng.NormFloat64()
+	}
+	maxNorm, err := KSGMutualInformation(dataX, dataY, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	euclidean, err := KSGMutualInformationWithOptions(dataX, dataY, 4, KSGOptions{Metric: Euclidean})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := GaussianMutualInformation(1 / 1.4142135623730951)
+	if !almostEqual(maxNorm, want, 0.1) {
+		t.Errorf("max-norm estimate %v far from analytic %v", maxNorm, want)
+	}
+	if euclidean == maxNorm {
+		t.Error("expected the metric to change the estimate")
+	}
+}
+
+// ksgBruteForce is the O(n²) reference of KSG algorithm 1 with the max norm.
+func ksgBruteForce(dataX, dataY []float64, k int) float64 {
+	n := len(dataX)
+	var sum float64
+	for i := 0; i < n; i++ {
+		distances := make([]float64, 0, n-1)
+		for j := 0; j < n; j++ {
+			if j != i {
+				distances = append(distances, math.Max(math.Abs(dataX[i]-dataX[j]), math.Abs(dataY[i]-dataY[j])))
+			}
+		}
+		sort.Float64s(distances)
+		eps := distances[k-1]
+		nx, ny := 0, 0
+		for j := 0; j < n; j++ {
+			if j != i && math.Abs(dataX[i]-dataX[j]) < eps {
+				nx++
+			}
+			if j != i && math.Abs(dataY[i]-dataY[j]) < eps {
+				ny++
+			}
+		}
+		sum += digamma(float64(nx+1)) + digamma(float64(ny+1))
+	}
+	return (digamma(float64(k)) + digamma(float64(n)) - sum/float64(n)) / math.Ln2
+}
+
+func TestKSGMatchesBruteForce(t *testing.T) {
+	// Rounded values produce ties and duplicate points.
+	rng := rand.New(rand.NewSource(21))
+	dataX := make([]float64, 300)
+	dataY := make([]float64, 300)
+	for i := range dataX {
+		dataX[i] = math.Round(rng.NormFloat64() * 4)
+		dataY[i] = math.Round(dataX[i] + rng.NormFloat64()*4)
+	}
+	for _, k := range []int{1, 3, 6} {
+		got, err := KSGMutualInformation(dataX, dataY, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := ksgBruteForce(dataX, dataY, k); !almostEqual(got, want, 1e-9) {
+			t.Errorf("k=%d: got %v, brute force %v", k, got, want)
+		}
+	}
+}
+
+func TestKSGGaussian(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	for _, rho := range []float64{0, 0.5, 0.9} {
+		dataX := make([]float64, 2000)
+		dataY := make([]float64, 2000)
+		for i := range dataX {
+			dataX[i] = rng.NormFloat64()
+			dataY[i] = rho*dataX[i] + math.Sqrt(1-rho*rho)*rng.NormFloat64()
+		}
+		got, err := KSGMutualInformation(dataX, dataY, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := GaussianMutualInformation(rho)
+		if !almostEqual(got, want, 0.05) {
+			t.Errorf("rho=%v: estimate %v, analytic %v", rho, got, want)
+		}
+	}
+	if _, err := KSGMutualInformation([]float64{1, 2}, []float64{1, 2}, 2); err == nil {
+		t.Error("expected error for fewer than k+1 samples")
+	}
+}
+
+func TestKSGAlgorithm2(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+	for _, rho := range []float64{0, 0.6, 0.9} {
+		dataX := make([]float64, 2000)
+		dataY := make([]float64, 2000)
+		for i := range dataX {
+			dataX[i] = rng.NormFloat64()
+			dataY[i] = rho*dataX[i] + math.Sqrt(1-rho*rho)*rng.NormFloat64()
+		}
+		got, err := KSGMutualInformationWithOptions(dataX, dataY, 4, KSGOptions{Algorithm: KSGAlgorithm2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := GaussianMutualInformation(rho)
+		if !almostEqual(got, want, 0.05) {
+			t.Errorf("rho=%v: estimate %v, analytic %v", rho, got, want)
+		}
+	}
+	if _, err := KSGMutualInformationWithOptions([]float64{1, 2, 3}, []float64{1, 2, 3}, 1, KSGOptions{Algorithm: 5}); err == nil {
+		t.Error("expected error for an unknown algorithm")
+	}
+}
+
+func TestKSGConditionalMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(29))
+	n := 1500
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	dataZ := make([]float64, n)
+	for i := range dataZ {
+		dataZ[i] = rng.NormFloat64()
+		dataX[i] = dataZ[i] + 0.5*rng.NormFloat64()
+		dataY[i] = dataZ[i] + 0.5*rng.NormFloat64()
+	}
+	// X and Y only share information through Z.
+	cmi, err := KSGConditionalMutualInformation(dataX, dataY, dataZ, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(cmi, 0, 0.05) {
+		t.Errorf("I(X;Y|Z) = %v for a common cause, want about 0", cmi)
+	}
+
+	// With an independent Z the conditional MI is the plain MI.
+	for i := range dataZ {
+		dataZ[i] = rng.NormFloat64()
+		dataX[i] = rng.NormFloat64()
+		dataY[i] = 0.8*dataX[i] + 0.6*rng.NormFloat64()
+	}
+	cmi, err = KSGConditionalMutualInformation(dataX, dataY, dataZ, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := GaussianMutualInformation(0.8); !almostEqual(cmi, want, 0.1) {
+		t.Errorf("I(X;Y|Z) = %v for independent Z, want I(X;Y) = %v", cmi, want)
+	}
+	if _, err := KSGConditionalMutualInformation(dataX, dataY, dataZ[1:], 4); err == nil {
+		t.Error("expected error for a dataZ length mismatch")
+	}
+}
@@ -0,0 +1,73 @@
+package mutualinfo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// LoadColumns reads the columns colX and colY (0-based) of the CSV data in r
+// as float64, skipping the first record if hasHeader. A record missing one of
+// the columns is an error naming its line. A record with a non-numeric value
+// in either column is skipped if skipNonNumeric and an error otherwise, so
+// the returned slices always have the same size.
+func LoadColumns(r io.Reader, colX, colY int, hasHeader, skipNonNumeric bool) (dataX, dataY []float64, err error) {
+	return LoadDelimitedColumns(r, ',', colX, colY, hasHeader, skipNonNumeric)
+}
+
+// LoadDelimitedColumns is LoadColumns for fields separated by comma, e.g.
+// '\t' for TSV. Quoted fields follow the CSV rules in either case.
+func LoadDelimitedColumns(r io.Reader, comma rune, colX, colY int, hasHeader, skipNonNumeric bool) (dataX, dataY []float64, err error) {
+	if comma == '"' || comma == '\r' || comma == '\n' {
+		return nil, nil, invalid(ErrInvalidParameter, "comma", "invalid delimiter")
+	}
+	err = readColumns(r, comma, colX, colY, hasHeader, skipNonNumeric, func(x, y float64) {
+		dataX = append(dataX, x)
+		dataY = append(dataY, y)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataX, dataY, nil
+}
+
+// StreamColumns is LoadDelimitedColumns calling fn with every pair instead of
+// collecting them, so that files larger than memory can feed a StreamingMI,
+// an MIMonitor or a Histogram2D record by record.
+func StreamColumns(r io.Reader, comma rune, colX, colY int, hasHeader, skipNonNumeric bool, fn func(x, y float64)) error {
+	if comma == '"' || comma == '\r' || comma == '\n' {
+		return invalid(ErrInvalidParameter, "comma", "invalid delimiter")
+	}
+	return readColumns(r, comma, colX, colY, hasHeader, skipNonNumeric, fn)
+}
+
+// binaryPairSize is the size of a pair in the binary format, two IEEE 754
+// float64 values x and y.
+const binaryPairSize = 16
+
+// StreamBinaryPairs reads pairs of float64 values x, y in the byte order order
+// from r, e.g. a file written with binary.Write of interleaved samples, and
+// calls fn with every pair. Data ending within a pair is an error.
+func StreamBinaryPairs(r io.Reader, order binary.ByteOrder, fn func(x, y float64)) error {
+	reader := bufio.NewReaderSize(r, 1<<16)
+	var pair [binaryPairSize]byte
+	for {
+		_, err := io.ReadFull(reader, pair[:])
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return errors.New("data ends within a pair")
+		}
+		if err != nil {
+			return err
+		}
+		fn(decodePair(pair[:], order))
+	}
+}
+
+func decodePair(b []byte, order binary.ByteOrder) (x, y float64) {
+	return math.Float64frombits(order.Uint64(b[:8])), math.Float64frombits(order.Uint64(b[8:16]))
+}
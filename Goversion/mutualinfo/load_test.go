@@ -0,0 +1,80 @@
+package mutualinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestLoadColumns(t *testing.T) {
+	const input = "time,x,y\n0,1.5,2\n1,n/a,3\n2,2.5,-1e-3\n"
+	dataX, dataY, err := LoadColumns(strings.NewReader(input), 1, 2, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataX) != 2 || dataX[0] != 1.5 || dataX[1] != 2.5 || dataY[0] != 2 || dataY[1] != -1e-3 {
+		t.Errorf("got %v and %v", dataX, dataY)
+	}
+
+	if _, _, err := LoadColumns(strings.NewReader(input), 1, 2, true, false); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error on line 3 for the non-numeric cell, got %v", err)
+	}
+	if _, _, err := LoadColumns(strings.NewReader("1,2,3\n4,5\n"), 0, 2, false, true); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error on line 2 for the ragged row, got %v", err)
+	}
+}
+
+func TestLoadDelimitedColumns(t *testing.T) {
+	const input = "x\ty\n1\t\"2\"\n3\t4\n"
+	dataX, dataY, err := LoadDelimitedColumns(strings.NewReader(input), '\t', 0, 1, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataX) != 2 || dataX[1] != 3 || dataY[0] != 2 || dataY[1] != 4 {
+		t.Errorf("got %v and %v", dataX, dataY)
+	}
+	if _, _, err := LoadDelimitedColumns(strings.NewReader(input), '"', 0, 1, true, false); err == nil {
+		t.Error("expected error for a quote as delimiter")
+	}
+}
+
+func TestStreamBinaryPairs(t *testing.T) {
+	pairs := []float64{1, 2, -0.5, 3e10, 0, 4}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, pairs); err != nil {
+		t.Fatal(err)
+	}
+	var got []float64
+	err := StreamBinaryPairs(bytes.NewReader(buf.Bytes()), binary.BigEndian, func(x, y float64) {
+		got = append(got, x, y)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(pairs) {
+		t.Fatalf("got %v, want %v", got, pairs)
+	}
+	for i := range got {
+		if got[i] != pairs[i] {
+			t.Fatalf("got %v, want %v", got, pairs)
+		}
+	}
+	if err := StreamBinaryPairs(bytes.NewReader(buf.Bytes()[:20]), binary.BigEndian, func(x, y float64) {}); err == nil {
+		t.Error("expected error for data ending within a pair")
+	}
+}
+
+func TestStreamColumns(t *testing.T) {
+	stream, err := NewStreamingMI(4, 4, 0, 4, 0, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const input = "x;y\n0.5;0.5\n1.5;1.5\n2.5;2.5\n3.5;3.5\n"
+	if err := StreamColumns(strings.NewReader(input), ';', 0, 1, true, false, stream.AddPair); err != nil {
+		t.Fatal(err)
+	}
+	if stream.N() != 4 || !almostEqual(stream.MI(), 2, 1e-12) {
+		t.Errorf("got %d pairs and MI %v, want 4 and 2", stream.N(), stream.MI())
+	}
+}
@@ -0,0 +1,114 @@
+package mutualinfo
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// MatrixOptions holds optional settings for MutualInformationMatrix.
+type MatrixOptions struct {
+	// Normalization selects the normalized mutual information of every
+	// pair, see CalculateNormalizedMutualInformation.
+	Normalization Normalization
+	// Workers bounds the number of pairs computed concurrently. If zero,
+	// runtime.NumCPU() is used.
+	Workers int
+	// Progress, if set, is called after every pair, including the
+	// diagonal.
+	Progress ProgressFunc
+	// Preprocess transforms every column before binning, see
+	// PreprocessCopula.
+	Preprocess Preprocess
+}
+
+// MutualInformationMatrix calculates the mutual information of all pairs of
+// the columns data[c], e.g. to rank features against each other. Every
+// column is binned once into bins bins spanning its range, a constant column
+// falling into a single bin, and the pairs are spread across a pool of
+// opts.Workers goroutines. The matrix is symmetric and its diagonal holds the
+// MI of every column with itself, i.e. its entropy, normalized like the
+// other pairs. Rows with a NaN or infinite value are skipped per pair.
+func MutualInformationMatrix(data [][]float64, bins int, opts MatrixOptions) ([][]float64, error) {
+	if len(data) == 0 {
+		return nil, invalid(ErrTooFewSamples, "data", "there must be at least one column")
+	}
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	if opts.Normalization < NormalizationNone || opts.Normalization > NormalizationRedundancy {
+		return nil, invalid(ErrInvalidOption, "Normalization", "unknown normalization")
+	}
+	if opts.Workers < 0 {
+		return nil, invalid(ErrInvalidParameter, "workers", "workers must not be negative")
+	}
+	if !opts.Preprocess.valid() {
+		return nil, invalid(ErrInvalidOption, "Preprocess", "unknown preprocessing")
+	}
+	n := len(data[0])
+	indices := make([][]int32, len(data))
+	for c, column := range data {
+		if len(column) != n {
+			return nil, invalid(ErrSizeMismatch, "data", "all columns must have the same size")
+		}
+		column = opts.Preprocess.apply(column)
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, v := range column {
+			if isFinite(v) {
+				min, max = math.Min(min, v), math.Max(max, v)
+			}
+		}
+		if min > max {
+			return nil, invalid(ErrTooFewSamples, "data", fmt.Sprintf("column %d holds no finite value", c))
+		}
+		if min == max {
+			min, max = min-0.5, max+0.5
+		}
+		indices[c] = make([]int32, n)
+		for i, v := range column {
+			indices[c][i] = int32(binIndex(v, min, max, bins))
+		}
+	}
+
+	columns := len(data)
+	matrix := make([][]float64, columns)
+	for i := range matrix {
+		matrix[i] = make([]float64, columns)
+	}
+	type pair struct{ i, j int }
+	pairs := make(chan pair)
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	tick := progressTicker(opts.Progress, columns*(columns+1)/2)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hist := NewHistogram2D(bins, bins, 0, 1, 0, 1)
+			for p := range pairs {
+				hist.reset(0, 1, 0, 1)
+				for k := 0; k < n; k++ {
+					hist.addIndex(int(indices[p.i][k]), int(indices[p.j][k]))
+				}
+				mi := 0.0
+				if hist.counted() > 0 {
+					mi = hist.CalculateNormalizedMutualInformation(opts.Normalization)
+				}
+				matrix[p.i][p.j], matrix[p.j][p.i] = mi, mi
+				tick()
+			}
+		}()
+	}
+	for i := 0; i < columns; i++ {
+		for j := i; j < columns; j++ {
+			pairs <- pair{i, j}
+		}
+	}
+	close(pairs)
+	wg.Wait()
+	return matrix, nil
+}
@@ -0,0 +1,63 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMutualInformationMatrix(t *testing.T) {
+	rng := rand.New(rand.NewSource(50))
+	n := 2000
+	data := make([][]float64, 4)
+	for c := range data {
+		data[c] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		data[0][i] = rng.Float64()
+		data[1][i] = data[0][i] + 0.1*rng.Float64()
+		data[2][i] = rng.Float64()
+		data[3][i] = 7
+	}
+	data[2][5] = math.NaN()
+	matrix, err := MutualInformationMatrix(data, 8, MatrixOptions{Workers: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		for j := range data {
+			if matrix[i][j] != matrix[j][i] {
+				t.Errorf("matrix not symmetric at %d, %d", i, j)
+			}
+			min0, max0, _ := DataRange(data[i])
+			min1, max1, _ := DataRange(data[j])
+			if i == 3 || j == 3 {
+				if matrix[i][j] != 0 {
+					t.Errorf("MI with the constant column %v, want 0", matrix[i][j])
+				}
+				continue
+			}
+			want, _ := MutualInformation(8, 8, min0, max0, min1, max1, data[i], data[j])
+			if !almostEqual(matrix[i][j], want, 1e-12) {
+				t.Errorf("MI(%d, %d) = %v, want %v", i, j, matrix[i][j], want)
+			}
+		}
+	}
+	if !(matrix[0][1] > 1) || !(matrix[0][2] < 0.05) {
+		t.Errorf("dependent pair %v, independent pair %v", matrix[0][1], matrix[0][2])
+	}
+
+	normalized, err := MutualInformationMatrix(data[:3], 8, MatrixOptions{Normalization: NormalizationSymmetric})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(normalized[1][1], 1, 1e-12) {
+		t.Errorf("normalized diagonal %v, want 1", normalized[1][1])
+	}
+	if _, err := MutualInformationMatrix([][]float64{{1, 2}, {1}}, 8, MatrixOptions{}); err == nil {
+		t.Error("expected error for columns of different sizes")
+	}
+	if _, err := MutualInformationMatrix([][]float64{{math.NaN()}}, 8, MatrixOptions{}); err == nil {
+		t.Error("expected error for a column without finite values")
+	}
+}
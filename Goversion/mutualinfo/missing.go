@@ -0,0 +1,130 @@
+package mutualinfo
+
+import (
+	"fmt"
+	"math"
+)
+
+// NaNPolicy selects how NaN values, e.g. missing samples, are handled.
+type NaNPolicy int
+
+const (
+	// DropNaNPairwise skips only the pairs holding a NaN. In a shift sweep
+	// a sample with a NaN in dataX can still pair its dataY value with the
+	// dataX values of other time steps.
+	DropNaNPairwise NaNPolicy = iota
+	// DropNaNListwise drops every time step at which dataX or dataY is NaN
+	// from both series, so that no shift uses either of its values.
+	DropNaNListwise
+	// RejectNaN returns an error naming the first NaN value.
+	RejectNaN
+)
+
+func (p NaNPolicy) String() string {
+	switch p {
+	case DropNaNPairwise:
+		return "pairwise"
+	case DropNaNListwise:
+		return "listwise"
+	case RejectNaN:
+		return "reject"
+	}
+	return "unknown"
+}
+
+// checkNaN returns an error naming the first NaN of data, like CheckFinite.
+func checkNaN(name string, data []float64) error {
+	for i, v := range data {
+		if math.IsNaN(v) {
+			return invalid(ErrInvalidData, name, fmt.Sprintf("%s[%d] is NaN", name, i))
+		}
+	}
+	return nil
+}
+
+// dropListwise returns copies of dataX and dataY with both values set to NaN
+// at every time step where either is NaN, together with the number of such
+// steps. Without NaN values, dataX and dataY are returned as is.
+func dropListwise(dataX, dataY []float64) (x, y []float64, dropped int) {
+	for i := range dataX {
+		if math.IsNaN(dataX[i]) || math.IsNaN(dataY[i]) {
+			if x == nil {
+				x, y = append([]float64(nil), dataX...), append([]float64(nil), dataY...)
+			}
+			x[i], y[i] = math.NaN(), math.NaN()
+			dropped++
+		}
+	}
+	if x == nil {
+		return dataX, dataY, 0
+	}
+	return x, y, dropped
+}
+
+// CalculateIndices1DWithPolicy is CalculateIndices1D with NaN values handled
+// according to policy, and also returns their number. With a single series
+// both drop policies mark them with -1.
+func CalculateIndices1DWithPolicy(bins int, min, max float64, data []float64, policy NaNPolicy) (indices []int, dropped int, err error) {
+	if policy < DropNaNPairwise || policy > RejectNaN {
+		return nil, 0, invalid(ErrInvalidOption, "NaNPolicy", "unknown NaN policy")
+	}
+	if policy == RejectNaN {
+		if err := checkNaN("data", data); err != nil {
+			return nil, 0, err
+		}
+	}
+	if indices, err = CalculateIndices1D(bins, min, max, data); err != nil {
+		return nil, 0, err
+	}
+	for _, v := range data {
+		if math.IsNaN(v) {
+			dropped++
+		}
+	}
+	return indices, dropped, nil
+}
+
+// CalculateIndices2DWithPolicy is CalculateIndices2D with NaN values handled
+// according to policy, and also returns the number of pairs holding a NaN.
+// Without a shift, both drop policies mark the same pairs with {-1, -1}.
+func CalculateIndices2DWithPolicy(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, policy NaNPolicy) (indices []IndexPair, dropped int, err error) {
+	if policy < DropNaNPairwise || policy > RejectNaN {
+		return nil, 0, invalid(ErrInvalidOption, "NaNPolicy", "unknown NaN policy")
+	}
+	if indices, err = CalculateIndices2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return nil, 0, err
+	}
+	if policy == RejectNaN {
+		if err := checkNaN("dataX", dataX); err != nil {
+			return nil, 0, err
+		}
+		if err := checkNaN("dataY", dataY); err != nil {
+			return nil, 0, err
+		}
+	}
+	for i := range dataX {
+		if math.IsNaN(dataX[i]) || math.IsNaN(dataY[i]) {
+			dropped++
+		}
+	}
+	return indices, dropped, nil
+}
+
+// shiftedNaNPairs returns the number of pairs (dataX[j+shift], dataY[j])
+// with lo <= j < hi holding a NaN for every shift of the sweep.
+func shiftedNaNPairs(shiftFrom, shiftTo, shiftStep, lo, hi int, dataX, dataY []float64) []int {
+	counts := make([]int, (shiftTo-shiftFrom)/shiftStep+1)
+	if checkNaN("dataX", dataX) == nil && checkNaN("dataY", dataY) == nil {
+		return counts
+	}
+	n := len(dataX)
+	for i := range counts {
+		shift := shiftFrom + i*shiftStep
+		for j := maxInt(lo, -shift); j < minInt(hi, n-shift); j++ {
+			if math.IsNaN(dataX[j+shift]) || math.IsNaN(dataY[j]) {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,84 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestNaNPolicy(t *testing.T) {
+	rng := rand.New(rand.NewSource(44))
+	dataX := make([]float64, 300)
+	dataY := make([]float64, 300)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.5*dataX[i] + 0.5*rng.Float64()
+	}
+	dataX[10], dataY[20] = math.NaN(), math.NaN()
+
+	pairwise, err := ShiftedMutualInformationResult(-1, 1, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each NaN is in one pair per shift.
+	if want := []int{2, 2, 2}; !slices.Equal(pairwise.Dropped, want) {
+		t.Errorf("pairwise dropped %v, want %v", pairwise.Dropped, want)
+	}
+	listwise, err := ShiftedMutualInformationResult(-1, 1, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{NaNPolicy: DropNaNListwise})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Steps 10 and 20 lose both values, which shifts of ±1 meet twice each.
+	if want := []int{4, 2, 4}; !slices.Equal(listwise.Dropped, want) {
+		t.Errorf("listwise dropped %v, want %v", listwise.Dropped, want)
+	}
+	if listwise.MI[1] != pairwise.MI[1] {
+		t.Errorf("without a shift the policies differ: %v and %v", listwise.MI[1], pairwise.MI[1])
+	}
+	for i := range listwise.Pairs {
+		if listwise.Pairs[i]+listwise.Dropped[i] != pairwise.Pairs[i]+pairwise.Dropped[i] {
+			t.Errorf("shift %d: %d+%d pairs listwise, %d+%d pairwise", listwise.Shifts[i], listwise.Pairs[i], listwise.Dropped[i], pairwise.Pairs[i], pairwise.Dropped[i])
+		}
+	}
+
+	_, err = ShiftedMutualInformationWithOptions(-1, 1, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{NaNPolicy: RejectNaN})
+	if err == nil || err.Error() != "dataX[10] is NaN" {
+		t.Errorf("RejectNaN gives error %v", err)
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-1, 1, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{NaNPolicy: 9}); err == nil {
+		t.Error("expected error for an unknown NaN policy")
+	}
+
+	hist := NewHistogram2D(4, 4, 0, 1, 0, 1)
+	hist.Increment(math.NaN(), 0.5)
+	hist.Increment(2, 0.5)
+	if hist.OutOfRange != 2 || hist.Missing != 1 {
+		t.Errorf("OutOfRange %d and Missing %d, want 2 and 1", hist.OutOfRange, hist.Missing)
+	}
+}
+
+func TestCalculateIndicesWithPolicy(t *testing.T) {
+	dataX := []float64{0.1, math.NaN(), 0.9, 2}
+	dataY := []float64{0.2, 0.4, math.NaN(), 0.5}
+	indices, dropped, err := CalculateIndices2DWithPolicy(2, 2, 0, 1, 0, 1, dataX, dataY, DropNaNListwise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dropped != 2 || indices[1].First != -1 || indices[2].First != -1 || indices[0] != (IndexPair{0, 0}) {
+		t.Errorf("indices %v with %d dropped", indices, dropped)
+	}
+	if _, _, err := CalculateIndices2DWithPolicy(2, 2, 0, 1, 0, 1, dataX, dataY, RejectNaN); err == nil {
+		t.Error("expected error for RejectNaN")
+	}
+	indices1D, dropped, err := CalculateIndices1DWithPolicy(2, 0, 1, dataX, DropNaNPairwise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dropped != 1 || indices1D[1] != -1 || indices1D[3] != -1 {
+		t.Errorf("indices %v with %d dropped", indices1D, dropped)
+	}
+	if _, _, err := CalculateIndices1DWithPolicy(2, 0, 1, dataX, RejectNaN); err == nil || err.Error() != "data[1] is NaN" {
+		t.Errorf("RejectNaN gives error %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !unix
+
+package mutualinfo
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// MappedPairs is an XYSource over a memory-mapped file of binary pairs. Memory
+// mapping is only supported on Unix systems; elsewhere use StreamBinaryPairs.
+type MappedPairs struct{}
+
+// OpenMappedPairs returns an error on systems without memory mapping.
+func OpenMappedPairs(path string, order binary.ByteOrder) (*MappedPairs, error) {
+	return nil, errors.New("memory mapping is not supported on this system")
+}
+
+func (m *MappedPairs) Len() int {
+	return 0
+}
+
+func (m *MappedPairs) At(i int) (x, y float64) {
+	panic("mutualinfo: MappedPairs is not supported on this system")
+}
+
+// Close does nothing.
+func (m *MappedPairs) Close() error {
+	return nil
+}
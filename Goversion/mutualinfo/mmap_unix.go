@@ -0,0 +1,66 @@
+//go:build unix
+
+package mutualinfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"syscall"
+)
+
+// MappedPairs is an XYSource over a memory-mapped file of binary pairs, see
+// StreamBinaryPairs, so that the shift sweeps can run on files larger than
+// memory, the operating system paging the data in as it is read. It must be
+// closed after use; its methods must not be called after Close.
+type MappedPairs struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// OpenMappedPairs maps the binary pairs in the file at path read-only.
+func OpenMappedPairs(path string, order binary.ByteOrder) (*MappedPairs, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size%binaryPairSize != 0 {
+		return nil, errors.New("data ends within a pair")
+	}
+	if size == 0 {
+		return &MappedPairs{order: order}, nil
+	}
+	if int64(int(size)) != size {
+		return nil, errors.New("file is too large to map")
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return &MappedPairs{data: data, order: order}, nil
+}
+
+func (m *MappedPairs) Len() int {
+	return len(m.data) / binaryPairSize
+}
+
+func (m *MappedPairs) At(i int) (x, y float64) {
+	return decodePair(m.data[i*binaryPairSize:], m.order)
+}
+
+// Close unmaps the file.
+func (m *MappedPairs) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	return err
+}
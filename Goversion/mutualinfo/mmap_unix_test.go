@@ -0,0 +1,63 @@
+//go:build unix
+
+package mutualinfo
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedPairs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	dataX := make([]float64, 2000)
+	dataY := make([]float64, len(dataX))
+	interleaved := make([]float64, 0, 2*len(dataX))
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = dataX[(i+5)%len(dataX)]
+		interleaved = append(interleaved, dataX[i], dataY[i])
+	}
+	path := filepath.Join(t.TempDir(), "pairs.bin")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := binary.Write(file, binary.LittleEndian, interleaved); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mapped, err := OpenMappedPairs(path, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+	if mapped.Len() != len(dataX) {
+		t.Fatalf("got %d pairs, want %d", mapped.Len(), len(dataX))
+	}
+	want, err := ShiftedMutualInformation(-8, 8, 8, 8, 0, 1, 0, 1, dataX, dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ShiftedMutualInformationSource(-8, 8, 8, 8, 0, 1, 0, 1, mapped, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("shift %d gives %v, want %v", i-8, got[i], want[i])
+		}
+	}
+
+	if err := os.WriteFile(path, make([]byte, 20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenMappedPairs(path, binary.LittleEndian); err == nil {
+		t.Error("expected error for a file ending within a pair")
+	}
+}
@@ -0,0 +1,160 @@
+package mutualinfo
+
+import (
+	"math"
+	"sync"
+)
+
+// MIMonitorConfig configures an MIMonitor.
+type MIMonitorConfig struct {
+	BinsX, BinsY int
+	MinX, MaxX   float64
+	MinY, MaxY   float64
+	// Decay in (0, 1] is the factor by which all previous observations are
+	// down-weighted on every new observation. 1 disables decay.
+	Decay     float64
+	Threshold float64
+	// OnThreshold is called whenever the current MI crosses Threshold.
+	// crossed is true when MI rose to or above the threshold and false when it fell below.
+	OnThreshold func(mi float64, crossed bool)
+	// OutOfRange selects how pairs outside the ranges are handled, for
+	// example when the ranges were fixed with RangeFromCalibration.
+	OutOfRange OutOfRangePolicy
+	// Checkpoints lists the numbers of counted pairs at which the current MI
+	// is recorded in the history, see LogCheckpoints.
+	Checkpoints []int
+}
+
+// MISnapshot is the MI after N counted pairs.
+type MISnapshot struct {
+	N  int
+	MI float64
+}
+
+// MIMonitor ingests (x, y) pairs one by one into an exponentially decaying
+// histogram and reports threshold crossings of the current MI.
+type MIMonitor struct {
+	cfg    MIMonitorConfig
+	data   [][]float64
+	weight float64
+	mi     float64
+	above  bool
+	// outOfRange counts the pairs with at least one value outside the ranges.
+	outOfRange int
+	// n counts the pairs entering the histogram.
+	n          int
+	checkpoint map[int]bool
+	history    []MISnapshot
+	mutex      sync.Mutex
+}
+
+// maxMonitorWeight bounds the growing per-sample weight before the grid is rescaled.
+const maxMonitorWeight = 1e100
+
+func NewMIMonitor(cfg MIMonitorConfig) (*MIMonitor, error) {
+	if err := validate2D(cfg.BinsX, cfg.BinsY, cfg.MinX, cfg.MaxX, cfg.MinY, cfg.MaxY, nil, nil); err != nil {
+		return nil, err
+	}
+	if !(cfg.Decay > 0 && cfg.Decay <= 1) {
+		return nil, invalid(ErrInvalidParameter, "decay", "decay must be in (0, 1]")
+	}
+	data := make([][]float64, cfg.BinsX)
+	for i := range data {
+		data[i] = make([]float64, cfg.BinsY)
+	}
+	checkpoint := make(map[int]bool, len(cfg.Checkpoints))
+	for _, n := range cfg.Checkpoints {
+		checkpoint[n] = true
+	}
+	return &MIMonitor{cfg: cfg, data: data, weight: 1, checkpoint: checkpoint}, nil
+}
+
+// Observe adds a pair to the monitor and updates the current MI.
+// Pairs outside the configured ranges are handled according to cfg.OutOfRange.
+// NaN is out of range and always dropped, as it cannot be clamped.
+func (m *MIMonitor) Observe(x, y float64) {
+	cfg := m.cfg
+	m.mutex.Lock()
+	if !(x >= cfg.MinX && x <= cfg.MaxX) || !(y >= cfg.MinY && y <= cfg.MaxY) {
+		m.outOfRange++
+		if cfg.OutOfRange != ClampOutOfRange || math.IsNaN(x) || math.IsNaN(y) {
+			m.mutex.Unlock()
+			return
+		}
+		x = clamp(x, cfg.MinX, cfg.MaxX)
+		y = clamp(y, cfg.MinY, cfg.MaxY)
+	}
+
+	// Instead of decaying every cell, newer samples get a growing weight.
+	// Only the relative weights matter for the MI.
+	m.weight /= cfg.Decay
+	if m.weight > maxMonitorWeight {
+		for i := range m.data {
+			for j := range m.data[i] {
+				m.data[i][j] /= m.weight
+			}
+		}
+		m.weight = 1
+	}
+
+	indexX := binIndex(x, cfg.MinX, cfg.MaxX, cfg.BinsX)
+	indexY := binIndex(y, cfg.MinY, cfg.MaxY, cfg.BinsY)
+	m.data[indexX][indexY] += m.weight
+
+	m.mi, _ = MutualInformationFromJoint(m.data)
+	m.n++
+	if m.checkpoint[m.n] {
+		m.history = append(m.history, MISnapshot{N: m.n, MI: m.mi})
+	}
+	mi := m.mi
+	above := mi >= cfg.Threshold
+	changed := above != m.above
+	m.above = above
+	m.mutex.Unlock()
+
+	if changed && cfg.OnThreshold != nil {
+		cfg.OnThreshold(mi, above)
+	}
+}
+
+// MI returns the mutual information of the current decayed histogram.
+func (m *MIMonitor) MI() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.mi
+}
+
+// OutOfRange returns the number of observed pairs with at least one value
+// outside the configured ranges, whether they were dropped or clamped.
+func (m *MIMonitor) OutOfRange() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.outOfRange
+}
+
+// History returns the MI recorded at the configured checkpoints so far.
+func (m *MIMonitor) History() []MISnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]MISnapshot(nil), m.history...)
+}
+
+// LogCheckpoints returns about perDecade logarithmically spaced sample counts
+// per decade from 1 up to maxN, suitable as MIMonitorConfig.Checkpoints for
+// recording a convergence curve.
+func LogCheckpoints(maxN, perDecade int) []int {
+	var checkpoints []int
+	if maxN < 1 || perDecade < 1 {
+		return checkpoints
+	}
+	for k := 0; ; k++ {
+		n := int(math.Round(math.Pow(10, float64(k)/float64(perDecade))))
+		if n > maxN {
+			break
+		}
+		if len(checkpoints) == 0 || n > checkpoints[len(checkpoints)-1] {
+			checkpoints = append(checkpoints, n)
+		}
+	}
+	return checkpoints
+}
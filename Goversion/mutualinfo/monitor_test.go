@@ -0,0 +1,68 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMIMonitor(t *testing.T) {
+	var events []bool
+	monitor, err := NewMIMonitor(MIMonitorConfig{
+		BinsX: 4, BinsY: 4, MaxX: 1, MaxY: 1,
+		Decay:       0.99,
+		Threshold:   0.5,
+		OnThreshold: func(mi float64, crossed bool) { events = append(events, crossed) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 2000; i++ {
+		v := rng.Float64()
+		monitor.Observe(v, v)
+	}
+	if monitor.MI() < 0.5 {
+		t.Fatalf("expected MI above threshold for coupled data, got %v", monitor.MI())
+	}
+	for i := 0; i < 2000; i++ {
+		monitor.Observe(rng.Float64(), rng.Float64())
+	}
+	if len(events) != 2 || !events[0] || events[1] {
+		t.Errorf("expected one upward and one downward crossing, got %v", events)
+	}
+
+	if _, err := NewMIMonitor(MIMonitorConfig{BinsX: 4, BinsY: 4, MaxX: 1, MaxY: 1}); err == nil {
+		t.Error("expected error for zero decay")
+	}
+}
+
+func TestMIMonitorHistory(t *testing.T) {
+	checkpoints := LogCheckpoints(1000, 2)
+	want := []int{1, 3, 10, 32, 100, 316, 1000}
+	if len(checkpoints) != len(want) {
+		t.Fatalf("LogCheckpoints = %v, want %v", checkpoints, want)
+	}
+	for i := range want {
+		if checkpoints[i] != want[i] {
+			t.Fatalf("LogCheckpoints = %v, want %v", checkpoints, want)
+		}
+	}
+
+	monitor, err := NewMIMonitor(MIMonitorConfig{BinsX: 4, BinsY: 4, MaxX: 1, MaxY: 1, Decay: 1, Checkpoints: checkpoints})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(17))
+	for i := 0; i < 500; i++ {
+		monitor.Observe(rng.Float64(), rng.Float64())
+	}
+	history := monitor.History()
+	if len(history) != 6 || history[5].N != 316 {
+		t.Fatalf("unexpected history %v", history)
+	}
+	// The upward bias of independent data decays with the sample size.
+	if history[5].MI >= history[2].MI {
+		t.Errorf("expected the estimate to converge towards 0, got %v", history)
+	}
+}
@@ -0,0 +1,35 @@
+package mutualinfo
+
+// MultiLagMutualInformation estimates I(target(t); (source(t-1), ..., source(t-k))),
+// the information that the last k values of source jointly carry about the
+// current value of target. Each lag is binned into binsSource bins over
+// [minSource, maxSource] and the k bin indices are combined into a single
+// symbol, so only occupied cells are stored. Time steps with a value outside
+// the ranges are skipped. Unlike a single lag, the joint vector also captures
+// information that is only visible in combinations of lags, but the estimate
+// needs far more samples than binsTarget*binsSource^k to be reliable.
+func MultiLagMutualInformation(k, binsTarget, binsSource int, minTarget, maxTarget, minSource, maxSource float64, target, source []float64) (float64, error) {
+	if err := validate2D(binsTarget, binsSource, minTarget, maxTarget, minSource, maxSource, target, source); err != nil {
+		return 0, err
+	}
+	if k < 1 {
+		return 0, invalid(ErrInvalidParameter, "k", "k must be greater or equal 1")
+	}
+	if k >= len(target) {
+		return 0, invalid(ErrTooFewSamples, "data", "data must be longer than k")
+	}
+	if _, ok := blockSymbols(binsSource, k); !ok {
+		return 0, invalid(ErrInvalidBins, "binsSource", "binsSource^k exceeds the supported number of block symbols")
+	}
+
+	targetIndices, _ := CalculateIndices1D(binsTarget, minTarget, maxTarget, target)
+	sourceIndices, _ := CalculateIndices1D(binsSource, minSource, maxSource, source)
+	counts := make(map[IndexPair]int)
+	for t := k; t < len(target); t++ {
+		past, ok := blockSymbol(sourceIndices[t-k:t], binsSource)
+		if ok && targetIndices[t] >= 0 {
+			counts[IndexPair{First: targetIndices[t], Second: past}]++
+		}
+	}
+	return sparseMutualInformation(counts, nil), nil
+}
@@ -0,0 +1,43 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMultiLagMutualInformation(t *testing.T) {
+	// The target is the XOR of the last two source values, which neither lag
+	// reveals on its own.
+	rng := rand.New(rand.NewSource(5))
+	n := 20000
+	source := make([]float64, n)
+	target := make([]float64, n)
+	for i := range source {
+		source[i] = float64(rng.Intn(2))
+		if i >= 2 {
+			target[i] = float64((int(source[i-1]) + int(source[i-2])) % 2)
+		}
+	}
+
+	joint, err := MultiLagMutualInformation(2, 2, 2, 0, 1, 0, 1, target, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(joint, 1, 1e-2) {
+		t.Errorf("joint MI of two lags = %v, want about 1 bit", joint)
+	}
+	single, err := MultiLagMutualInformation(1, 2, 2, 0, 1, 0, 1, target, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single > 1e-2 {
+		t.Errorf("single lag MI = %v, want about 0", single)
+	}
+
+	if _, err := MultiLagMutualInformation(0, 2, 2, 0, 1, 0, 1, target, source); err == nil {
+		t.Error("expected error for k = 0")
+	}
+	if _, err := MultiLagMutualInformation(50, 2, 1024, 0, 1, 0, 1, target, source); err == nil {
+		t.Error("expected error when binsSource^k is too large")
+	}
+}
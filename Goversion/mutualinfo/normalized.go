@@ -0,0 +1,73 @@
+package mutualinfo
+
+import "math"
+
+// Normalization selects the denominator of a normalized mutual information.
+type Normalization int
+
+const (
+	// NormalizationNone leaves the mutual information in bits.
+	NormalizationNone Normalization = iota
+	// NormalizationSymmetric divides by the mean marginal entropy, 2I/(H(X)+H(Y)).
+	NormalizationSymmetric
+	// NormalizationMin divides by min(H(X), H(Y)).
+	NormalizationMin
+	// NormalizationSqrt divides by the geometric mean sqrt(H(X)H(Y)).
+	NormalizationSqrt
+	// NormalizationJoint divides by the joint entropy H(X,Y).
+	NormalizationJoint
+	// NormalizationRedundancy is the redundancy I/(H(X)+H(Y)), which is at
+	// most 1/2, reached when X and Y determine each other.
+	NormalizationRedundancy
+)
+
+func (n Normalization) String() string {
+	switch n {
+	case NormalizationNone:
+		return "none"
+	case NormalizationSymmetric:
+		return "symmetric"
+	case NormalizationMin:
+		return "min"
+	case NormalizationSqrt:
+		return "sqrt"
+	case NormalizationJoint:
+		return "joint"
+	case NormalizationRedundancy:
+		return "redundancy"
+	}
+	return "unknown"
+}
+
+// CalculateNormalizedMutualInformation returns the mutual information
+// divided by the denominator selected by norm, which bounds it to [0, 1].
+// It is 0 if the denominator is zero, e.g. when all pairs fall into a single
+// bin of X or Y.
+func (h *Histogram2D) CalculateNormalizedMutualInformation(norm Normalization) float64 {
+	hx, hy, hxy := h.Entropies()
+	return normalizeMutualInformation(hx, hy, hxy, norm)
+}
+
+func normalizeMutualInformation(hx, hy, hxy float64, norm Normalization) float64 {
+	mi := hx + hy - hxy
+	var denominator float64
+	switch norm {
+	case NormalizationSymmetric:
+		denominator = (hx + hy) / 2
+	case NormalizationMin:
+		denominator = math.Min(hx, hy)
+	case NormalizationSqrt:
+		denominator = math.Sqrt(hx * hy)
+	case NormalizationJoint:
+		denominator = hxy
+	case NormalizationRedundancy:
+		denominator = hx + hy
+	default:
+		return mi
+	}
+	if denominator == 0 {
+		return 0
+	}
+	// Rounding may push the ratio slightly outside [0, 1].
+	return clamp(mi/denominator, 0, 1)
+}
@@ -0,0 +1,60 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateNormalizedMutualInformation(t *testing.T) {
+	// Y determines X but X has four bins and Y only two, so H(X) = 2 and H(Y) = I = 1.
+	hist := NewHistogram2D(4, 2, 0, 4, 0, 2)
+	for _, p := range [][2]float64{{0.5, 0.5}, {1.5, 0.5}, {2.5, 1.5}, {3.5, 1.5}} {
+		hist.Increment(p[0], p[1])
+	}
+	if got := hist.CalculateNormalizedMutualInformation(NormalizationSymmetric); !almostEqual(got, 2.0/3, 1e-12) {
+		t.Errorf("symmetric = %v, want 2/3", got)
+	}
+	if got := hist.CalculateNormalizedMutualInformation(NormalizationMin); !almostEqual(got, 1, 1e-12) {
+		t.Errorf("min = %v, want 1", got)
+	}
+	if got := hist.CalculateNormalizedMutualInformation(NormalizationSqrt); !almostEqual(got, 1/math.Sqrt2, 1e-12) {
+		t.Errorf("sqrt = %v, want 1/sqrt(2)", got)
+	}
+	// H(X,Y) = H(X) = 2.
+	if got := hist.CalculateNormalizedMutualInformation(NormalizationJoint); !almostEqual(got, 0.5, 1e-12) {
+		t.Errorf("joint = %v, want 1/2", got)
+	}
+	if got := hist.CalculateNormalizedMutualInformation(NormalizationRedundancy); !almostEqual(got, 1.0/3, 1e-12) {
+		t.Errorf("redundancy = %v, want 1/3", got)
+	}
+	if got := hist.CalculateNormalizedMutualInformation(NormalizationNone); got != hist.CalculateMutualInformation() {
+		t.Errorf("none = %v, want the MI in bits", got)
+	}
+
+	single := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	single.Increment(0.5, 0.5)
+	single.Increment(0.5, 0.5)
+	if got := single.CalculateNormalizedMutualInformation(NormalizationSymmetric); got != 0 {
+		t.Errorf("single occupied bin = %v, want 0", got)
+	}
+}
+
+func TestShiftedNormalizedMutualInformation(t *testing.T) {
+	data := []float64{0, 1, 2, 3, 0, 1, 2, 3}
+	mi, err := ShiftedMutualInformationWithOptions(0, 1, 4, 4, 0, 4, 0, 4, data, data, 1, ShiftOptions{Normalization: NormalizationMin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for shift, v := range mi {
+		if !almostEqual(v, 1, 1e-12) {
+			t.Errorf("shift %d: normalized MI %v, want 1", shift, v)
+		}
+	}
+}
+
+func TestShiftedMutualInformationUnknownNormalization(t *testing.T) {
+	data := []float64{0, 1, 2, 3, 0, 1, 2, 3}
+	if _, err := ShiftedMutualInformationWithOptions(0, 1, 4, 4, 0, 4, 0, 4, data, data, 1, ShiftOptions{Normalization: Normalization(42)}); err == nil {
+		t.Error("expected error for an unknown normalization")
+	}
+}
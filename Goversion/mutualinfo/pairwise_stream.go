@@ -0,0 +1,121 @@
+package mutualinfo
+
+import (
+	"math"
+	"sync"
+)
+
+// StreamingPairwise maintains the histograms of all channel pairs of a
+// multichannel stream so that the pairwise MI matrix can be read at any time.
+type StreamingPairwise struct {
+	bins     int
+	min, max []float64
+	counts   [][]int          // per-channel 1D counts for the diagonal
+	pairs    [][]*Histogram2D // pairs[i][j] for i < j
+	mutex    sync.Mutex
+}
+
+// NewStreamingPairwise creates a streaming pairwise accumulator for
+// len(min) channels, channel c using bins bins over [min[c], max[c]].
+func NewStreamingPairwise(bins int, min, max []float64) (*StreamingPairwise, error) {
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	if len(min) != len(max) {
+		return nil, invalid(ErrSizeMismatch, "min", "min and max must have the same size")
+	}
+	if len(min) < 2 {
+		return nil, invalid(ErrInvalidParameter, "min", "there must be at least two channels")
+	}
+	for c := range min {
+		if !isFinite(min[c]) || !isFinite(max[c]) {
+			return nil, invalid(ErrInvalidRange, "min", "min and max must be finite for every channel")
+		}
+		if min[c] >= max[c] {
+			return nil, invalid(ErrInvalidRange, "min", "min has to be smaller than max for every channel")
+		}
+	}
+
+	n := len(min)
+	s := &StreamingPairwise{
+		bins:   bins,
+		min:    append([]float64(nil), min...),
+		max:    append([]float64(nil), max...),
+		counts: make([][]int, n),
+		pairs:  make([][]*Histogram2D, n),
+	}
+	for i := 0; i < n; i++ {
+		s.counts[i] = make([]int, bins)
+		s.pairs[i] = make([]*Histogram2D, n)
+		for j := i + 1; j < n; j++ {
+			s.pairs[i][j] = NewHistogram2D(bins, bins, min[i], max[i], min[j], max[j])
+		}
+	}
+	return s, nil
+}
+
+// Observe adds one sample holding one value per channel. Channels whose value
+// is outside their range are left out of every pair for this sample.
+func (s *StreamingPairwise) Observe(sample []float64) error {
+	if len(sample) != len(s.min) {
+		return invalid(ErrSizeMismatch, "sample", "sample must hold exactly one value per channel")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	valid := make([]bool, len(sample))
+	for c, v := range sample {
+		index := binIndex(v, s.min[c], s.max[c], s.bins)
+		if index < 0 {
+			continue
+		}
+		valid[c] = true
+		s.counts[c][index]++
+	}
+	for i := range sample {
+		if !valid[i] {
+			continue
+		}
+		for j := i + 1; j < len(sample); j++ {
+			if valid[j] {
+				s.pairs[i][j].Increment(sample[i], sample[j])
+			}
+		}
+	}
+	return nil
+}
+
+// Matrix returns a snapshot of the symmetric pairwise MI matrix. The diagonal
+// holds the entropy of each channel, which equals its MI with itself.
+func (s *StreamingPairwise) Matrix() [][]float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n := len(s.min)
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		total := 0
+		for _, c := range s.counts[i] {
+			total += c
+		}
+		for _, c := range s.counts[i] {
+			if c != 0 {
+				p := float64(c) / float64(total)
+				matrix[i][i] -= p * math.Log2(p)
+			}
+		}
+		for j := i + 1; j < n; j++ {
+			mi := 0.0
+			if _, _, total := s.pairs[i][j].marginalCounts(); total > 0 {
+				mi = s.pairs[i][j].CalculateMutualInformation()
+			}
+			matrix[i][j] = mi
+			matrix[j][i] = mi
+		}
+	}
+	return matrix
+}
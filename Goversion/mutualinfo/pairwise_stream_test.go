@@ -0,0 +1,34 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStreamingPairwise(t *testing.T) {
+	stream, err := NewStreamingPairwise(4, []float64{0, 0, 0}, []float64{1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := stream.Matrix(); m[0][1] != 0 || m[0][0] != 0 {
+		t.Errorf("expected zero matrix before any sample, got %v", m)
+	}
+
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 4000; i++ {
+		v := rng.Float64()
+		if err := stream.Observe([]float64{v, v, rng.Float64()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := stream.Matrix()
+	if !almostEqual(m[0][1], m[0][0], 1e-9) || m[0][1] < 1.9 {
+		t.Errorf("identical channels should share their full entropy, got %v", m)
+	}
+	if m[0][2] > 0.05 || m[0][2] != m[2][0] {
+		t.Errorf("independent channels should have near-zero symmetric MI, got %v", m)
+	}
+	if err := stream.Observe([]float64{0.5}); err == nil {
+		t.Error("expected error for wrong sample size")
+	}
+}
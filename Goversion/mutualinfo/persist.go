@@ -0,0 +1,518 @@
+package mutualinfo
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// The binary formats start with a magic number and a version, followed by
+// the bins and ranges and then the counts as uvarints, little endian floats.
+// Version 1, with the Outside counts of a Histogram2D, is the first released
+// layout; any change to a layout must increment the version.
+const (
+	histogram2DMagic = "MIH2"
+	histogramNDMagic = "MIHN"
+	histogramVersion = 1
+)
+
+const (
+	flagEdgesX = 1 << iota
+	flagEdgesY
+	flagWeighted
+)
+
+// MarshalBinary encodes h compactly, with its counts, weights, edges and
+// out-of-range counts including Outside, e.g. to checkpoint an accumulation
+// and resume it with UnmarshalBinary or merge it with the histograms of
+// other machines, see Merge.
+func (h *Histogram2D) MarshalBinary() ([]byte, error) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	var flags byte
+	if h.EdgesX != nil {
+		flags |= flagEdgesX
+	}
+	if h.EdgesY != nil {
+		flags |= flagEdgesY
+	}
+	if h.WeightedData != nil {
+		flags |= flagWeighted
+	}
+	buf := append([]byte(histogram2DMagic), histogramVersion, flags)
+	buf = binary.AppendUvarint(buf, uint64(h.BinsX))
+	buf = binary.AppendUvarint(buf, uint64(h.BinsY))
+	for _, v := range []float64{h.MinX, h.MaxX, h.MinY, h.MaxY} {
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+	for _, v := range h.EdgesX {
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+	for _, v := range h.EdgesY {
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+	buf = binary.AppendUvarint(buf, uint64(h.OutOfRange))
+	buf = binary.AppendUvarint(buf, uint64(h.Missing))
+	for _, c := range []int{h.Outside.UnderX, h.Outside.OverX, h.Outside.UnderY, h.Outside.OverY} {
+		buf = binary.AppendUvarint(buf, uint64(c))
+	}
+	for _, row := range h.Data {
+		for _, c := range row {
+			buf = binary.AppendUvarint(buf, uint64(c))
+		}
+	}
+	for _, row := range h.WeightedData {
+		for _, w := range row {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(w))
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces h by the histogram encoded by MarshalBinary. An
+// error is returned for data that is not such an encoding or describes an
+// invalid histogram.
+func (h *Histogram2D) UnmarshalBinary(data []byte) error {
+	d := decoder{data: data}
+	if string(d.bytes(len(histogram2DMagic))) != histogram2DMagic {
+		return invalid(ErrInvalidData, "data", "data is not an encoded Histogram2D")
+	}
+	if d.byte() != histogramVersion {
+		return invalid(ErrInvalidData, "data", "unsupported histogram version")
+	}
+	flags := d.byte()
+	if flags&^(flagEdgesX|flagEdgesY|flagWeighted) != 0 {
+		return invalid(ErrInvalidData, "data", "unknown histogram flags")
+	}
+	binsX, binsY := d.count(), d.count()
+	minX, maxX, minY, maxY := d.float(), d.float(), d.float(), d.float()
+	// Every bin takes at least one byte, which bounds binsX+1 and binsY+1
+	// by the size of data before anything is allocated.
+	if d.err == nil && (binsX < 1 || binsY < 1 || binsX >= len(d.data) || binsY >= len(d.data)) {
+		return invalid(ErrInvalidData, "data", "data ends within the counts")
+	}
+	var edgesX, edgesY []float64
+	if flags&flagEdgesX != 0 {
+		edgesX = d.floats(binsX + 1)
+	}
+	if flags&flagEdgesY != 0 {
+		edgesY = d.floats(binsY + 1)
+	}
+	outOfRange, missing := d.count(), d.count()
+	outside := RangeCounts{UnderX: d.count(), OverX: d.count(), UnderY: d.count(), OverY: d.count()}
+	if d.err != nil {
+		return d.err
+	}
+	if binsX > len(d.data)/binsY {
+		return invalid(ErrInvalidData, "data", "data ends within the counts")
+	}
+	counts := make([][]int, binsX)
+	for i := range counts {
+		counts[i] = make([]int, binsY)
+		for j := range counts[i] {
+			counts[i][j] = d.count()
+		}
+	}
+	var weights [][]float64
+	if flags&flagWeighted != 0 {
+		weights = make([][]float64, binsX)
+		for i := range weights {
+			weights[i] = d.floats(binsY)
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) > 0 {
+		return invalid(ErrInvalidData, "data", "data continues after the histogram")
+	}
+	decoded, err := newDecodedHistogram2D(minX, maxX, minY, maxY, edgesX, edgesY, counts, weights, outOfRange, missing, outside)
+	if err != nil {
+		return err
+	}
+	h.replace(decoded)
+	return nil
+}
+
+// histogram2DJSON is the JSON form of a Histogram2D.
+type histogram2DJSON struct {
+	MinX       float64     `json:"min_x"`
+	MaxX       float64     `json:"max_x"`
+	MinY       float64     `json:"min_y"`
+	MaxY       float64     `json:"max_y"`
+	EdgesX     []float64   `json:"edges_x,omitempty"`
+	EdgesY     []float64   `json:"edges_y,omitempty"`
+	Counts     [][]int     `json:"counts"`
+	Weights    [][]float64 `json:"weights,omitempty"`
+	OutOfRange int         `json:"out_of_range"`
+	Missing    int         `json:"missing"`
+	Outside    RangeCounts `json:"outside"`
+}
+
+// MarshalJSON encodes h as JSON with the counts as a BinsX x BinsY array,
+// a readable alternative to MarshalBinary.
+func (h *Histogram2D) MarshalJSON() ([]byte, error) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	return json.Marshal(histogram2DJSON{
+		MinX: h.MinX, MaxX: h.MaxX, MinY: h.MinY, MaxY: h.MaxY,
+		EdgesX: h.EdgesX, EdgesY: h.EdgesY,
+		Counts: h.Data, Weights: h.WeightedData,
+		OutOfRange: h.OutOfRange, Missing: h.Missing, Outside: h.Outside,
+	})
+}
+
+// UnmarshalJSON replaces h by the histogram encoded by MarshalJSON.
+func (h *Histogram2D) UnmarshalJSON(data []byte) error {
+	var v histogram2DJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	decoded, err := newDecodedHistogram2D(v.MinX, v.MaxX, v.MinY, v.MaxY, v.EdgesX, v.EdgesY, v.Counts, v.Weights, v.OutOfRange, v.Missing, v.Outside)
+	if err != nil {
+		return err
+	}
+	h.replace(decoded)
+	return nil
+}
+
+// newDecodedHistogram2D builds a histogram from decoded fields after
+// checking that they are consistent.
+func newDecodedHistogram2D(minX, maxX, minY, maxY float64, edgesX, edgesY []float64, counts [][]int, weights [][]float64, outOfRange, missing int, outside RangeCounts) (*Histogram2D, error) {
+	if len(counts) == 0 || len(counts[0]) == 0 {
+		return nil, invalid(ErrInvalidBins, "counts", "there must be at least one binX and one binY")
+	}
+	binsX, binsY := len(counts), len(counts[0])
+	if edgesX != nil {
+		if err := validateEdges(edgesX); err != nil {
+			return nil, err
+		}
+		if len(edgesX) != binsX+1 || edgesX[0] != minX || edgesX[binsX] != maxX {
+			return nil, invalid(ErrInvalidData, "edges_x", "edges_x must match the bins and range of X")
+		}
+	}
+	if edgesY != nil {
+		if err := validateEdges(edgesY); err != nil {
+			return nil, err
+		}
+		if len(edgesY) != binsY+1 || edgesY[0] != minY || edgesY[binsY] != maxY {
+			return nil, invalid(ErrInvalidData, "edges_y", "edges_y must match the bins and range of Y")
+		}
+	}
+	if edgesX == nil || edgesY == nil {
+		if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	if outOfRange < 0 || missing < 0 || missing > outOfRange {
+		return nil, invalid(ErrInvalidData, "out_of_range", "out-of-range counts must not be negative and include the missing ones")
+	}
+	if outside.UnderX < 0 || outside.OverX < 0 || outside.UnderY < 0 || outside.OverY < 0 {
+		return nil, invalid(ErrInvalidData, "outside", "outside counts must not be negative")
+	}
+	if weights != nil && len(weights) != binsX {
+		return nil, invalid(ErrSizeMismatch, "weights", "weights must have the shape of counts")
+	}
+	hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	hist.EdgesX, hist.EdgesY = edgesX, edgesY
+	hist.OutOfRange, hist.Missing, hist.Outside = outOfRange, missing, outside
+	for i, row := range counts {
+		if len(row) != binsY {
+			return nil, invalid(ErrSizeMismatch, "counts", "all rows of counts must have the same size")
+		}
+		for j, c := range row {
+			if c < 0 {
+				return nil, invalid(ErrInvalidData, "counts", "counts must not be negative")
+			}
+			hist.Data[i][j] = c
+		}
+	}
+	if weights != nil {
+		hist.WeightedData = make([][]float64, binsX)
+		for i, row := range weights {
+			if len(row) != binsY {
+				return nil, invalid(ErrSizeMismatch, "weights", "weights must have the shape of counts")
+			}
+			for _, w := range row {
+				if !(w >= 0) || math.IsInf(w, 1) {
+					return nil, invalid(ErrInvalidData, "weights", "weights must be finite and not negative")
+				}
+			}
+			hist.WeightedData[i] = append([]float64(nil), row...)
+		}
+	}
+	return hist, nil
+}
+
+// replace sets the bins, ranges and counts of h to those of an unshared
+// histogram other.
+func (h *Histogram2D) replace(other *Histogram2D) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.BinsX, h.BinsY = other.BinsX, other.BinsY
+	h.MinX, h.MaxX, h.MinY, h.MaxY = other.MinX, other.MaxX, other.MinY, other.MaxY
+	h.Data, h.WeightedData, h.weightResiduals = other.Data, other.WeightedData, nil
+	h.EdgesX, h.EdgesY = other.EdgesX, other.EdgesY
+	h.OutOfRange, h.Missing, h.Outside = other.OutOfRange, other.Missing, other.Outside
+}
+
+// MarshalBinary encodes h compactly, with only its occupied cells, see
+// Histogram2D.MarshalBinary.
+func (h *HistogramND) MarshalBinary() ([]byte, error) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	buf := append([]byte(histogramNDMagic), histogramVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(h.Bins)))
+	for d := range h.Bins {
+		buf = binary.AppendUvarint(buf, uint64(h.Bins[d]))
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(h.Min[d]))
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(h.Max[d]))
+	}
+	buf = binary.AppendUvarint(buf, uint64(h.OutOfRange))
+	// The keys are sorted and stored as differences, which keeps them short.
+	keys := h.sortedKeys()
+	buf = binary.AppendUvarint(buf, uint64(len(keys)))
+	previous := 0
+	for _, key := range keys {
+		buf = binary.AppendUvarint(buf, uint64(key-previous))
+		buf = binary.AppendUvarint(buf, uint64(h.Counts[key]))
+		previous = key
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces h by the histogram encoded by MarshalBinary.
+func (h *HistogramND) UnmarshalBinary(data []byte) error {
+	d := decoder{data: data}
+	if string(d.bytes(len(histogramNDMagic))) != histogramNDMagic {
+		return invalid(ErrInvalidData, "data", "data is not an encoded HistogramND")
+	}
+	if d.byte() != histogramVersion {
+		return invalid(ErrInvalidData, "data", "unsupported histogram version")
+	}
+	dims := d.count()
+	// Every dimension takes at least 17 bytes.
+	if d.err == nil && dims > len(d.data)/17 {
+		return invalid(ErrInvalidData, "data", "data ends within the dimensions")
+	}
+	bins := make([]int, dims)
+	min, max := make([]float64, dims), make([]float64, dims)
+	for i := 0; i < dims; i++ {
+		bins[i], min[i], max[i] = d.count(), d.float(), d.float()
+	}
+	outOfRange := d.count()
+	cells := d.count()
+	if d.err != nil {
+		return d.err
+	}
+	decoded, err := NewHistogramND(bins, min, max)
+	if err != nil {
+		return err
+	}
+	decoded.OutOfRange = outOfRange
+	total := 1
+	for _, b := range bins {
+		total *= b
+	}
+	key := 0
+	for i := 0; i < cells && d.err == nil; i++ {
+		delta, count := d.count(), d.count()
+		if (i > 0 && delta == 0) || delta >= total-key || count < 1 {
+			return invalid(ErrInvalidData, "data", "cells must be increasing, within the bins and occupied")
+		}
+		key += delta
+		decoded.Counts[key] = count
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) > 0 {
+		return invalid(ErrInvalidData, "data", "data continues after the histogram")
+	}
+	h.replace(decoded)
+	return nil
+}
+
+// histogramNDJSON is the JSON form of a HistogramND, with the occupied cells
+// by their bin indices.
+type histogramNDJSON struct {
+	Bins       []int        `json:"bins"`
+	Min        []float64    `json:"min"`
+	Max        []float64    `json:"max"`
+	Cells      []cellNDJSON `json:"cells"`
+	OutOfRange int          `json:"out_of_range"`
+}
+
+type cellNDJSON struct {
+	Index []int `json:"index"`
+	Count int   `json:"count"`
+}
+
+// MarshalJSON encodes h as JSON with the occupied cells by their bin
+// indices, ordered by index.
+func (h *HistogramND) MarshalJSON() ([]byte, error) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	v := histogramNDJSON{Bins: h.Bins, Min: h.Min, Max: h.Max, Cells: []cellNDJSON{}, OutOfRange: h.OutOfRange}
+	for _, key := range h.sortedKeys() {
+		index := make([]int, len(h.Bins))
+		for d, rest := len(h.Bins)-1, key; d >= 0; d-- {
+			index[d] = rest % h.Bins[d]
+			rest /= h.Bins[d]
+		}
+		v.Cells = append(v.Cells, cellNDJSON{Index: index, Count: h.Counts[key]})
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON replaces h by the histogram encoded by MarshalJSON. Cells
+// given more than once are added up.
+func (h *HistogramND) UnmarshalJSON(data []byte) error {
+	var v histogramNDJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	decoded, err := NewHistogramND(v.Bins, v.Min, v.Max)
+	if err != nil {
+		return err
+	}
+	if v.OutOfRange < 0 {
+		return invalid(ErrInvalidData, "out_of_range", "out_of_range must not be negative")
+	}
+	decoded.OutOfRange = v.OutOfRange
+	for _, cell := range v.Cells {
+		if len(cell.Index) != len(v.Bins) {
+			return invalid(ErrSizeMismatch, "cells", "every cell must have one index per dimension")
+		}
+		if cell.Count < 0 {
+			return invalid(ErrInvalidData, "cells", "counts must not be negative")
+		}
+		key := 0
+		for d, index := range cell.Index {
+			if index < 0 || index >= v.Bins[d] {
+				return invalid(ErrInvalidData, "cells", "cell indices must lie within the bins")
+			}
+			key = key*v.Bins[d] + index
+		}
+		if cell.Count > 0 {
+			decoded.Counts[key] += cell.Count
+		}
+	}
+	h.replace(decoded)
+	return nil
+}
+
+// Merge adds the counts of other to h, e.g. to combine the partial
+// histograms of separately processed partitions. An error is returned
+// unless both have the same bins and ranges. As in Histogram2D.Merge, the
+// two mutexes are never held at the same time.
+func (h *HistogramND) Merge(other *HistogramND) error {
+	if len(h.Bins) != len(other.Bins) {
+		return invalid(ErrSizeMismatch, "histograms", "histograms must have the same dimensions")
+	}
+	for d := range h.Bins {
+		if h.Bins[d] != other.Bins[d] {
+			return invalid(ErrSizeMismatch, "histograms", "histograms must have the same number of bins")
+		}
+		if h.Min[d] != other.Min[d] || h.Max[d] != other.Max[d] {
+			return invalid(ErrSizeMismatch, "histograms", "histograms must have the same ranges")
+		}
+	}
+	other.Mutex.Lock()
+	counts := make(map[int]int, len(other.Counts))
+	for key, c := range other.Counts {
+		counts[key] = c
+	}
+	outOfRange := other.OutOfRange
+	other.Mutex.Unlock()
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	for key, c := range counts {
+		h.Counts[key] += c
+	}
+	h.OutOfRange += outOfRange
+	return nil
+}
+
+// sortedKeys returns the keys of the occupied cells in increasing order.
+// The caller must hold the mutex.
+func (h *HistogramND) sortedKeys() []int {
+	keys := make([]int, 0, len(h.Counts))
+	for key := range h.Counts {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// replace sets the bins, ranges and counts of h to those of an unshared
+// histogram other.
+func (h *HistogramND) replace(other *HistogramND) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.Bins, h.Min, h.Max = other.Bins, other.Min, other.Max
+	h.Counts, h.OutOfRange = other.Counts, other.OutOfRange
+}
+
+// decoder reads the fields of a binary histogram from data, keeping the
+// first error so that the fields can be read without checking each.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = invalid(ErrInvalidData, "data", "data ends within the histogram")
+	}
+	d.data = nil
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if len(d.data) < n {
+		d.fail()
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) byte() byte {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// count reads a uvarint that must fit an int.
+func (d *decoder) count() int {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 || v > math.MaxInt64 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return int(v)
+}
+
+func (d *decoder) float() float64 {
+	if b := d.bytes(8); b != nil {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) floats(n int) []float64 {
+	if n < 0 || n > len(d.data)/8 {
+		d.fail()
+		return nil
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = d.float()
+	}
+	return values
+}
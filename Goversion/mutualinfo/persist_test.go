@@ -0,0 +1,212 @@
+package mutualinfo
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func sameHistogram2D(t *testing.T, got, want *Histogram2D) {
+	t.Helper()
+	if got.BinsX != want.BinsX || got.BinsY != want.BinsY ||
+		got.MinX != want.MinX || got.MaxX != want.MaxX || got.MinY != want.MinY || got.MaxY != want.MaxY {
+		t.Fatalf("bins or ranges differ: %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(got.Data, want.Data) || !reflect.DeepEqual(got.WeightedData, want.WeightedData) {
+		t.Error("counts or weights differ")
+	}
+	if !reflect.DeepEqual(got.EdgesX, want.EdgesX) || !reflect.DeepEqual(got.EdgesY, want.EdgesY) {
+		t.Error("edges differ")
+	}
+	if got.OutOfRange != want.OutOfRange || got.Missing != want.Missing || got.Outside != want.Outside {
+		t.Errorf("out of range %d/%d %+v, want %d/%d %+v", got.OutOfRange, got.Missing, got.Outside, want.OutOfRange, want.Missing, want.Outside)
+	}
+}
+
+func TestHistogram2DPersistence(t *testing.T) {
+	rng := rand.New(rand.NewSource(542))
+	plain := NewHistogram2D(5, 4, 0, 1, -1, 1)
+	weighted := NewHistogram2D(3, 3, 0, 1, 0, 1)
+	edged, _ := NewHistogram2DEdges([]float64{0, 0.1, 0.5, 1}, []float64{0, 0.3, 1})
+	for i := 0; i < 500; i++ {
+		x := rng.Float64()
+		plain.Increment(x, x-rng.Float64())
+		weighted.IncrementWeighted(x, rng.Float64(), rng.Float64())
+		edged.Increment(x, x*x)
+	}
+	plain.Increment(2, 0)
+	plain.Increment(math.NaN(), 0)
+
+	for name, hist := range map[string]*Histogram2D{"plain": plain, "weighted": weighted, "edges": edged} {
+		data, err := hist.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromBinary Histogram2D
+		if err := fromBinary.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sameHistogram2D(t, &fromBinary, hist)
+		if got, want := fromBinary.CalculateMutualInformation(), hist.CalculateMutualInformation(); got != want {
+			t.Errorf("%s: MI %v after decoding, want %v", name, got, want)
+		}
+
+		text, err := json.Marshal(hist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromJSON Histogram2D
+		if err := json.Unmarshal(text, &fromJSON); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sameHistogram2D(t, &fromJSON, hist)
+
+		// Every truncation is an error, not a panic.
+		for n := 0; n < len(data); n++ {
+			var h Histogram2D
+			if err := h.UnmarshalBinary(data[:n]); !errors.Is(err, ErrInvalidData) {
+				t.Fatalf("%s: truncated to %d bytes: %v, want ErrInvalidData", name, n, err)
+			}
+		}
+	}
+
+	// A checkpoint resumed and merged with another partition gives the
+	// histogram of all data.
+	data, _ := plain.MarshalBinary()
+	var resumed Histogram2D
+	resumed.UnmarshalBinary(data)
+	other := NewHistogram2D(5, 4, 0, 1, -1, 1)
+	for i := 0; i < 100; i++ {
+		x := rng.Float64()
+		resumed.Increment(x, -x)
+		other.Increment(x, -x)
+	}
+	plain.Merge(other)
+	sameHistogram2D(t, &resumed, plain)
+
+	var h Histogram2D
+	if err := h.UnmarshalJSON([]byte(`{"min_x":0,"max_x":1,"min_y":0,"max_y":1,"counts":[[1,2],[3]]}`)); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("ragged counts: got %v", err)
+	}
+	if err := h.UnmarshalJSON([]byte(`{"min_x":1,"max_x":0,"min_y":0,"max_y":1,"counts":[[1]]}`)); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("inverted range: got %v", err)
+	}
+	if err := h.UnmarshalJSON([]byte(`{"min_x":0,"max_x":1,"min_y":0,"max_y":1,"counts":[[-1]]}`)); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("negative count: got %v", err)
+	}
+	if err := h.UnmarshalBinary(append(data, 0)); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("trailing data: got %v", err)
+	}
+
+	// Bins of MaxInt64 with edges, whose count binsX+1 overflows.
+	crafted := append([]byte(histogram2DMagic), histogramVersion, flagEdgesX)
+	crafted = binary.AppendUvarint(crafted, math.MaxInt64)
+	crafted = binary.AppendUvarint(crafted, 1)
+	crafted = append(crafted, make([]byte, 32)...)
+	if err := h.UnmarshalBinary(crafted); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("overflowing bins: got %v", err)
+	}
+}
+
+func FuzzHistogram2DUnmarshalBinary(f *testing.F) {
+	hist := NewHistogram2D(3, 2, 0, 1, 0, 1)
+	hist.IncrementWeighted(0.5, 0.5, 2)
+	hist.Increment(2, 0)
+	data, _ := hist.MarshalBinary()
+	f.Add(data)
+	edged, _ := NewHistogram2DEdges([]float64{0, 0.5, 1}, []float64{0, 1})
+	data, _ = edged.MarshalBinary()
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var h Histogram2D
+		if err := h.UnmarshalBinary(data); err != nil {
+			return
+		}
+		// What decodes must survive another round trip unchanged.
+		encoded, _ := h.MarshalBinary()
+		var again Histogram2D
+		if err := again.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("%x decodes but its encoding %x does not: %v", data, encoded, err)
+		}
+		if reencoded, _ := again.MarshalBinary(); string(reencoded) != string(encoded) {
+			t.Errorf("encoding %x changes to %x in a round trip", encoded, reencoded)
+		}
+	})
+}
+
+func FuzzHistogramNDUnmarshalBinary(f *testing.F) {
+	hist, _ := NewHistogramND([]int{3, 2}, []float64{0, 0}, []float64{1, 1})
+	hist.Increment([]float64{0.5, 0.5})
+	data, _ := hist.MarshalBinary()
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var h HistogramND
+		h.UnmarshalBinary(data)
+	})
+}
+
+func TestHistogramNDPersistence(t *testing.T) {
+	rng := rand.New(rand.NewSource(542))
+	bins, min, max := []int{4, 3, 5}, []float64{0, 0, 0}, []float64{1, 1, 1}
+	whole, _ := NewHistogramND(bins, min, max)
+	first, _ := NewHistogramND(bins, min, max)
+	second, _ := NewHistogramND(bins, min, max)
+	for i := 0; i < 300; i++ {
+		x := rng.Float64()
+		point := []float64{x, rng.Float64(), x * x}
+		if i%50 == 0 {
+			point[1] = 3
+		}
+		whole.Increment(point)
+		if i < 120 {
+			first.Increment(point)
+		} else {
+			second.Increment(point)
+		}
+	}
+
+	data, err := first.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBinary HistogramND
+	if err := fromBinary.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	text, err := json.Marshal(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON HistogramND
+	if err := json.Unmarshal(text, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := fromBinary.Merge(&fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromBinary.Counts, whole.Counts) || fromBinary.OutOfRange != whole.OutOfRange {
+		t.Error("merged partitions differ from the histogram of all points")
+	}
+	if got, want := fromBinary.Entropy(0, 2), whole.Entropy(0, 2); !almostEqual(got, want, 1e-12) {
+		t.Errorf("entropy %v, want %v", got, want)
+	}
+
+	for n := 0; n < len(data); n++ {
+		var h HistogramND
+		if err := h.UnmarshalBinary(data[:n]); !errors.Is(err, ErrInvalidData) {
+			t.Fatalf("truncated to %d bytes: %v, want ErrInvalidData", n, err)
+		}
+	}
+	other, _ := NewHistogramND([]int{4, 3, 6}, min, max)
+	if err := whole.Merge(other); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("merge with other bins: got %v", err)
+	}
+	var h HistogramND
+	if err := h.UnmarshalJSON([]byte(`{"bins":[2],"min":[0],"max":[1],"cells":[{"index":[2],"count":1}]}`)); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("index outside the bins: got %v", err)
+	}
+}
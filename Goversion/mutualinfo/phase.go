@@ -0,0 +1,60 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// WrapPhase maps an angle in radians onto the interval [-π, π).
+func WrapPhase(angle float64) float64 {
+	wrapped := math.Atan2(math.Sin(angle), math.Cos(angle))
+	if wrapped >= math.Pi {
+		wrapped = -math.Pi
+	}
+	return wrapped
+}
+
+// PhaseDifference returns the circular difference phaseX[i] - phaseY[i]
+// wrapped onto [-π, π). Like copy, it only covers the common length of both inputs.
+func PhaseDifference(phaseX, phaseY []float64) []float64 {
+	n := len(phaseX)
+	if len(phaseY) < n {
+		n = len(phaseY)
+	}
+	diff := make([]float64, n)
+	for i := 0; i < n; i++ {
+		diff[i] = WrapPhase(phaseX[i] - phaseY[i])
+	}
+	return diff
+}
+
+// CircularMutualInformation calculates the mutual information between a
+// circular variable phaseX (in radians, any winding) and a linear variable dataY.
+// The phases are wrapped onto [-π, π) before binning so that angles
+// on either side of ±π share neighbouring bins instead of being clamped.
+func CircularMutualInformation(binsX, binsY int, minY, maxY float64, phaseX, dataY []float64) (float64, error) {
+	if err := validate2D(binsX, binsY, -math.Pi, math.Pi, minY, maxY, nil, nil); err != nil {
+		return 0, err
+	}
+	if len(phaseX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "phaseX", "phaseX and dataY must have the same size")
+	}
+
+	hist := NewHistogram2D(binsX, binsY, -math.Pi, math.Pi, minY, maxY)
+	for i := range phaseX {
+		if dataY[i] < minY || dataY[i] > maxY {
+			continue
+		}
+		hist.Increment(WrapPhase(phaseX[i]), dataY[i])
+	}
+	return hist.CalculateMutualInformation(), nil
+}
+
+// PhaseLockingMutualInformation calculates the mutual information between the
+// phase difference of phaseX and phaseY and a reference signal. The difference
+// is binned circularly into binsX bins over [-π, π).
+func PhaseLockingMutualInformation(binsX, binsY int, minRef, maxRef float64, phaseX, phaseY, reference []float64) (float64, error) {
+	if len(phaseX) != len(phaseY) {
+		return 0, invalid(ErrSizeMismatch, "phaseX", "phaseX and phaseY must have the same size")
+	}
+	return CircularMutualInformation(binsX, binsY, minRef, maxRef, PhaseDifference(phaseX, phaseY), reference)
+}
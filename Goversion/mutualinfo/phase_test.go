@@ -0,0 +1,49 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWrapPhase(t *testing.T) {
+	cases := []struct{ in, want float64 }{
+		{0, 0},
+		{math.Pi, -math.Pi},
+		{-math.Pi, -math.Pi},
+		{3 * math.Pi / 2, -math.Pi / 2},
+		{-5 * math.Pi / 2, -math.Pi / 2},
+	}
+	for _, c := range cases {
+		if got := WrapPhase(c.in); !almostEqual(got, c.want, 1e-12) {
+			t.Errorf("WrapPhase(%v) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestPhaseDifference(t *testing.T) {
+	diff := PhaseDifference([]float64{0.1, math.Pi - 0.1}, []float64{2*math.Pi - 0.1, -math.Pi + 0.1})
+	want := []float64{0.2, -0.2}
+	for i := range want {
+		if !almostEqual(diff[i], want[i], 1e-12) {
+			t.Errorf("diff[%d] = %v, want %v", i, diff[i], want[i])
+		}
+	}
+}
+
+func TestCircularMutualInformation(t *testing.T) {
+	n := 4000
+	phase := make([]float64, n)
+	ref := make([]float64, n)
+	for i := range phase {
+		ref[i] = float64(i%8) / 8
+		// Add full windings so that only the wrapped value carries information.
+		phase[i] = -math.Pi + (float64(i%8)+0.5)*2*math.Pi/8 + float64(i%3)*2*math.Pi
+	}
+	mi, err := CircularMutualInformation(8, 8, 0, 1, phase, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(mi, 3, 1e-9) {
+		t.Errorf("expected 3 bits for a deterministic relationship, got %v", mi)
+	}
+}
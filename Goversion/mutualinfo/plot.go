@@ -0,0 +1,323 @@
+package mutualinfo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+// The margins in pixels around the plot area of WriteSVG and WritePNG leave
+// room for the tick and axis labels.
+const (
+	plotMarginLeft   = 70
+	plotMarginRight  = 20
+	plotMarginTop    = 30
+	plotMarginBottom = 50
+)
+
+// curveLayout maps the shifts and MI of a Result to pixel coordinates.
+type curveLayout struct {
+	r                      Result
+	width, height          int
+	minX, maxX, minY, maxY float64
+	ticksX, ticksY         []float64
+}
+
+func newCurveLayout(r Result, width, height int) (curveLayout, error) {
+	if len(r.Shifts) == 0 || len(r.Shifts) != len(r.MI) {
+		return curveLayout{}, invalid(ErrTooFewSamples, "r", "result must hold one MI per shift")
+	}
+	if width <= plotMarginLeft+plotMarginRight || height <= plotMarginTop+plotMarginBottom {
+		return curveLayout{}, invalid(ErrInvalidParameter, "width", "width and height leave no room for the plot")
+	}
+	l := curveLayout{r: r, width: width, height: height}
+	l.minX, l.maxX = float64(r.Shifts[0]), float64(r.Shifts[len(r.Shifts)-1])
+	if l.minX == l.maxX {
+		l.minX, l.maxX = l.minX-1, l.maxX+1
+	}
+	for _, mi := range r.MI {
+		if isFinite(mi) {
+			l.maxY = math.Max(l.maxY, mi)
+		}
+	}
+	if l.maxY == 0 {
+		l.maxY = 1
+	}
+	l.ticksX = niceTicks(l.minX, l.maxX, 8)
+	l.ticksY = niceTicks(0, l.maxY, 5)
+	// Round the MI axis up to the next tick so that the curve stays inside.
+	if len(l.ticksY) > 1 {
+		step := l.ticksY[1] - l.ticksY[0]
+		if last := l.ticksY[len(l.ticksY)-1]; last < l.maxY {
+			l.ticksY = append(l.ticksY, last+step)
+		}
+		l.maxY = l.ticksY[len(l.ticksY)-1]
+	}
+	return l, nil
+}
+
+func (l curveLayout) x(shift float64) float64 {
+	return plotMarginLeft + (shift-l.minX)/(l.maxX-l.minX)*float64(l.width-plotMarginLeft-plotMarginRight)
+}
+
+func (l curveLayout) y(mi float64) float64 {
+	return float64(l.height-plotMarginBottom) - (mi-l.minY)/(l.maxY-l.minY)*float64(l.height-plotMarginTop-plotMarginBottom)
+}
+
+// segments returns the runs of shifts with a finite MI, which are drawn as
+// connected lines.
+func (l curveLayout) segments() [][]int {
+	var segments [][]int
+	var current []int
+	for i, mi := range l.r.MI {
+		if !isFinite(mi) {
+			if len(current) > 0 {
+				segments = append(segments, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, i)
+	}
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+	return segments
+}
+
+// niceTicks returns about n tick positions covering [min, max] at a step
+// of 1, 2 or 5 times a power of ten.
+func niceTicks(min, max float64, n int) []float64 {
+	raw := (max - min) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, f := range []float64{2, 5, 10} {
+		if raw/magnitude > f/1.5 {
+			step = f * magnitude
+		}
+	}
+	var ticks []float64
+	for t := math.Ceil(min/step) * step; t <= max+step*1e-9; t += step {
+		// Avoid printing -0 and accumulated rounding errors.
+		ticks = append(ticks, math.Round(t/step)*step+0)
+	}
+	return ticks
+}
+
+// curveLabel returns the label of the MI axis.
+func curveLabel(r Result) string {
+	unit := r.Settings.Unit
+	if unit == "" {
+		unit = UnitBits.String()
+	}
+	if n := r.Settings.Normalization; n != "" && n != NormalizationNone.String() {
+		return "normalized MI (" + n + ")"
+	}
+	return "MI (" + unit + ")"
+}
+
+func formatTick(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// WriteSVG renders the MI of r over its shifts as an SVG image of width x
+// height pixels, with the peak marked. Shifts with a NaN MI leave gaps.
+func WriteSVG(w io.Writer, r Result, width, height int) error {
+	l, err := newCurveLayout(r, width, height)
+	if err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	left, right := float64(plotMarginLeft), float64(width-plotMarginRight)
+	top, bottom := float64(plotMarginTop), float64(height-plotMarginBottom)
+	for _, t := range l.ticksY {
+		y := l.y(t)
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", left, y, right, y)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n", left-6, y, formatTick(t))
+	}
+	for _, t := range l.ticksX {
+		x := l.x(t)
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="black"/>`+"\n", x, bottom, x, bottom+5)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", x, bottom+18, formatTick(t))
+	}
+	fmt.Fprintf(b, `<path d="M%.1f %.1fV%.1fH%.1f" fill="none" stroke="black"/>`+"\n", left, top, bottom, right)
+	fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle">shift</text>`+"\n", (left+right)/2, height-10)
+	fmt.Fprintf(b, `<text transform="translate(16 %.1f) rotate(-90)" text-anchor="middle">%s</text>`+"\n", (top+bottom)/2, html.EscapeString(curveLabel(r)))
+	for _, segment := range l.segments() {
+		b.WriteString(`<polyline fill="none" stroke="#1f77b4" stroke-width="1.5" points="`)
+		for k, i := range segment {
+			if k > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(b, "%.1f,%.1f", l.x(float64(r.Shifts[i])), l.y(r.MI[i]))
+		}
+		b.WriteString(`"/>` + "\n")
+	}
+	if isFinite(r.PeakMI) {
+		x, y := l.x(float64(r.PeakShift)), l.y(r.PeakMI)
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="4" fill="#d62728"/>`+"\n", x, y)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">peak at %d: %s</text>`+"\n", x, top-10, r.PeakShift, formatTick(r.PeakMI))
+	}
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+// WritePNG renders the curve of WriteSVG as a PNG image. The standard
+// library has no fonts, so the image has tick marks but no labels; use
+// WriteSVG for a labelled plot.
+func WritePNG(w io.Writer, r Result, width, height int) error {
+	l, err := newCurveLayout(r, width, height)
+	if err != nil {
+		return err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	black := color.RGBA{A: 0xff}
+	grid := color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	blue := color.RGBA{0x1f, 0x77, 0xb4, 0xff}
+	red := color.RGBA{0xd6, 0x27, 0x28, 0xff}
+	left, right := float64(plotMarginLeft), float64(width-plotMarginRight)
+	top, bottom := float64(plotMarginTop), float64(height-plotMarginBottom)
+	for _, t := range l.ticksY {
+		drawLine(img, left, l.y(t), right, l.y(t), grid)
+		drawLine(img, left-5, l.y(t), left, l.y(t), black)
+	}
+	for _, t := range l.ticksX {
+		drawLine(img, l.x(t), bottom, l.x(t), bottom+5, black)
+	}
+	drawLine(img, left, top, left, bottom, black)
+	drawLine(img, left, bottom, right, bottom, black)
+	for _, segment := range l.segments() {
+		for k := 1; k < len(segment); k++ {
+			i, j := segment[k-1], segment[k]
+			drawLine(img, l.x(float64(r.Shifts[i])), l.y(r.MI[i]), l.x(float64(r.Shifts[j])), l.y(r.MI[j]), blue)
+		}
+		if len(segment) == 1 {
+			i := segment[0]
+			img.Set(int(math.Round(l.x(float64(r.Shifts[i])))), int(math.Round(l.y(r.MI[i]))), blue)
+		}
+	}
+	if isFinite(r.PeakMI) {
+		cx, cy := int(math.Round(l.x(float64(r.PeakShift)))), int(math.Round(l.y(r.PeakMI)))
+		for dy := -3; dy <= 3; dy++ {
+			for dx := -3; dx <= 3; dx++ {
+				if dx*dx+dy*dy <= 10 {
+					img.Set(cx+dx, cy+dy, red)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// drawLine draws the line from (x0, y0) to (x1, y1) with Bresenham's
+// algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	ax, ay := int(math.Round(x0)), int(math.Round(y0))
+	bx, by := int(math.Round(x1)), int(math.Round(y1))
+	dx, dy := abs(bx-ax), -abs(by-ay)
+	sx, sy := 1, 1
+	if ax > bx {
+		sx = -1
+	}
+	if ay > by {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(ax, ay, c)
+		if ax == bx && ay == by {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			ax += sx
+		}
+		if e2 <= dx {
+			e += dx
+			ay += sy
+		}
+	}
+}
+
+// WriteGnuplot writes the curve of r as whitespace-separated columns shift,
+// mi, hx, hy, hxy and pairs under a # header, e.g. for
+// plot "curve.dat" using 1:2 with lines. NaN is written as NaN, which
+// gnuplot skips.
+func WriteGnuplot(w io.Writer, r Result) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# shift mi hx hy hxy pairs, %s, peak at shift %d\n", curveLabel(r), r.PeakShift)
+	for i, shift := range r.Shifts {
+		fmt.Fprintf(b, "%d %s %s %s %s %d\n", shift, formatValue(r.MI, i), formatValue(r.HX, i), formatValue(r.HY, i), formatValue(r.HXY, i), valueAt(r.Pairs, i))
+	}
+	return b.Flush()
+}
+
+func formatValue(values []float64, i int) string {
+	if i >= len(values) {
+		return "NaN"
+	}
+	return strconv.FormatFloat(values[i], 'g', -1, 64)
+}
+
+func valueAt(values []int, i int) int {
+	if i >= len(values) {
+		return 0
+	}
+	return values[i]
+}
+
+// WriteVegaLite writes a Vega-Lite specification of the curve of r with
+// the data inline, which renders with vega-embed or the online editor.
+// Shifts with a NaN MI are left out.
+func WriteVegaLite(w io.Writer, r Result) error {
+	type point struct {
+		Shift int     `json:"shift"`
+		MI    float64 `json:"mi"`
+		Pairs int     `json:"pairs"`
+	}
+	values := []point{}
+	for i, shift := range r.Shifts {
+		if i < len(r.MI) && isFinite(r.MI[i]) {
+			values = append(values, point{shift, r.MI[i], valueAt(r.Pairs, i)})
+		}
+	}
+	label := curveLabel(r)
+	spec := map[string]any{
+		"$schema": "https://vega.github.io/schema/vega-lite/v5.json",
+		"data":    map[string]any{"values": values},
+		"layer": []any{
+			map[string]any{
+				"mark": "line",
+				"encoding": map[string]any{
+					"x":       map[string]any{"field": "shift", "type": "quantitative", "title": "shift"},
+					"y":       map[string]any{"field": "mi", "type": "quantitative", "title": label},
+					"tooltip": []any{map[string]any{"field": "shift"}, map[string]any{"field": "mi"}, map[string]any{"field": "pairs"}},
+				},
+			},
+			map[string]any{
+				"mark":      map[string]any{"type": "point", "filled": true, "color": "#d62728"},
+				"transform": []any{map[string]any{"filter": fmt.Sprintf("datum.shift == %d", r.PeakShift)}},
+				"encoding": map[string]any{
+					"x": map[string]any{"field": "shift", "type": "quantitative"},
+					"y": map[string]any{"field": "mi", "type": "quantitative"},
+				},
+			},
+		},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(spec)
+}
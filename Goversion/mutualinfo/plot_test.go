@@ -0,0 +1,129 @@
+package mutualinfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func plotResult(t *testing.T) Result {
+	t.Helper()
+	dataX := make([]float64, 400)
+	dataY := make([]float64, len(dataX))
+	for i := range dataX {
+		dataX[i] = math.Sin(float64(i) * 0.37)
+		dataY[i] = dataX[(i+len(dataX)-3)%len(dataX)]
+	}
+	result, err := ShiftedMutualInformationResult(-10, 10, 8, 8, -1, 1, -1, 1, dataX, dataY, 1, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.MI[5] = math.NaN()
+	return result
+}
+
+func TestWriteSVG(t *testing.T) {
+	result := plotResult(t)
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, result, 640, 400); err != nil {
+		t.Fatal(err)
+	}
+	decoder := xml.NewDecoder(&buf)
+	polylines, peak := 0, false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "polyline" {
+			polylines++
+		}
+		if text, ok := token.(xml.CharData); ok && strings.HasPrefix(string(text), "peak at -3:") {
+			peak = true
+		}
+	}
+	// The NaN splits the curve in two.
+	if polylines != 2 || !peak {
+		t.Errorf("got %d polylines and peak label %v, want 2 and true", polylines, peak)
+	}
+	if err := WriteSVG(&buf, result, 50, 50); err == nil {
+		t.Error("expected error for a size without room for the plot")
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, plotResult(t), 320, 200); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 320 || size.Y != 200 {
+		t.Errorf("got size %v, want 320x200", size)
+	}
+}
+
+func TestWriteVegaLiteAndGnuplot(t *testing.T) {
+	result := plotResult(t)
+	var buf bytes.Buffer
+	if err := WriteVegaLite(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Data struct {
+			Values []struct {
+				Shift int     `json:"shift"`
+				MI    float64 `json:"mi"`
+			} `json:"values"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Data.Values) != 20 {
+		t.Errorf("got %d points, want the 20 finite ones", len(spec.Data.Values))
+	}
+
+	buf.Reset()
+	if err := WriteGnuplot(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 22 || !strings.HasPrefix(lines[0], "#") || !strings.HasPrefix(lines[6], "-5 NaN ") {
+		t.Errorf("unexpected gnuplot data:\n%s", buf.String())
+	}
+}
+
+func TestNiceTicks(t *testing.T) {
+	for _, c := range []struct {
+		min, max float64
+		n        int
+		want     []float64
+	}{
+		{-10, 10, 8, []float64{-10, -8, -6, -4, -2, 0, 2, 4, 6, 8, 10}},
+		{0, 0.83, 5, []float64{0, 0.2, 0.4, 0.6, 0.8}},
+		{-3, 250, 5, []float64{0, 50, 100, 150, 200, 250}},
+	} {
+		got := niceTicks(c.min, c.max, c.n)
+		if len(got) != len(c.want) {
+			t.Errorf("niceTicks(%v, %v) = %v, want %v", c.min, c.max, got, c.want)
+			continue
+		}
+		for i := range got {
+			if !almostEqual(got[i], c.want[i], 1e-12) {
+				t.Errorf("niceTicks(%v, %v) = %v, want %v", c.min, c.max, got, c.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package mutualinfo
+
+import "math"
+
+// Preprocess selects a transform applied to each series before estimating.
+type Preprocess int
+
+const (
+	// PreprocessNone uses the data as given.
+	PreprocessNone Preprocess = iota
+	// PreprocessCopula replaces every value by its rank divided by n+1, the
+	// empirical copula, so that both marginals are uniform on (0, 1). The
+	// MI is invariant under monotone transforms but its estimates are not;
+	// on uniform marginals skewed or heavy-tailed data no longer crowds a
+	// few bins. Ties share their mean rank and NaN stays NaN.
+	PreprocessCopula
+)
+
+func (p Preprocess) String() string {
+	switch p {
+	case PreprocessNone:
+		return "none"
+	case PreprocessCopula:
+		return "copula"
+	}
+	return "unknown"
+}
+
+func (p Preprocess) valid() bool {
+	return p >= PreprocessNone && p <= PreprocessCopula
+}
+
+// apply returns data transformed by p, which is data itself for
+// PreprocessNone.
+func (p Preprocess) apply(data []float64) []float64 {
+	if p != PreprocessCopula {
+		return data
+	}
+	return CopulaTransform(data)
+}
+
+// CopulaTransform returns the ranks of data divided by n+1, see
+// PreprocessCopula, where n is the number of values other than NaN.
+func CopulaTransform(data []float64) []float64 {
+	values := make([]float64, 0, len(data))
+	for _, v := range data {
+		if !math.IsNaN(v) {
+			values = append(values, v)
+		}
+	}
+	r := ranks(values)
+	n := float64(len(values))
+	u := make([]float64, len(data))
+	k := 0
+	for i, v := range data {
+		if math.IsNaN(v) {
+			u[i] = math.NaN()
+			continue
+		}
+		u[i] = r[k] / (n + 1)
+		k++
+	}
+	return u
+}
@@ -0,0 +1,58 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCopulaTransform(t *testing.T) {
+	got := CopulaTransform([]float64{10, math.NaN(), -1, 10, 3})
+	want := []float64{3.5 / 5, math.NaN(), 1.0 / 5, 3.5 / 5, 2.0 / 5}
+	for i := range want {
+		if got[i] != want[i] && !(math.IsNaN(got[i]) && math.IsNaN(want[i])) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPreprocessCopula(t *testing.T) {
+	rng := rand.New(rand.NewSource(20))
+	dataX := make([]float64, 2000)
+	dataY := make([]float64, len(dataX))
+	expX := make([]float64, len(dataX))
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+		dataY[i] = dataX[i] + 0.5*rng.NormFloat64()
+		// A monotone transform that crowds most values into a few bins.
+		expX[i] = math.Exp(3 * dataX[i])
+	}
+	opts := ShiftOptions{Preprocess: PreprocessCopula}
+	plain, err := ShiftedMutualInformationResult(0, 0, 10, 10, 0, 1, 0, 1, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skewed, err := ShiftedMutualInformationResult(0, 0, 10, 10, 0, 1, 0, 1, expX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With uniform marginals the estimate no longer depends on the transform.
+	if plain.MI[0] != skewed.MI[0] || plain.MI[0] < 0.5 {
+		t.Errorf("got %v and %v for the plain and skewed data", plain.MI[0], skewed.MI[0])
+	}
+	if plain.Settings.Preprocess != "copula" || plain.Settings.MaxX != 1 {
+		t.Errorf("unexpected settings %+v", plain.Settings)
+	}
+
+	ksgPlain, err := KSGMutualInformationWithOptions(dataX, dataY, 4, KSGOptions{Preprocess: PreprocessCopula})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ksgSkewed, err := KSGMutualInformationWithOptions(expX, dataY, 4, KSGOptions{Preprocess: PreprocessCopula})
+	if err != nil || ksgPlain != ksgSkewed {
+		t.Errorf("KSG gives %v and %v, %v", ksgPlain, ksgSkewed, err)
+	}
+	if _, err := ShiftedMutualInformationWithOptions(0, 0, 10, 10, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Preprocess: -1}); err == nil {
+		t.Error("expected error for an unknown preprocessing")
+	}
+}
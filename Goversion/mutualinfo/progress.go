@@ -0,0 +1,27 @@
+package mutualinfo
+
+import "sync"
+
+// ProgressFunc receives the progress of a long computation, e.g. to print a
+// progress bar or estimate the remaining time: done of total units, such as
+// shifts or resamples, are complete. Calls are serialized, done increases by
+// one from call to call and, unless the computation is cancelled, the last
+// call has done == total. It is called from the worker goroutines, so it
+// should return quickly.
+type ProgressFunc func(done, total int)
+
+// progressTicker returns a function that reports one more completed unit of
+// total to progress, or does nothing if progress is nil.
+func progressTicker(progress ProgressFunc, total int) func() {
+	if progress == nil {
+		return func() {}
+	}
+	var mutex sync.Mutex
+	done := 0
+	return func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		done++
+		progress(done, total)
+	}
+}
@@ -0,0 +1,60 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// recordProgress returns a ProgressFunc checking that done counts up to
+// total, and a pointer to the last done seen.
+func recordProgress(t *testing.T, total int) (ProgressFunc, *int) {
+	last := new(int)
+	return func(done, gotTotal int) {
+		if gotTotal != total || done != *last+1 {
+			t.Errorf("got progress %d/%d after %d, want %d/%d", done, gotTotal, *last, *last+1, total)
+		}
+		*last = done
+	}, last
+}
+
+func TestProgress(t *testing.T) {
+	rng := rand.New(rand.NewSource(19))
+	dataX := make([]float64, 200)
+	dataY := make([]float64, len(dataX))
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+
+	progress, last := recordProgress(t, 21)
+	if _, err := ShiftedMutualInformationWithOptions(-10, 10, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Workers: 4, Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+	if *last != 21 {
+		t.Errorf("shifts: progress ended at %d, want 21", *last)
+	}
+
+	progress, last = recordProgress(t, 7)
+	if _, err := BootstrapShiftedMutualInformationWithProgress(-2, 2, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, 7, 0.9, 1, progress); err != nil {
+		t.Fatal(err)
+	}
+	if *last != 7 {
+		t.Errorf("bootstrap: progress ended at %d, want 7", *last)
+	}
+
+	progress, last = recordProgress(t, 5)
+	if _, err := SignificanceTestWithProgress(-2, 2, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, 5, 1, ShuffleSurrogate, progress); err != nil {
+		t.Fatal(err)
+	}
+	if *last != 5 {
+		t.Errorf("significance: progress ended at %d, want 5", *last)
+	}
+
+	progress, last = recordProgress(t, 6)
+	if _, err := MutualInformationMatrix([][]float64{dataX, dataY, dataX}, 8, MatrixOptions{Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+	if *last != 6 {
+		t.Errorf("matrix: progress ended at %d, want 6", *last)
+	}
+}
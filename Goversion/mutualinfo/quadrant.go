@@ -0,0 +1,74 @@
+package mutualinfo
+
+import "math"
+
+// Quadrant holds the joint probability mass of one quadrant of a histogram
+// and the sum of the pointwise mutual information contributions
+// p(x,y)*log2(p(x,y)/(p(x)p(y))) of its cells.
+type Quadrant struct {
+	Mass           float64
+	MIContribution float64
+}
+
+// Quadrants splits a histogram at the median bins of both marginals.
+// "Lower" refers to bins up to and including the median bin.
+type Quadrants struct {
+	LowerLeft  Quadrant // low X, low Y
+	LowerRight Quadrant // high X, low Y
+	UpperLeft  Quadrant // low X, high Y
+	UpperRight Quadrant // high X, high Y
+}
+
+// medianBin returns the first bin at which the cumulative count reaches half of total.
+func medianBin(counts []int, total int) int {
+	cumulative := 0
+	for i, c := range counts {
+		cumulative += c
+		if 2*cumulative >= total {
+			return i
+		}
+	}
+	return len(counts) - 1
+}
+
+// Quadrants returns the probability mass and MI contribution of the four
+// quadrants around the medians of X and Y. Concentration in the lower-left
+// and upper-right quadrants indicates positive association, concentration
+// off the diagonal negative association. The contributions sum to the MI.
+func (h *Histogram2D) Quadrants() Quadrants {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	var q Quadrants
+	rows, cols, total := h.marginalCounts()
+	if total == 0 {
+		return q
+	}
+	medianX := medianBin(rows, total)
+	medianY := medianBin(cols, total)
+
+	for i := 0; i < h.BinsX; i++ {
+		for j := 0; j < h.BinsY; j++ {
+			if h.Data[i][j] == 0 {
+				continue
+			}
+			var quad *Quadrant
+			switch {
+			case i <= medianX && j <= medianY:
+				quad = &q.LowerLeft
+			case i > medianX && j <= medianY:
+				quad = &q.LowerRight
+			case i <= medianX:
+				quad = &q.UpperLeft
+			default:
+				quad = &q.UpperRight
+			}
+			p := float64(h.Data[i][j]) / float64(total)
+			px := float64(rows[i]) / float64(total)
+			py := float64(cols[j]) / float64(total)
+			quad.Mass += p
+			quad.MIContribution += p * math.Log2(p/(px*py))
+		}
+	}
+	return q
+}
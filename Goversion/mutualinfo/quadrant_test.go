@@ -0,0 +1,22 @@
+package mutualinfo
+
+import "testing"
+
+func TestQuadrants(t *testing.T) {
+	hist := NewHistogram2D(4, 4, 0, 4, 0, 4)
+	for i := 0; i < 100; i++ {
+		v := float64(i%4) + 0.5
+		hist.Increment(v, v)
+	}
+	q := hist.Quadrants()
+	if !almostEqual(q.LowerLeft.Mass, 0.5, 1e-12) || !almostEqual(q.UpperRight.Mass, 0.5, 1e-12) {
+		t.Errorf("expected diagonal mass, got %+v", q)
+	}
+	if q.LowerRight.Mass != 0 || q.UpperLeft.Mass != 0 {
+		t.Errorf("expected empty off-diagonal quadrants, got %+v", q)
+	}
+	sum := q.LowerLeft.MIContribution + q.LowerRight.MIContribution + q.UpperLeft.MIContribution + q.UpperRight.MIContribution
+	if mi := hist.CalculateMutualInformation(); !almostEqual(sum, mi, 1e-12) {
+		t.Errorf("contributions sum to %v, MI is %v", sum, mi)
+	}
+}
@@ -0,0 +1,129 @@
+package mutualinfo
+
+import (
+	"math"
+	"sort"
+)
+
+// groupedMutualInformation calculates the mutual information separately for
+// the samples of every group in 0..numGroups-1 and returns it together
+// with the number of in-range pairs per group. Groups without pairs have MI 0.
+func groupedMutualInformation(dataX, dataY []float64, group []int, numGroups, bins int, minX, maxX, minY, maxY float64) ([]float64, []int, error) {
+	if err := validate2D(bins, bins, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return nil, nil, err
+	}
+	if len(group) != len(dataX) {
+		return nil, nil, invalid(ErrSizeMismatch, "groups", "group labels and data must have the same size")
+	}
+	hists := make([]*Histogram2D, numGroups)
+	for g := range hists {
+		hists[g] = NewHistogram2D(bins, bins, minX, maxX, minY, maxY)
+	}
+	for i, g := range group {
+		if g < 0 || g >= numGroups {
+			return nil, nil, invalid(ErrInvalidData, "groups", "group labels must be in 0..numGroups-1")
+		}
+		if dataX[i] < minX || dataX[i] > maxX || dataY[i] < minY || dataY[i] > maxY {
+			continue
+		}
+		hists[g].Increment(dataX[i], dataY[i])
+	}
+
+	mi := make([]float64, numGroups)
+	counts := make([]int, numGroups)
+	for g, hist := range hists {
+		_, _, counts[g] = hist.marginalCounts()
+		if counts[g] > 0 {
+			mi[g] = hist.CalculateMutualInformation()
+		}
+	}
+	return mi, counts, nil
+}
+
+// RegimeConditionalMutualInformation calculates I(X;Y | regime) for a binary
+// regime indicator (0 or 1): the MI within each regime weighted by the
+// fraction of pairs in that regime. The per-regime values are returned too
+// so that the coupling of both states can be compared.
+func RegimeConditionalMutualInformation(dataX, dataY []float64, regime []int, bins int, minX, maxX, minY, maxY float64) (cmi float64, perRegime [2]float64, err error) {
+	mi, counts, err := groupedMutualInformation(dataX, dataY, regime, 2, bins, minX, maxX, minY, maxY)
+	if err != nil {
+		return 0, perRegime, err
+	}
+	total := counts[0] + counts[1]
+	if total == 0 {
+		return 0, perRegime, invalid(ErrTooFewSamples, "data", "no pair lies within the given ranges")
+	}
+	copy(perRegime[:], mi)
+	cmi = (float64(counts[0])*mi[0] + float64(counts[1])*mi[1]) / float64(total)
+	return cmi, perRegime, nil
+}
+
+// BucketedMutualInformation calculates the mutual information separately
+// within each bucket 0..numBuckets-1, e.g. the hour of day of every sample.
+// Buckets without pairs in range have MI 0.
+func BucketedMutualInformation(dataX, dataY []float64, bucket []int, numBuckets, bins int, minX, maxX, minY, maxY float64) ([]float64, error) {
+	if numBuckets < 1 {
+		return nil, invalid(ErrInvalidBins, "buckets", "there must be at least one bucket")
+	}
+	mi, _, err := groupedMutualInformation(dataX, dataY, bucket, numBuckets, bins, minX, maxX, minY, maxY)
+	return mi, err
+}
+
+// GroupedMutualInformation calculates the mutual information within each
+// group, e.g. each subject of a repeated-measures design, and pools the
+// per-group values weighted by their number of in-range pairs. perGroup is
+// ordered by ascending group label. stderr is the standard error of the
+// weighted mean from the between-group variance; it is NaN for a single
+// group. Unlike calculating the MI of all pooled samples, this does not mix
+// within- and between-group variation.
+func GroupedMutualInformation(dataX, dataY []float64, group []int, bins int, minX, maxX, minY, maxY float64) (pooledMI float64, perGroup []float64, stderr float64, err error) {
+	if len(group) != len(dataX) {
+		return 0, nil, 0, invalid(ErrSizeMismatch, "groups", "group labels and data must have the same size")
+	}
+	labels := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, g := range group {
+		if !seen[g] {
+			seen[g] = true
+			labels = append(labels, g)
+		}
+	}
+	sort.Ints(labels)
+	dense := make(map[int]int, len(labels))
+	for i, g := range labels {
+		dense[g] = i
+	}
+	indices := make([]int, len(group))
+	for i, g := range group {
+		indices[i] = dense[g]
+	}
+
+	perGroup, counts, err := groupedMutualInformation(dataX, dataY, indices, len(labels), bins, minX, maxX, minY, maxY)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0, nil, 0, invalid(ErrTooFewSamples, "data", "no pair lies within the given ranges")
+	}
+
+	var sumSquaredWeights float64
+	for g, mi := range perGroup {
+		w := float64(counts[g]) / float64(total)
+		pooledMI += w * mi
+		sumSquaredWeights += w * w
+	}
+	if sumSquaredWeights >= 1 {
+		return pooledMI, perGroup, math.NaN(), nil
+	}
+	var variance float64
+	for g, mi := range perGroup {
+		w := float64(counts[g]) / float64(total)
+		variance += w * (mi - pooledMI) * (mi - pooledMI)
+	}
+	variance /= 1 - sumSquaredWeights
+	return pooledMI, perGroup, math.Sqrt(variance * sumSquaredWeights), nil
+}
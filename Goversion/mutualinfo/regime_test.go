@@ -0,0 +1,102 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRegimeConditionalMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	n := 4000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	regime := make([]int, n)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		regime[i] = i % 2
+		if regime[i] == 0 {
+			dataY[i] = dataX[i]
+		} else {
+			dataY[i] = rng.Float64()
+		}
+	}
+	cmi, perRegime, err := RegimeConditionalMutualInformation(dataX, dataY, regime, 4, 0, 1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(perRegime[0], 2, 0.01) || perRegime[1] > 0.05 {
+		t.Errorf("unexpected per-regime MI %v", perRegime)
+	}
+	if !almostEqual(cmi, (perRegime[0]+perRegime[1])/2, 1e-12) {
+		t.Errorf("expected equally weighted combination, got %v", cmi)
+	}
+	regime[0] = 2
+	if _, _, err := RegimeConditionalMutualInformation(dataX, dataY, regime, 4, 0, 1, 0, 1); err == nil {
+		t.Error("expected error for non-binary regime")
+	}
+}
+
+func TestBucketedMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	n := 24 * 400
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	hour := make([]int, n)
+	for i := range dataX {
+		hour[i] = i % 24
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+		// Coupling only during daytime.
+		if hour[i] >= 8 && hour[i] < 20 {
+			dataY[i] = dataX[i]
+		}
+	}
+	mi, err := BucketedMutualInformation(dataX, dataY, hour, 24, 4, 0, 1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mi) != 24 || mi[3] > 0.1 || mi[12] < 1.9 {
+		t.Errorf("unexpected hourly MI %v", mi)
+	}
+	if _, err := BucketedMutualInformation(dataX, dataY, hour, 12, 4, 0, 1, 0, 1); err == nil {
+		t.Error("expected error for bucket label out of range")
+	}
+}
+
+func TestGroupedMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	var dataX, dataY []float64
+	var group []int
+	// Subject 7 is strongly coupled, subject 3 not at all; subject 3 has twice the samples.
+	for _, subject := range []struct{ label, n int }{{7, 1000}, {3, 2000}} {
+		for i := 0; i < subject.n; i++ {
+			x := rng.Float64()
+			y := rng.Float64()
+			if subject.label == 7 {
+				y = x
+			}
+			dataX = append(dataX, x)
+			dataY = append(dataY, y)
+			group = append(group, subject.label)
+		}
+	}
+	pooled, perGroup, stderr, err := GroupedMutualInformation(dataX, dataY, group, 4, 0, 1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perGroup) != 2 || perGroup[0] > 0.05 || !almostEqual(perGroup[1], 2, 0.01) {
+		t.Fatalf("unexpected per-group MI %v", perGroup)
+	}
+	if !almostEqual(pooled, (2*perGroup[0]+perGroup[1])/3, 1e-12) {
+		t.Errorf("pooled MI %v is not weighted by sample count", pooled)
+	}
+	if !(stderr > 0.5) {
+		t.Errorf("expected a large between-subject standard error, got %v", stderr)
+	}
+
+	_, _, stderr, err = GroupedMutualInformation(dataX[:10], dataY[:10], group[:10], 4, 0, 1, 0, 1)
+	if err != nil || !math.IsNaN(stderr) {
+		t.Errorf("single group: expected NaN standard error, got %v, %v", stderr, err)
+	}
+}
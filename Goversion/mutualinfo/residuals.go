@@ -0,0 +1,43 @@
+package mutualinfo
+
+import "math"
+
+// ExpectedCounts returns the counts expected under independence of X and Y,
+// E[i][j] = rowTotal[i]*colTotal[j]/N, in the same layout as Data.
+func (h *Histogram2D) ExpectedCounts() [][]float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	rows, cols, total := h.marginalCounts()
+	expected := make([][]float64, h.BinsX)
+	for i := range expected {
+		expected[i] = make([]float64, h.BinsY)
+		if total == 0 {
+			continue
+		}
+		for j := range expected[i] {
+			expected[i][j] = float64(rows[i]) * float64(cols[j]) / float64(total)
+		}
+	}
+	return expected
+}
+
+// StandardizedResiduals returns (O-E)/sqrt(E) for every cell, where O are the
+// observed and E the expected counts. Cells with E == 0 have a residual of 0.
+func (h *Histogram2D) StandardizedResiduals() [][]float64 {
+	expected := h.ExpectedCounts()
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	residuals := make([][]float64, h.BinsX)
+	for i := range residuals {
+		residuals[i] = make([]float64, h.BinsY)
+		for j, e := range expected[i] {
+			if e > 0 {
+				residuals[i][j] = (float64(h.Data[i][j]) - e) / math.Sqrt(e)
+			}
+		}
+	}
+	return residuals
+}
@@ -0,0 +1,28 @@
+package mutualinfo
+
+import "testing"
+
+func TestExpectedCounts(t *testing.T) {
+	hist := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	for _, p := range [][2]float64{{0.5, 0.5}, {0.5, 0.5}, {0.5, 1.5}, {1.5, 1.5}} {
+		hist.Increment(p[0], p[1])
+	}
+	want := [][]float64{{1.5, 1.5}, {0.5, 0.5}}
+	got := hist.ExpectedCounts()
+	for i := range want {
+		for j := range want[i] {
+			if !almostEqual(got[i][j], want[i][j], 1e-12) {
+				t.Errorf("E[%d][%d] = %v, want %v", i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+	residuals := hist.StandardizedResiduals()
+	if residuals[0][0] <= 0 || residuals[1][0] >= 0 {
+		t.Errorf("unexpected residual signs %v", residuals)
+	}
+
+	empty := NewHistogram2D(2, 2, 0, 2, 0, 2).ExpectedCounts()
+	if empty[0][0] != 0 {
+		t.Errorf("expected zero counts for empty histogram, got %v", empty)
+	}
+}
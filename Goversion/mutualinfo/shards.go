@@ -0,0 +1,238 @@
+package mutualinfo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// add adds the counts of other to h. Both must have the same bins and the caller
+// must ensure that neither is modified concurrently.
+func (h *Histogram2D) add(other *Histogram2D) {
+	for i := range h.Data {
+		for j := range h.Data[i] {
+			h.Data[i][j] += other.Data[i][j]
+		}
+	}
+	h.OutOfRange += other.OutOfRange
+	h.Missing += other.Missing
+	h.Outside.add(other.Outside)
+	if h.WeightedData == nil && other.WeightedData == nil {
+		return
+	}
+	if h.WeightedData == nil {
+		h.WeightedData = make([][]float64, h.BinsX)
+		for i := range h.WeightedData {
+			h.WeightedData[i] = make([]float64, h.BinsY)
+			for j, c := range h.Data[i] {
+				// h.Data already includes the counts of other.
+				h.WeightedData[i][j] = float64(c - other.Data[i][j])
+			}
+		}
+	}
+	for i := range h.WeightedData {
+		for j := range h.WeightedData[i] {
+			if other.WeightedData != nil {
+				h.addWeight(i, j, other.WeightedData[i][j])
+			} else {
+				h.addWeight(i, j, float64(other.Data[i][j]))
+			}
+			if other.weightResiduals != nil {
+				h.addWeight(i, j, other.weightResiduals[i][j])
+			}
+		}
+	}
+}
+
+// Merge adds the counts of other to h, e.g. to combine partial histograms of
+// separately processed shards; merging the histograms of all shards gives
+// the same MI as one histogram of all data. An error is returned unless both
+// have the same bins, ranges and edges.
+//
+// other is copied under its own lock before h is locked, so the two
+// mutexes are never held at the same time and concurrent merges in opposite
+// directions cannot deadlock. h.Merge(h) doubles all counts.
+func (h *Histogram2D) Merge(other *Histogram2D) error {
+	if h.BinsX != other.BinsX || h.BinsY != other.BinsY {
+		return invalid(ErrSizeMismatch, "histograms", "histograms must have the same number of bins")
+	}
+	if h.MinX != other.MinX || h.MaxX != other.MaxX || h.MinY != other.MinY || h.MaxY != other.MaxY {
+		return invalid(ErrSizeMismatch, "histograms", "histograms must have the same ranges")
+	}
+	if !equalEdges(h.EdgesX, other.EdgesX) || !equalEdges(h.EdgesY, other.EdgesY) {
+		return invalid(ErrSizeMismatch, "histograms", "histograms must have the same edges")
+	}
+
+	other.Mutex.Lock()
+	snapshot := NewHistogram2D(other.BinsX, other.BinsY, other.MinX, other.MaxX, other.MinY, other.MaxY)
+	for i := range other.Data {
+		copy(snapshot.Data[i], other.Data[i])
+	}
+	snapshot.OutOfRange = other.OutOfRange
+	snapshot.Missing = other.Missing
+	snapshot.Outside = other.Outside
+	if other.WeightedData != nil {
+		snapshot.WeightedData = make([][]float64, other.BinsX)
+		for i := range other.WeightedData {
+			snapshot.WeightedData[i] = append([]float64(nil), other.WeightedData[i]...)
+		}
+	}
+	if other.weightResiduals != nil {
+		snapshot.weightResiduals = make([][]float64, other.BinsX)
+		for i := range other.weightResiduals {
+			snapshot.weightResiduals[i] = append([]float64(nil), other.weightResiduals[i]...)
+		}
+	}
+	other.Mutex.Unlock()
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.add(snapshot)
+	return nil
+}
+
+func equalEdges(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// readColumns streams the CSV records of r and calls fn with the values of
+// columns colX and colY of every record, skipping the first record if hasHeader.
+// A record with a non-numeric value in either column is an error, or skipped
+// if skipNonNumeric.
+func readColumns(r io.Reader, comma rune, colX, colY int, hasHeader, skipNonNumeric bool, fn func(x, y float64)) error {
+	if colX < 0 || colY < 0 {
+		return invalid(ErrInvalidParameter, "colX", "column indices must not be negative")
+	}
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hasHeader && line == 1 {
+			continue
+		}
+		if colX >= len(record) || colY >= len(record) {
+			return invalid(ErrSizeMismatch, "colX", fmt.Sprintf("line %d: has %d columns, need column %d", line, len(record), maxInt(colX, colY)))
+		}
+		x, errX := strconv.ParseFloat(record[colX], 64)
+		y, errY := strconv.ParseFloat(record[colY], 64)
+		if errX != nil || errY != nil {
+			if skipNonNumeric {
+				continue
+			}
+			if errX != nil {
+				return fmt.Errorf("line %d: %w", line, errX)
+			}
+			return fmt.Errorf("line %d: %w", line, errY)
+		}
+		fn(x, y)
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// ShardedMutualInformation calculates the mutual information of columns colX
+// and colY over all CSV files matching pattern, as if they were one file.
+// If pattern is a directory, all *.csv files in it are used. The files are
+// read in parallel, each worker filling a private histogram, and the
+// histograms are merged at the end. Pairs outside the ranges are ignored.
+func ShardedMutualInformation(pattern string, colX, colY int, hasHeader bool, binsX, binsY int, minX, maxX, minY, maxY float64) (float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, nil, nil); err != nil {
+		return 0, err
+	}
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*.csv")
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, invalid(ErrTooFewSamples, "pattern", "no files match the pattern")
+	}
+
+	workers := runtime.NumCPU()
+	if workers > len(files) {
+		workers = len(files)
+	}
+	paths := make(chan string)
+	hists := make([]*Histogram2D, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		hists[w] = NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for path := range paths {
+				if errs[w] != nil {
+					continue
+				}
+				errs[w] = readShard(path, colX, colY, hasHeader, hists[w])
+			}
+		}(w)
+	}
+	for _, path := range files {
+		paths <- path
+	}
+	close(paths)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return 0, err
+		}
+	}
+	total := hists[0]
+	for _, hist := range hists[1:] {
+		total.add(hist)
+	}
+	if _, _, n := total.marginalCounts(); n == 0 {
+		return 0, invalid(ErrTooFewSamples, "data", "no pair lies within the given ranges")
+	}
+	return total.CalculateMutualInformation(), nil
+}
+
+func readShard(path string, colX, colY int, hasHeader bool, hist *Histogram2D) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = readColumns(file, ',', colX, colY, hasHeader, false, func(x, y float64) {
+		if x < hist.MinX || x > hist.MaxX || y < hist.MinY || y > hist.MaxY {
+			return
+		}
+		hist.Increment(x, y)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package mutualinfo
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShardedMutualInformation(t *testing.T) {
+	dir := t.TempDir()
+	var dataX, dataY []float64
+	for day := 0; day < 3; day++ {
+		var b strings.Builder
+		b.WriteString("time,x,y\n")
+		for i := 0; i < 100; i++ {
+			x := float64((i*7+day)%10) + 0.5
+			y := float64((i*3)%10) + 0.5
+			if i%2 == 0 {
+				y = x
+			}
+			dataX = append(dataX, x)
+			dataY = append(dataY, y)
+			fmt.Fprintf(&b, "%d,%v,%v\n", i, x, y)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("day%d.csv", day)), []byte(b.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ShardedMutualInformation(dir, 1, 2, true, 10, 10, 0, 10, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := MutualInformation(10, 10, 0, 10, 0, 10, dataX, dataY)
+	if !almostEqual(got, want, 1e-12) {
+		t.Errorf("sharded MI %v differs from MI of concatenated data %v", got, want)
+	}
+
+	if _, err := ShardedMutualInformation(dir, 1, 5, true, 10, 10, 0, 10, 0, 10); err == nil {
+		t.Error("expected error for missing column")
+	}
+	if _, err := ShardedMutualInformation(filepath.Join(dir, "*.txt"), 1, 2, true, 10, 10, 0, 10, 0, 10); !errors.Is(err, ErrTooFewSamples) {
+		t.Errorf("no file matches: got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	whole := NewHistogram2D(6, 6, 0, 1, 0, 1)
+	first := NewHistogram2D(6, 6, 0, 1, 0, 1)
+	second := NewHistogram2D(6, 6, 0, 1, 0, 1)
+	for i := 0; i < 1000; i++ {
+		x := rng.Float64()
+		y := 0.6*x + 0.4*rng.Float64()
+		whole.Increment(x, y)
+		if i < 400 {
+			first.Increment(x, y)
+		} else {
+			second.Increment(x, y)
+		}
+	}
+	if err := first.Merge(second); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := first.CalculateMutualInformation(), whole.CalculateMutualInformation(); got != want {
+		t.Errorf("merged MI %v, want %v", got, want)
+	}
+
+	// Opposite merges running concurrently must not deadlock.
+	a := NewHistogram2D(2, 2, 0, 1, 0, 1)
+	b := NewHistogram2D(2, 2, 0, 1, 0, 1)
+	a.Increment(0.2, 0.2)
+	b.Increment(0.8, 0.8)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); a.Merge(b) }()
+		go func() { defer wg.Done(); b.Merge(a) }()
+	}
+	wg.Wait()
+
+	if err := a.Merge(a); err != nil {
+		t.Errorf("self merge: %v", err)
+	}
+	if err := whole.Merge(NewHistogram2D(6, 5, 0, 1, 0, 1)); err == nil {
+		t.Error("expected error for different bins")
+	}
+	if err := whole.Merge(NewHistogram2D(6, 6, 0, 2, 0, 1)); err == nil {
+		t.Error("expected error for different ranges")
+	}
+}
@@ -0,0 +1,348 @@
+package mutualinfo
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+)
+
+// NormalizeShiftCurve returns the shift curve mi divided by its maximum so that
+// its peak is 1. A curve without a positive maximum is returned as zeros.
+func NormalizeShiftCurve(mi []float64) []float64 {
+	peak := 0.0
+	for _, v := range mi {
+		if v > peak {
+			peak = v
+		}
+	}
+	normalized := make([]float64, len(mi))
+	if peak == 0 {
+		return normalized
+	}
+	for i, v := range mi {
+		normalized[i] = v / peak
+	}
+	return normalized
+}
+
+// ShiftAsymmetry returns the summed MI at positive shifts minus the summed MI
+// at negative shifts of a sweep starting at shiftFrom with step shiftStep;
+// shift 0 does not contribute. The sweep should be symmetric around 0.
+//
+// A positive shift pairs dataX[j+shift] with dataY[j], so a positive
+// asymmetry means that Y is more informative about later values of X, i.e.
+// Y leads X. A negative asymmetry means that X leads Y.
+func ShiftAsymmetry(mi []float64, shiftFrom, shiftStep int) float64 {
+	var asymmetry float64
+	for i, v := range mi {
+		shift := shiftFrom + i*shiftStep
+		switch {
+		case shift > 0:
+			asymmetry += v
+		case shift < 0:
+			asymmetry -= v
+		}
+	}
+	return asymmetry
+}
+
+// PeakShift returns the shift with the largest MI of the sweep
+// shiftFrom..shiftTo with step shiftStep, as returned by
+// ShiftedMutualInformation, together with that MI. NaN values are skipped
+// and the smallest shift wins ties. An error is returned if mi does not
+// match the sweep or holds no value other than NaN.
+func PeakShift(shiftFrom, shiftTo, shiftStep int, mi []float64) (shift int, value float64, err error) {
+	if shiftStep < 1 {
+		return 0, 0, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if shiftFrom > shiftTo {
+		return 0, 0, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if len(mi) != (shiftTo-shiftFrom)/shiftStep+1 {
+		return 0, 0, invalid(ErrSizeMismatch, "mi", "mi must hold one value per shift")
+	}
+	best := -1
+	for i, v := range mi {
+		if !math.IsNaN(v) && (best < 0 || v > mi[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, 0, invalid(ErrTooFewSamples, "mi", "mi holds no value other than NaN")
+	}
+	return shiftFrom + best*shiftStep, mi[best], nil
+}
+
+// LagEstimate is the delay at which a shift sweep peaks. Shift is the shift
+// with the largest MI and Delay the estimated delay, which interpolation
+// may place between shifts; PeakMI is the MI at Delay.
+type LagEstimate struct {
+	Shift  int
+	Delay  float64
+	PeakMI float64
+}
+
+// FindOptimalLag locates the largest MI of results, which must be sorted by
+// shift as returned by ShiftedMutualInformationDiagnostics. NaN values are
+// skipped and the smallest shift wins ties. With interpolate, a parabola
+// through the peak and its two neighbors refines the delay and peak MI
+// between shifts; a peak at either end of the sweep is not interpolated.
+// With a positive delay, Y leads X, see ShiftAsymmetry.
+func FindOptimalLag(results []ShiftResult, interpolate bool) (LagEstimate, error) {
+	best := -1
+	for i, r := range results {
+		if i > 0 && r.Shift <= results[i-1].Shift {
+			return LagEstimate{}, invalid(ErrInvalidData, "results", "results must be sorted by increasing shift")
+		}
+		if !math.IsNaN(r.MI) && (best < 0 || r.MI > results[best].MI) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return LagEstimate{}, invalid(ErrTooFewSamples, "results", "results hold no MI other than NaN")
+	}
+	peak := results[best]
+	estimate := LagEstimate{Shift: peak.Shift, Delay: float64(peak.Shift), PeakMI: peak.MI}
+	if !interpolate || best == 0 || best == len(results)-1 {
+		return estimate, nil
+	}
+	x0, y0 := float64(results[best-1].Shift), results[best-1].MI
+	x1, y1 := float64(peak.Shift), peak.MI
+	x2, y2 := float64(results[best+1].Shift), results[best+1].MI
+	denominator := (x1-x0)*(y1-y2) - (x1-x2)*(y1-y0)
+	// A flat or NaN neighborhood has no vertex.
+	if !(denominator > 0) {
+		return estimate, nil
+	}
+	x := x1 - 0.5*((x1-x0)*(x1-x0)*(y1-y2)-(x1-x2)*(x1-x2)*(y1-y0))/denominator
+	estimate.Delay = x
+	estimate.PeakMI = y0*(x-x1)*(x-x2)/((x0-x1)*(x0-x2)) +
+		y1*(x-x0)*(x-x2)/((x1-x0)*(x1-x2)) +
+		y2*(x-x0)*(x-x1)/((x2-x0)*(x2-x1))
+	return estimate, nil
+}
+
+// Result is a shift sweep together with its peak, see PeakShift, the
+// entropies and number of pairs in the bins of every shift, the number of
+// pairs of every shift skipped because they hold a NaN or lie outside the
+// ranges, the bin edges used for all shifts and the settings of the
+// estimator. EdgesX, EdgesY, OutOfRange and Outside are nil with
+// RecalibrateRange, where every shift has its own bins. It
+// encodes to JSON as is, except that NaN values, e.g. of a shift without
+// pairs in range, are encoded as null and decoded back to NaN.
+type Result struct {
+	Shifts  []int     `json:"shifts"`
+	MI      []float64 `json:"mi"`
+	HX      []float64 `json:"hx"`
+	HY      []float64 `json:"hy"`
+	HXY     []float64 `json:"hxy"`
+	Pairs   []int     `json:"pairs"`
+	Dropped []int     `json:"dropped"`
+	// OutOfRange counts the pairs of every shift without a NaN but with a
+	// value outside the ranges, and Outside these values by axis and side.
+	OutOfRange []int          `json:"out_of_range,omitempty"`
+	Outside    []RangeCounts  `json:"outside,omitempty"`
+	PeakShift  int            `json:"peak_shift"`
+	PeakMI     float64        `json:"peak_mi"`
+	EdgesX     []float64      `json:"edges_x,omitempty"`
+	EdgesY     []float64      `json:"edges_y,omitempty"`
+	Settings   ResultSettings `json:"settings"`
+	// ShuffleBias is the mean MI of the shuffles subtracted from every
+	// shift with ShiftOptions.Shuffles.
+	ShuffleBias []float64 `json:"shuffle_bias,omitempty"`
+	// Correlation and DistanceCorrelation are set by AddCorrelations.
+	Correlation         []float64 `json:"correlation,omitempty"`
+	DistanceCorrelation []float64 `json:"distance_correlation,omitempty"`
+}
+
+// ResultSettings records how a Result was estimated, after AutoBins,
+// winsorizing and quantile binning have replaced the bins and ranges passed
+// in.
+type ResultSettings struct {
+	BinsX            int     `json:"bins_x"`
+	BinsY            int     `json:"bins_y"`
+	MinX             float64 `json:"min_x"`
+	MaxX             float64 `json:"max_x"`
+	MinY             float64 `json:"min_y"`
+	MaxY             float64 `json:"max_y"`
+	ShiftFrom        int     `json:"shift_from"`
+	ShiftTo          int     `json:"shift_to"`
+	ShiftStep        int     `json:"shift_step"`
+	Binning          string  `json:"binning"`
+	Normalization    string  `json:"normalization"`
+	BiasCorrection   string  `json:"bias_correction"`
+	Unit             string  `json:"unit"`
+	NaNPolicy        string  `json:"nan_policy"`
+	Preprocess       string  `json:"preprocess"`
+	Shuffles         int     `json:"shuffles"`
+	Winsorize        float64 `json:"winsorize"`
+	DeadZone         float64 `json:"dead_zone"`
+	RecalibrateRange bool    `json:"recalibrate_range"`
+	CommonWindow     bool    `json:"common_window"`
+}
+
+// nullableFloats is a []float64 whose NaN and infinite values, which JSON
+// cannot represent, encode as null. null decodes to NaN.
+type nullableFloats []float64
+
+func (v nullableFloats) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	values := make([]*float64, len(v))
+	for i := range v {
+		if isFinite(v[i]) {
+			values[i] = &v[i]
+		}
+	}
+	return json.Marshal(values)
+}
+
+func (v *nullableFloats) UnmarshalJSON(data []byte) error {
+	var values []*float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if values == nil {
+		*v = nil
+		return nil
+	}
+	*v = make(nullableFloats, len(values))
+	for i, p := range values {
+		(*v)[i] = math.NaN()
+		if p != nil {
+			(*v)[i] = *p
+		}
+	}
+	return nil
+}
+
+// resultJSON is the JSON form of a Result, with the fields that may hold
+// NaN as nullableFloats and in the same order.
+type resultJSON struct {
+	Shifts              []int          `json:"shifts"`
+	MI                  nullableFloats `json:"mi"`
+	HX                  nullableFloats `json:"hx"`
+	HY                  nullableFloats `json:"hy"`
+	HXY                 nullableFloats `json:"hxy"`
+	Pairs               []int          `json:"pairs"`
+	Dropped             []int          `json:"dropped"`
+	OutOfRange          []int          `json:"out_of_range,omitempty"`
+	Outside             []RangeCounts  `json:"outside,omitempty"`
+	PeakShift           int            `json:"peak_shift"`
+	PeakMI              float64        `json:"peak_mi"`
+	EdgesX              []float64      `json:"edges_x,omitempty"`
+	EdgesY              []float64      `json:"edges_y,omitempty"`
+	Settings            ResultSettings `json:"settings"`
+	ShuffleBias         nullableFloats `json:"shuffle_bias,omitempty"`
+	Correlation         nullableFloats `json:"correlation,omitempty"`
+	DistanceCorrelation nullableFloats `json:"distance_correlation,omitempty"`
+}
+
+// MarshalJSON encodes r with NaN values, e.g. of a shift without pairs in
+// range, as null.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(resultJSON{
+		Shifts: r.Shifts, MI: r.MI, HX: r.HX, HY: r.HY, HXY: r.HXY,
+		Pairs: r.Pairs, Dropped: r.Dropped, OutOfRange: r.OutOfRange, Outside: r.Outside,
+		PeakShift: r.PeakShift, PeakMI: r.PeakMI, EdgesX: r.EdgesX, EdgesY: r.EdgesY,
+		Settings: r.Settings, ShuffleBias: r.ShuffleBias,
+		Correlation: r.Correlation, DistanceCorrelation: r.DistanceCorrelation,
+	})
+}
+
+// UnmarshalJSON decodes r as encoded by MarshalJSON, with null as NaN.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var v resultJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*r = Result{
+		Shifts: v.Shifts, MI: v.MI, HX: v.HX, HY: v.HY, HXY: v.HXY,
+		Pairs: v.Pairs, Dropped: v.Dropped, OutOfRange: v.OutOfRange, Outside: v.Outside,
+		PeakShift: v.PeakShift, PeakMI: v.PeakMI, EdgesX: v.EdgesX, EdgesY: v.EdgesY,
+		Settings: v.Settings, ShuffleBias: v.ShuffleBias,
+		Correlation: v.Correlation, DistanceCorrelation: v.DistanceCorrelation,
+	}
+	return nil
+}
+
+// shiftedOutside returns the number of pairs of every shift with a value
+// outside the ranges but no NaN, and these values by axis and side, like
+// shiftedNaNPairs for the pairs with lo <= j < hi. Pairs in the dead zone
+// are skipped as by the sweep.
+func shiftedOutside(shiftFrom, shiftTo, shiftStep, lo, hi int, minX, maxX, minY, maxY, deadZone float64, dataX, dataY []float64) ([]int, []RangeCounts) {
+	numShifts := (shiftTo-shiftFrom)/shiftStep + 1
+	pairs, outside := make([]int, numShifts), make([]RangeCounts, numShifts)
+	// Usually the ranges span the data and there is nothing to count.
+	outsideX, outsideY := false, false
+	for i := range dataX {
+		outsideX = outsideX || dataX[i] < minX || dataX[i] > maxX
+		outsideY = outsideY || dataY[i] < minY || dataY[i] > maxY
+	}
+	if !outsideX && !outsideY {
+		return pairs, outside
+	}
+	n := len(dataX)
+	for i := range pairs {
+		shift := shiftFrom + i*shiftStep
+		for j := maxInt(lo, -shift); j < minInt(hi, n-shift); j++ {
+			x, y := dataX[j+shift], dataY[j]
+			if math.IsNaN(x) || math.IsNaN(y) || (math.Abs(x) < deadZone && math.Abs(y) < deadZone) {
+				continue
+			}
+			if x < minX || x > maxX || y < minY || y > maxY {
+				pairs[i]++
+				outside[i].count(x, y, minX, maxX, minY, maxY)
+			}
+		}
+	}
+	return pairs, outside
+}
+
+// ShiftResults returns the shifts of r one by one, without confidence
+// intervals or p-values.
+func (r Result) ShiftResults() []ShiftResult {
+	results := make([]ShiftResult, len(r.Shifts))
+	for i, shift := range r.Shifts {
+		results[i] = ShiftResult{
+			Shift: shift,
+			MI:    r.MI[i],
+			Pairs: r.Pairs[i],
+			HX:    r.HX[i], HY: r.HY[i], HXY: r.HXY[i],
+			Lower: math.NaN(), Upper: math.NaN(),
+			PValue: math.NaN(),
+		}
+	}
+	return results
+}
+
+// ShiftedMutualInformationDiagnostics is ShiftedMutualInformationWithOptions
+// returning every shift with its entropies and number of pairs. Unlike
+// ShiftedMutualInformationResult it succeeds if no shift has an MI. Use
+// BootstrapShiftedMutualInformation for confidence intervals and
+// SignificanceTest for p-values.
+func ShiftedMutualInformationDiagnostics(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep int, opts ShiftOptions) ([]ShiftResult, error) {
+	var result Result
+	mi, err := shiftedMutualInformation(context.Background(), shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, opts, &result)
+	if err != nil {
+		return nil, err
+	}
+	result.MI = mi
+	return result.ShiftResults(), nil
+}
+
+// ShiftedMutualInformationResult runs ShiftedMutualInformationWithOptions
+// and returns the MI per shift together with the details of Result.
+func ShiftedMutualInformationResult(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep int, opts ShiftOptions) (Result, error) {
+	var result Result
+	mi, err := shiftedMutualInformation(context.Background(), shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, opts, &result)
+	if err != nil {
+		return Result{}, err
+	}
+	result.MI = mi
+	if result.PeakShift, result.PeakMI, err = PeakShift(shiftFrom, shiftTo, shiftStep, mi); err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,276 @@
+package mutualinfo
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeShiftCurve(t *testing.T) {
+	got := NormalizeShiftCurve([]float64{0.1, 0.4, 0.2})
+	want := []float64{0.25, 1, 0.5}
+	for i := range want {
+		if !almostEqual(got[i], want[i], 1e-12) {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+	for _, v := range NormalizeShiftCurve([]float64{0, 0}) {
+		if v != 0 {
+			t.Errorf("expected zeros for an all-zero curve")
+		}
+	}
+}
+
+func TestShiftAsymmetry(t *testing.T) {
+	n := 2000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	rng := rand.New(rand.NewSource(19))
+	for i := range dataX {
+		dataX[i] = float64(rng.Intn(8))
+	}
+	// X leads Y by three samples.
+	for i := range dataY {
+		if i >= 3 {
+			dataY[i] = dataX[i-3]
+		}
+	}
+	mi, err := ShiftedMutualInformation(-5, 5, 8, 8, 0, 8, 0, 8, dataX, dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := ShiftAsymmetry(mi, -5, 1); a >= 0 {
+		t.Errorf("expected negative asymmetry when X leads Y, got %v", a)
+	}
+	if a := ShiftAsymmetry([]float64{1, 5, 1}, -1, 1); a != 0 {
+		t.Errorf("symmetric curve: got %v", a)
+	}
+}
+
+func TestPeakShift(t *testing.T) {
+	shift, value, err := PeakShift(-4, 4, 2, []float64{0.1, math.NaN(), 0.3, 0.7, 0.7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shift != 2 || value != 0.7 {
+		t.Errorf("peak at shift %d with %v, want shift 2 with 0.7", shift, value)
+	}
+	if _, _, err := PeakShift(-4, 4, 2, []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}); err == nil {
+		t.Error("expected error for all NaN")
+	}
+	if _, _, err := PeakShift(-4, 4, 2, nil); err == nil {
+		t.Error("expected error for empty mi")
+	}
+	if _, _, err := PeakShift(-4, 4, 2, []float64{1, 2, 3}); err == nil {
+		t.Error("expected error for a length mismatch")
+	}
+}
+
+func TestShiftedMutualInformationResultDetails(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	dataX := make([]float64, 400)
+	dataY := make([]float64, 400)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	dataY[0] = 3
+	for _, sparse := range []bool{false, true} {
+		opts := ShiftOptions{Sparse: sparse, Normalization: NormalizationJoint}
+		result, err := ShiftedMutualInformationResult(-2, 2, 4, 4, 0, 1, 0, 1, dataX, dataY, 2, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Shift s leaves 400-|s| pairs, all but shift -2 lose the pair with
+		// dataY[0] out of range.
+		wantPairs := []int{398, 399, 397}
+		for i, shift := range result.Shifts {
+			hist := shiftedHistogram(shift, 4, 4, 0, 1, 0, 1, SlicePairs{X: dataX, Y: dataY}, ShiftOptions{})
+			hx, hy, hxy := hist.Entropies()
+			if result.Pairs[i] != wantPairs[i] || !almostEqual(result.HX[i], hx, 1e-12) || !almostEqual(result.HY[i], hy, 1e-12) || !almostEqual(result.HXY[i], hxy, 1e-12) {
+				t.Errorf("sparse %v, shift %d: pairs %d, entropies %v %v %v, want %d, %v %v %v", sparse, shift,
+					result.Pairs[i], result.HX[i], result.HY[i], result.HXY[i], wantPairs[i], hx, hy, hxy)
+			}
+		}
+		if s := result.Settings; s.BinsX != 4 || s.ShiftStep != 2 || s.Normalization != "joint" || s.Binning != "uniform" {
+			t.Errorf("settings %+v", s)
+		}
+	}
+
+	result, _ := ShiftedMutualInformationResult(-2, 2, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{})
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Result
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.PeakShift != result.PeakShift || decoded.MI[3] != result.MI[3] || decoded.Settings != result.Settings {
+		t.Errorf("JSON round trip changed the result: %s", encoded)
+	}
+}
+
+func TestShiftedMutualInformationDiagnostics(t *testing.T) {
+	dataX := make([]float64, 100)
+	dataY := make([]float64, 100)
+	for i := range dataX {
+		dataX[i] = float64(i % 10)
+		dataY[i] = float64((i + 3) % 10)
+	}
+	results, err := ShiftedMutualInformationDiagnostics(-5, 5, 10, 10, 0, 10, 0, 10, dataX, dataY, 5, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mi, _ := ShiftedMutualInformation(-5, 5, 10, 10, 0, 10, 0, 10, dataX, dataY, 5)
+	for i, r := range results {
+		if r.Shift != -5+5*i || r.MI != mi[i] || !almostEqual(r.HX+r.HY-r.HXY, r.MI, 1e-12) {
+			t.Errorf("result %d: %+v, want shift %d and MI %v", i, r, -5+5*i, mi[i])
+		}
+		if want := 100 - abs(r.Shift); r.Pairs != want {
+			t.Errorf("shift %d: %d pairs, want %d", r.Shift, r.Pairs, want)
+		}
+		if !math.IsNaN(r.Lower) || !math.IsNaN(r.PValue) {
+			t.Errorf("shift %d: interval and p-value set without being computed", r.Shift)
+		}
+	}
+
+	// Without any pair in range there is no MI, but still a result.
+	results, err = ShiftedMutualInformationDiagnostics(-5, 5, 10, 10, 20, 30, 0, 10, dataX, dataY, 5, ShiftOptions{})
+	if err != nil || results[1].Pairs != 0 {
+		t.Errorf("pairs out of range: %+v, %v", results, err)
+	}
+}
+
+func TestFindOptimalLag(t *testing.T) {
+	// Samples of the parabola 1 - (s-1.4)²/50 every 2 shifts.
+	var results []ShiftResult
+	for shift := -6; shift <= 6; shift += 2 {
+		d := float64(shift) - 1.4
+		results = append(results, ShiftResult{Shift: shift, MI: 1 - d*d/50})
+	}
+	lag, err := FindOptimalLag(results, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag.Shift != 2 || lag.Delay != 2 || lag.PeakMI != results[4].MI {
+		t.Errorf("without interpolation got %+v", lag)
+	}
+	lag, err = FindOptimalLag(results, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag.Shift != 2 || !almostEqual(lag.Delay, 1.4, 1e-12) || !almostEqual(lag.PeakMI, 1, 1e-12) {
+		t.Errorf("interpolated %+v, want delay 1.4 and MI 1", lag)
+	}
+
+	// A peak at the end of the sweep stays there.
+	lag, _ = FindOptimalLag(results[:4], true)
+	if lag.Shift != 0 || lag.Delay != 0 {
+		t.Errorf("peak at the last shift interpolated to %+v", lag)
+	}
+	results[2].MI = math.NaN()
+	if _, err := FindOptimalLag(results[2:3], true); err == nil {
+		t.Error("expected error for NaN only")
+	}
+	if _, err := FindOptimalLag([]ShiftResult{{Shift: 1}, {Shift: 0}}, false); err == nil {
+		t.Error("expected error for unsorted results")
+	}
+}
+
+func TestResultOutOfRange(t *testing.T) {
+	dataX := make([]float64, 50)
+	dataY := make([]float64, 50)
+	for i := range dataX {
+		dataX[i] = float64(i%10) / 10
+		dataY[i] = float64(i%7) / 7
+	}
+	dataX[10], dataX[20], dataY[30] = -1, 5, 2
+	dataY[40] = math.NaN()
+
+	result, err := ShiftedMutualInformationResult(-1, 1, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every outlier is in one pair per shift.
+	want := RangeCounts{UnderX: 1, OverX: 1, OverY: 1}
+	for i := range result.Shifts {
+		if result.OutOfRange[i] != 3 || result.Outside[i] != want {
+			t.Errorf("shift %d: %d pairs out of range, %+v, want 3 and %+v", result.Shifts[i], result.OutOfRange[i], result.Outside[i], want)
+		}
+		if got := result.Pairs[i] + result.Dropped[i] + result.OutOfRange[i]; got != 50-abs(result.Shifts[i]) {
+			t.Errorf("shift %d: %d pairs accounted for, want %d", result.Shifts[i], got, 50-abs(result.Shifts[i]))
+		}
+	}
+
+	inRange, _ := ShiftedMutualInformationResult(-1, 1, 4, 4, -1, 5, 0, 2, dataX, dataY, 1, ShiftOptions{})
+	if inRange.OutOfRange[0] != 0 || inRange.Outside[0] != (RangeCounts{}) {
+		t.Errorf("got %v and %v out of range with ranges spanning the data", inRange.OutOfRange, inRange.Outside)
+	}
+	recalibrated, _ := ShiftedMutualInformationResult(-1, 1, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{RecalibrateRange: true})
+	if recalibrated.OutOfRange != nil || recalibrated.Outside != nil {
+		t.Error("out-of-range counts are set with RecalibrateRange")
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	dataX := make([]float64, 10)
+	dataY := make([]float64, 10)
+	for i := range dataX {
+		dataX[i], dataY[i] = float64(i%3), float64(i%2)
+	}
+	// From shift 6 on, every pair holds one of the trailing NaNs of X.
+	for i := 6; i < 10; i++ {
+		dataX[i] = math.NaN()
+	}
+	result, err := ShiftedMutualInformationResult(0, 7, 2, 2, 0, 2, 0, 1, dataX, dataY, 1, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(result.MI[6]) || !math.IsNaN(result.MI[7]) {
+		t.Fatalf("expected empty shifts 6 and 7, got %v", result.MI)
+	}
+	if err := result.AddCorrelations(dataX, dataY, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(data, &fields)
+	if !strings.HasSuffix(string(fields["mi"]), ",null,null]") || fields["settings"] == nil || fields["pairs"] == nil {
+		t.Errorf("unexpected encoding %s", data)
+	}
+
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for i := range result.MI {
+		for _, pair := range [][2][]float64{{decoded.MI, result.MI}, {decoded.HXY, result.HXY}, {decoded.Correlation, result.Correlation}} {
+			got, want := pair[0][i], pair[1][i]
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Errorf("shift %d: decoded %v, want %v", i, got, want)
+			}
+		}
+	}
+	if decoded.Settings != result.Settings || decoded.PeakShift != result.PeakShift || decoded.DistanceCorrelation != nil {
+		t.Errorf("decoded %+v, want %+v", decoded, result)
+	}
+
+	// A field added to Result must be added to its JSON form as well.
+	plain, mirrored := reflect.TypeOf(Result{}), reflect.TypeOf(resultJSON{})
+	if plain.NumField() != mirrored.NumField() {
+		t.Fatalf("Result has %d fields, resultJSON %d", plain.NumField(), mirrored.NumField())
+	}
+	for i := 0; i < plain.NumField(); i++ {
+		if a, b := plain.Field(i), mirrored.Field(i); a.Name != b.Name || a.Tag != b.Tag {
+			t.Errorf("field %d: Result has %s %q, resultJSON %s %q", i, a.Name, a.Tag, b.Name, b.Tag)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package mutualinfo
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// permutationRand returns the random source of permutation index perm. Deriving
+// it from seed and perm fixes the set of permutations regardless of how they
+// are scheduled across goroutines. Both are mixed by splitmix64, so that
+// nearby seeds do not share most of their permutations as with seed+perm.
+func permutationRand(seed int64, perm int) *rand.Rand {
+	return rand.New(rand.NewSource(int64(splitmix64(splitmix64(uint64(seed)) + uint64(perm)))))
+}
+
+// splitmix64 is the output function of the SplitMix64 generator, a
+// bijection that scatters nearby inputs over all 64 bits.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// MutualInformationSignificance calculates the mutual information of dataX
+// and dataY and its p-value under the null hypothesis of independence. The
+// null distribution is sampled by shuffling dataY permutations times and the
+// p-value is the fraction of shuffles with an MI greater or equal to the
+// observed one. Results only depend on seed, not on GOMAXPROCS.
+//
+// If dataY is a copy of dataX, shuffles still give a clearly positive MI
+// but rarely one as high as the observed, so the p-value is near 0. Data in
+// a single bin has MI 0 for every shuffle and a p-value of 1.
+func MutualInformationSignificance(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, permutations int, seed int64) (mi, pValue float64, err error) {
+	if permutations < 1 {
+		return 0, 0, invalid(ErrInvalidParameter, "permutations", "there must be at least one permutation")
+	}
+	mi, err = MutualInformation(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pValue = permutationPValue(mi, permutations, seed, func(rng *rand.Rand, shuffled []float64) float64 {
+		copy(shuffled, dataY)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		null, _ := MutualInformation(binsX, binsY, minX, maxX, minY, maxY, dataX, shuffled)
+		return null
+	}, len(dataY))
+	return mi, pValue, nil
+}
+
+// permutationPValue evaluates null for permutations permutations across
+// GOMAXPROCS goroutines and returns the fraction with a value greater or
+// equal to observed. null gets the random source of its permutation index
+// and a scratch buffer of size bufSize owned by the calling goroutine.
+func permutationPValue(observed float64, permutations int, seed int64, null func(rng *rand.Rand, buf []float64) float64, bufSize int) float64 {
+	exceed := make([]bool, permutations)
+	perms := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]float64, bufSize)
+			for p := range perms {
+				exceed[p] = null(permutationRand(seed, p), buf) >= observed
+			}
+		}()
+	}
+	for p := 0; p < permutations; p++ {
+		perms <- p
+	}
+	close(perms)
+	wg.Wait()
+
+	count := 0
+	for _, e := range exceed {
+		if e {
+			count++
+		}
+	}
+	return float64(count) / float64(permutations)
+}
+
+// StratifiedMutualInformationSignificance is MutualInformationSignificance
+// with dataY only shuffled among samples of the same stratum, e.g. the same
+// block, session or coarse bin of a covariate. Like the plain permutation it
+// keeps both marginal distributions exactly, but it also keeps any
+// association explained by the strata, so the null distribution only
+// reflects dependence within strata. With a single stratum it equals the
+// plain permutation test for the same seed.
+func StratifiedMutualInformationSignificance(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, strata []int, permutations int, seed int64) (mi, pValue float64, err error) {
+	if permutations < 1 {
+		return 0, 0, invalid(ErrInvalidParameter, "permutations", "there must be at least one permutation")
+	}
+	if len(strata) != len(dataY) {
+		return 0, 0, invalid(ErrSizeMismatch, "strata", "strata and data must have the same size")
+	}
+	mi, err = MutualInformation(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	members := make(map[int][]int)
+	var order []int
+	for i, s := range strata {
+		if _, ok := members[s]; !ok {
+			order = append(order, s)
+		}
+		members[s] = append(members[s], i)
+	}
+	pValue = permutationPValue(mi, permutations, seed, func(rng *rand.Rand, shuffled []float64) float64 {
+		copy(shuffled, dataY)
+		for _, s := range order {
+			idx := members[s]
+			rng.Shuffle(len(idx), func(i, j int) {
+				shuffled[idx[i]], shuffled[idx[j]] = shuffled[idx[j]], shuffled[idx[i]]
+			})
+		}
+		null, _ := MutualInformation(binsX, binsY, minX, maxX, minY, maxY, dataX, shuffled)
+		return null
+	}, len(dataY))
+	return mi, pValue, nil
+}
+
+// SurrogateSummary summarizes the null distribution of the MI of one shift.
+type SurrogateSummary struct {
+	Mean   float64
+	StdDev float64
+	// Q95 is the 95% quantile, a threshold for significance at the 5% level.
+	Q95 float64
+	Max float64
+}
+
+// ShiftSignificance is the observed MI of one shift with its permutation
+// p-value and the summary of its surrogates.
+type ShiftSignificance struct {
+	Shift      int
+	MI         float64
+	PValue     float64
+	Surrogates SurrogateSummary
+}
+
+// SignificanceTest runs ShiftedMutualInformation and tests every shift
+// against surrogates obtained by shuffling dataY permutations times. Each
+// shuffle is evaluated at all shifts, so the shifts share their surrogates,
+// and the shuffles are spread across GOMAXPROCS goroutines. The p-value of a
+// shift is the fraction of surrogates with an MI greater or equal to the
+// observed one. Results only depend on seed, not on GOMAXPROCS.
+//
+// With many shifts, some will reach small p-values by chance; compare the
+// peak against the largest surrogate MI over all shifts or correct the
+// p-values for the number of shifts.
+func SignificanceTest(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep, permutations int, seed int64) ([]ShiftSignificance, error) {
+	return SignificanceTestWithSurrogates(shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, permutations, seed, ShuffleSurrogate)
+}
+
+// SignificanceTestWithSurrogates is SignificanceTest with the surrogates of
+// dataY drawn by surrogate instead of shuffling, e.g. PhaseSurrogate or
+// IAAFTSurrogate for autocorrelated series.
+func SignificanceTestWithSurrogates(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep, permutations int, seed int64, surrogate SurrogateFunc) ([]ShiftSignificance, error) {
+	return SignificanceTestWithProgress(shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, permutations, seed, surrogate, nil)
+}
+
+// SignificanceTestWithProgress is SignificanceTestWithSurrogates calling
+// progress after every surrogate.
+func SignificanceTestWithProgress(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep, permutations int, seed int64, surrogate SurrogateFunc, progress ProgressFunc) ([]ShiftSignificance, error) {
+	if surrogate == nil {
+		return nil, invalid(ErrInvalidParameter, "surrogate", "surrogate must not be nil")
+	}
+	if permutations < 1 {
+		return nil, invalid(ErrInvalidParameter, "permutations", "there must be at least one permutation")
+	}
+	mi, err := ShiftedMutualInformation(shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep)
+	if err != nil {
+		return nil, err
+	}
+
+	nulls := make([][]float64, permutations)
+	tick := progressTicker(progress, permutations)
+	perms := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shuffled := make([]float64, len(dataY))
+			for p := range perms {
+				surrogate(shuffled, dataY, permutationRand(seed, p))
+				nulls[p], _ = shiftSweep(context.Background(), shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, nil, nil, SlicePairs{X: dataX, Y: shuffled}, shiftStep, ShiftOptions{Workers: 1})
+				tick()
+			}
+		}()
+	}
+	for p := 0; p < permutations; p++ {
+		perms <- p
+	}
+	close(perms)
+	wg.Wait()
+
+	results := make([]ShiftSignificance, len(mi))
+	surrogates := make([]float64, permutations)
+	for s := range mi {
+		exceed := 0
+		var sum neumaierSum
+		for p := range nulls {
+			surrogates[p] = nulls[p][s]
+			sum.Add(surrogates[p])
+			if surrogates[p] >= mi[s] {
+				exceed++
+			}
+		}
+		mean := sum.Value() / float64(permutations)
+		sorted := sortedCopy(surrogates)
+		results[s] = ShiftSignificance{
+			Shift:  shiftFrom + s*shiftStep,
+			MI:     mi[s],
+			PValue: float64(exceed) / float64(permutations),
+			Surrogates: SurrogateSummary{
+				Mean:   mean,
+				StdDev: math.Sqrt(variance(surrogates)),
+				Q95:    quantile(sorted, 0.95),
+				Max:    sorted[len(sorted)-1],
+			},
+		}
+	}
+	return results, nil
+}
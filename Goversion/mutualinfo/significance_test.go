@@ -0,0 +1,157 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestMutualInformationSignificanceReproducible(t *testing.T) {
+	rng := rand.New(rand.NewSource(13))
+	dataX := make([]float64, 500)
+	dataY := make([]float64, 500)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.2*dataX[i] + 0.8*rng.Float64()
+	}
+
+	previous := runtime.GOMAXPROCS(1)
+	defer runtime.GOMAXPROCS(previous)
+	_, p1, err := MutualInformationSignificance(5, 5, 0, 1, 0, 1, dataX, dataY, 200, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.GOMAXPROCS(4)
+	_, p4, err := MutualInformationSignificance(5, 5, 0, 1, 0, 1, dataX, dataY, 200, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p1 != p4 {
+		t.Errorf("p-value depends on GOMAXPROCS: %v vs %v", p1, p4)
+	}
+}
+
+func TestStratifiedMutualInformationSignificance(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	n := 600
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	strata := make([]int, n)
+	for i := range dataX {
+		// Both series only depend on the session, not on each other.
+		strata[i] = i % 3
+		offset := float64(strata[i]) / 3
+		dataX[i] = offset + rng.Float64()/3
+		dataY[i] = offset + rng.Float64()/3
+	}
+	_, plain, err := MutualInformationSignificance(6, 6, 0, 1, 0, 1, dataX, dataY, 200, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, stratified, err := StratifiedMutualInformationSignificance(6, 6, 0, 1, 0, 1, dataX, dataY, strata, 200, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain > 0.01 || stratified < 0.05 {
+		t.Errorf("expected the session effect to be significant only for the plain shuffle: plain %v, stratified %v", plain, stratified)
+	}
+
+	single := make([]int, n)
+	_, p1, _ := StratifiedMutualInformationSignificance(6, 6, 0, 1, 0, 1, dataX, dataY, single, 50, 2)
+	_, p2, _ := MutualInformationSignificance(6, 6, 0, 1, 0, 1, dataX, dataY, 50, 2)
+	if p1 != p2 {
+		t.Errorf("single stratum should match the plain test: %v vs %v", p1, p2)
+	}
+}
+
+func TestMutualInformationSignificanceEdgeCases(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	data := make([]float64, 300)
+	independent := make([]float64, 300)
+	for i := range data {
+		data[i] = rng.Float64()
+		independent[i] = rng.Float64()
+	}
+
+	mi, p, err := MutualInformationSignificance(5, 5, 0, 1, 0, 1, data, data, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mi < 2 || p != 0 {
+		t.Errorf("identical data: MI %v, p-value %v, want MI log2(5) and p 0", mi, p)
+	}
+
+	constant := make([]float64, 300)
+	if _, p, err := MutualInformationSignificance(5, 5, 0, 1, 0, 1, data, constant, 50, 1); err != nil || p != 1 {
+		t.Errorf("constant dataY: p-value %v, %v, want 1", p, err)
+	}
+
+	if _, p, err := MutualInformationSignificance(5, 5, 0, 1, 0, 1, data, independent, 200, 1); err != nil || p < 0.05 {
+		t.Errorf("independent data: p-value %v, %v, want not significant", p, err)
+	}
+
+	for _, permutations := range []int{0, -3} {
+		if _, _, err := MutualInformationSignificance(5, 5, 0, 1, 0, 1, data, data, permutations, 1); err == nil {
+			t.Errorf("expected error for %d permutations", permutations)
+		}
+	}
+}
+
+func TestSignificanceTest(t *testing.T) {
+	rng := rand.New(rand.NewSource(33))
+	dataX := make([]float64, 600)
+	dataY := make([]float64, 600)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+	}
+	for j := range dataY {
+		dataY[j] = rng.Float64()
+		if j >= 2 {
+			dataY[j] = 0.7*dataX[j-2] + 0.3*dataY[j]
+		}
+	}
+
+	results, err := SignificanceTest(-3, 3, 6, 6, 0, 1, 0, 1, dataX, dataY, 1, 100, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 7 {
+		t.Fatalf("got %d results, want 7", len(results))
+	}
+	for _, r := range results {
+		if r.Shift == -2 {
+			if r.PValue != 0 || r.MI <= r.Surrogates.Max {
+				t.Errorf("coupled shift: %+v, want p-value 0 above all surrogates", r)
+			}
+			continue
+		}
+		if r.MI > 2*r.Surrogates.Q95 {
+			t.Errorf("uncoupled shift %d: MI %v far above the surrogate Q95 %v", r.Shift, r.MI, r.Surrogates.Q95)
+		}
+		if !(r.Surrogates.Mean > 0 && r.Surrogates.StdDev > 0 && r.Surrogates.Mean <= r.Surrogates.Q95 && r.Surrogates.Q95 <= r.Surrogates.Max) {
+			t.Errorf("shift %d: inconsistent summary %+v", r.Shift, r.Surrogates)
+		}
+	}
+
+	previous := runtime.GOMAXPROCS(1)
+	defer runtime.GOMAXPROCS(previous)
+	again, _ := SignificanceTest(-3, 3, 6, 6, 0, 1, 0, 1, dataX, dataY, 1, 100, 7)
+	for i := range again {
+		if again[i] != results[i] {
+			t.Errorf("shift %d depends on GOMAXPROCS: %+v vs %+v", again[i].Shift, again[i], results[i])
+		}
+	}
+	if _, err := SignificanceTest(-3, 3, 6, 6, 0, 1, 0, 1, dataX, dataY, 1, 0, 7); err == nil {
+		t.Error("expected error for zero permutations")
+	}
+}
+
+func TestPermutationRand(t *testing.T) {
+	if a, b := permutationRand(5, 3).Int63(), permutationRand(5, 3).Int63(); a != b {
+		t.Errorf("same seed and permutation give %d and %d", a, b)
+	}
+	// With seed+perm, permutation 1 of seed 0 was permutation 0 of seed 1.
+	if a, b := permutationRand(0, 1).Int63(), permutationRand(1, 0).Int63(); a == b {
+		t.Error("nearby seeds share their permutations")
+	}
+}
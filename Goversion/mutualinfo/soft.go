@@ -0,0 +1,100 @@
+package mutualinfo
+
+import (
+	"math"
+	"sync"
+)
+
+// SoftHistogram2D is a 2D histogram in which every pair is spread over the
+// nearest bins with a triangular kernel instead of landing fully in one bin.
+type SoftHistogram2D struct {
+	BinsX int
+	BinsY int
+	MinX  float64
+	MaxX  float64
+	MinY  float64
+	MaxY  float64
+	Data  [][]float64
+	Mutex sync.Mutex
+}
+
+func NewSoftHistogram2D(binsX, binsY int, minX, maxX, minY, maxY float64) *SoftHistogram2D {
+	data := make([][]float64, binsX)
+	for i := range data {
+		data[i] = make([]float64, binsY)
+	}
+	return &SoftHistogram2D{
+		BinsX: binsX,
+		BinsY: binsY,
+		MinX:  minX,
+		MaxX:  maxX,
+		MinY:  minY,
+		MaxY:  maxY,
+		Data:  data,
+	}
+}
+
+// softBins returns the two bins nearest to value and the weight of the
+// upper one, interpolating linearly between the bin centers. Values within
+// half a bin of a boundary go fully to the outermost bin. ok is false if
+// value lies outside [min, max] or is NaN.
+func softBins(value, min, max float64, bins int) (lower, upper int, weight float64, ok bool) {
+	if !(value >= min && value <= max) {
+		return 0, 0, 0, false
+	}
+	u := (value-min)/(max-min)*float64(bins) - 0.5
+	if u <= 0 {
+		return 0, 0, 0, true
+	}
+	if u >= float64(bins-1) {
+		return bins - 1, bins - 1, 0, true
+	}
+	lower = int(math.Floor(u))
+	return lower, lower + 1, u - float64(lower), true
+}
+
+// Increment spreads the pair (x, y) over up to four neighbouring cells with
+// a total weight of one. Pairs with a value outside the histogram ranges are
+// ignored.
+func (h *SoftHistogram2D) Increment(x, y float64) {
+	lowX, highX, wX, okX := softBins(x, h.MinX, h.MaxX, h.BinsX)
+	lowY, highY, wY, okY := softBins(y, h.MinY, h.MaxY, h.BinsY)
+	if !okX || !okY {
+		return
+	}
+
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.Data[lowX][lowY] += (1 - wX) * (1 - wY)
+	h.Data[lowX][highY] += (1 - wX) * wY
+	h.Data[highX][lowY] += wX * (1 - wY)
+	h.Data[highX][highY] += wX * wY
+}
+
+// CalculateMutualInformation returns the mutual information in bits of the
+// soft counts, or zero if no pair has been counted.
+func (h *SoftHistogram2D) CalculateMutualInformation() float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	mi, err := MutualInformationFromJoint(h.Data)
+	if err != nil {
+		return 0
+	}
+	return mi
+}
+
+// SoftMutualInformation calculates the mutual information of dataX and dataY
+// like MutualInformation, but with soft bin assignment: each value
+// contributes to its two nearest bins in proportion to its distance from
+// their centers. The estimate then changes smoothly as the ranges or the
+// data move, rather than jumping whenever a value crosses a bin edge.
+func SoftMutualInformation(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return 0, err
+	}
+	hist := NewSoftHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	for i := range dataX {
+		hist.Increment(dataX[i], dataY[i])
+	}
+	return hist.CalculateMutualInformation(), nil
+}
@@ -0,0 +1,73 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSoftBins(t *testing.T) {
+	cases := []struct {
+		value        float64
+		lower, upper int
+		weight       float64
+	}{
+		{0, 0, 0, 0},
+		{0.25, 0, 0, 0},
+		{0.5, 0, 0, 0},
+		{1.5, 1, 2, 0},
+		{1.75, 1, 2, 0.25},
+		{2, 1, 2, 0.5},
+		{4, 3, 3, 0},
+	}
+	for _, c := range cases {
+		lower, upper, weight, ok := softBins(c.value, 0, 4, 4)
+		if !ok || lower != c.lower || upper != c.upper || !almostEqual(weight, c.weight, 1e-12) {
+			t.Errorf("softBins(%v) = %d, %d, %v, %v", c.value, lower, upper, weight, ok)
+		}
+	}
+	for _, value := range []float64{4.1, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, _, _, ok := softBins(value, 0, 4, 4); ok {
+			t.Errorf("expected out of range for %v", value)
+		}
+	}
+	mi, err := SoftMutualInformation(4, 4, 0, 5, 0, 5, []float64{1, 2, math.NaN(), 4}, []float64{1, 2, 3, math.Inf(1)})
+	if err != nil || math.IsNaN(mi) {
+		t.Errorf("NaN and infinite values: got %v, %v", mi, err)
+	}
+}
+
+func TestSoftMutualInformationSmooth(t *testing.T) {
+	n := 2000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = float64(i) / float64(n)
+		dataY[i] = math.Sin(2 * math.Pi * dataX[i])
+	}
+
+	// Nudging the range changes the soft estimate continuously.
+	previous := math.NaN()
+	maxStep := 0.0
+	for k := 0; k <= 20; k++ {
+		offset := 0.001 * float64(k)
+		mi, err := SoftMutualInformation(8, 8, -offset, 1+offset, -1, 1, dataX, dataY)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !math.IsNaN(previous) {
+			maxStep = math.Max(maxStep, math.Abs(mi-previous))
+		}
+		previous = mi
+	}
+	if maxStep > 0.01 {
+		t.Errorf("soft MI jumps by %v between nearby ranges", maxStep)
+	}
+
+	independent, err := SoftMutualInformation(4, 4, 0, 1, 0, 1, []float64{0.1, 0.1, 0.9, 0.9}, []float64{0.1, 0.9, 0.1, 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(independent, 0, 1e-12) {
+		t.Errorf("independent soft MI = %v, want 0", independent)
+	}
+}
@@ -0,0 +1,36 @@
+package mutualinfo
+
+// XYSource provides indexed access to paired samples without requiring
+// them to be held in slices, e.g. backed by a memory-mapped file, a database
+// cursor or a generator.
+type XYSource interface {
+	Len() int
+	At(i int) (x, y float64)
+}
+
+// SlicePairs is an XYSource backed by two slices of the same size.
+type SlicePairs struct {
+	X, Y []float64
+}
+
+func (s SlicePairs) Len() int {
+	return len(s.X)
+}
+
+func (s SlicePairs) At(i int) (x, y float64) {
+	return s.X[i], s.Y[i]
+}
+
+// NumberPairs is an XYSource backed by two slices of the same size and any
+// Number type, converting values to float64 on access.
+type NumberPairs[T Number] struct {
+	X, Y []T
+}
+
+func (s NumberPairs[T]) Len() int {
+	return len(s.X)
+}
+
+func (s NumberPairs[T]) At(i int) (x, y float64) {
+	return float64(s.X[i]), float64(s.Y[i])
+}
@@ -0,0 +1,41 @@
+package mutualinfo
+
+import (
+	"math"
+	"testing"
+)
+
+// sineSource generates its samples on demand.
+type sineSource struct{ n int }
+
+func (s sineSource) Len() int { return s.n }
+
+func (s sineSource) At(i int) (x, y float64) {
+	return math.Sin(float64(i) * 0.1), math.Sin(float64(i-5) * 0.1)
+}
+
+func TestSourceMatchesSlices(t *testing.T) {
+	src := sineSource{n: 1000}
+	dataX := make([]float64, src.n)
+	dataY := make([]float64, src.n)
+	for i := range dataX {
+		dataX[i], dataY[i] = src.At(i)
+	}
+
+	want, _ := ShiftedMutualInformation(-10, 10, 8, 8, -1, 1, -1, 1, dataX, dataY, 2)
+	got, err := ShiftedMutualInformationSource(-10, 10, 8, 8, -1, 1, -1, 1, src, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("shift %d: source %v, slices %v", -10+2*i, got[i], want[i])
+		}
+	}
+
+	wantMI, _ := MutualInformation(8, 8, -1, 1, -1, 1, dataX, dataY)
+	gotMI, err := MutualInformationSource(8, 8, -1, 1, -1, 1, src)
+	if err != nil || gotMI != wantMI {
+		t.Errorf("MutualInformationSource = %v, %v, want %v", gotMI, err, wantMI)
+	}
+}
@@ -0,0 +1,191 @@
+package mutualinfo
+
+import (
+	"math"
+	"sync"
+)
+
+// SparseHistogram2D is a Histogram2D storing only the occupied cells in a
+// map. With many bins and comparatively few pairs, e.g. 500x500 bins, it
+// needs far less memory and its entropies only visit occupied cells.
+type SparseHistogram2D struct {
+	BinsX  int
+	BinsY  int
+	MinX   float64
+	MaxX   float64
+	MinY   float64
+	MaxY   float64
+	EdgesX []float64
+	EdgesY []float64
+	Counts map[IndexPair]int
+	// OutOfRange counts the pairs passed to Increment with a value outside
+	// the ranges, which are not counted in Counts.
+	OutOfRange int
+	Mutex      sync.Mutex
+}
+
+func NewSparseHistogram2D(binsX, binsY int, minX, maxX, minY, maxY float64) *SparseHistogram2D {
+	return &SparseHistogram2D{
+		BinsX:  binsX,
+		BinsY:  binsY,
+		MinX:   minX,
+		MaxX:   maxX,
+		MinY:   minY,
+		MaxY:   maxY,
+		Counts: make(map[IndexPair]int),
+	}
+}
+
+// Increment counts the pair (x, y) with the same binning as
+// Histogram2D.Increment.
+func (h *SparseHistogram2D) Increment(x, y float64) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.increment(x, y)
+}
+
+func (h *SparseHistogram2D) increment(x, y float64) {
+	indexX := axisIndex(x, h.EdgesX, h.MinX, h.MaxX, h.BinsX)
+	indexY := axisIndex(y, h.EdgesY, h.MinY, h.MaxY, h.BinsY)
+	h.addIndex(indexX, indexY)
+}
+
+// axisIndex returns the bin of value by edges if they are set and by
+// equally wide bins over [min, max] otherwise, -1 if it is outside.
+func axisIndex(value float64, edges []float64, min, max float64, bins int) int {
+	if edges != nil {
+		return edgeIndex(edges, value)
+	}
+	return binIndex(value, min, max, bins)
+}
+
+func (h *SparseHistogram2D) addIndex(indexX, indexY int) {
+	if indexX < 0 || indexY < 0 {
+		h.OutOfRange++
+		return
+	}
+	h.Counts[IndexPair{First: indexX, Second: indexY}]++
+}
+
+func (h *SparseHistogram2D) counted() int {
+	total := 0
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+func (h *SparseHistogram2D) reset(minX, maxX, minY, maxY float64) {
+	for cell := range h.Counts {
+		delete(h.Counts, cell)
+	}
+	h.OutOfRange = 0
+	h.MinX, h.MaxX, h.MinY, h.MaxY = minX, maxX, minY, maxY
+}
+
+// Entropies returns H(X), H(Y) and H(X,Y) in bits like Histogram2D.Entropies,
+// NaN for an empty histogram.
+func (h *SparseHistogram2D) Entropies() (hx, hy, hxy float64) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	if h.counted() == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	rows := make(map[int]int)
+	cols := make(map[int]int)
+	for cell, c := range h.Counts {
+		rows[cell.First] += c
+		cols[cell.Second] += c
+	}
+	return countsEntropy(rows), countsEntropy(cols), countsEntropy(h.Counts)
+}
+
+// CalculateMutualInformation returns the mutual information in bits, NaN
+// for an empty histogram like Histogram2D.
+func (h *SparseHistogram2D) CalculateMutualInformation() float64 {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	if h.counted() == 0 {
+		return math.NaN()
+	}
+	return sparseMutualInformation(h.Counts, nil)
+}
+
+// SparseMIResult holds the result of SparseMutualInformation.
+type SparseMIResult struct {
+	MI float64
+	// MIWithoutBaseline is the MI of the distribution with the baseline cell
+	// removed and the remaining cells renormalized.
+	MIWithoutBaseline float64
+	// BaselineX and BaselineY are the bins of the most populated cell, which in
+	// rare-event data is typically the joint absence of both events.
+	BaselineX, BaselineY int
+	BaselineMass         float64
+}
+
+// sparseMutualInformation calculates the mutual information in bits of the
+// occupied cells in counts, skipping the cell skip if it is not nil.
+func sparseMutualInformation(counts map[IndexPair]int, skip *IndexPair) float64 {
+	rows := make(map[int]int)
+	cols := make(map[int]int)
+	total := 0
+	for cell, c := range counts {
+		if skip != nil && cell == *skip {
+			continue
+		}
+		rows[cell.First] += c
+		cols[cell.Second] += c
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	n := float64(total)
+	var mi neumaierSum
+	for cell, c := range counts {
+		if skip != nil && cell == *skip {
+			continue
+		}
+		p := float64(c) / n
+		mi.Add(p * math.Log2(p*n*n/(float64(rows[cell.First])*float64(cols[cell.Second]))))
+	}
+	return mi.Value()
+}
+
+// SparseMutualInformation calculates the mutual information using a map of
+// the occupied cells only, which suits large bin counts with mostly empty
+// cells. It additionally reports the MI without the dominant baseline cell,
+// which separates dependence driven by joint absence from dependence driven
+// by joint presence. Pairs outside the ranges are ignored.
+func SparseMutualInformation(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (SparseMIResult, error) {
+	indices, err := CalculateIndices2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY)
+	if err != nil {
+		return SparseMIResult{}, err
+	}
+	counts := make(map[IndexPair]int)
+	total := 0
+	for _, cell := range indices {
+		if cell.First >= 0 {
+			counts[cell]++
+			total++
+		}
+	}
+	if total == 0 {
+		return SparseMIResult{}, invalid(ErrTooFewSamples, "data", "no pair lies within the given ranges")
+	}
+
+	var baseline IndexPair
+	best := 0
+	for cell, c := range counts {
+		if c > best || (c == best && (cell.First < baseline.First || (cell.First == baseline.First && cell.Second < baseline.Second))) {
+			baseline, best = cell, c
+		}
+	}
+	return SparseMIResult{
+		MI:                sparseMutualInformation(counts, nil),
+		MIWithoutBaseline: sparseMutualInformation(counts, &baseline),
+		BaselineX:         baseline.First,
+		BaselineY:         baseline.Second,
+		BaselineMass:      float64(best) / float64(total),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSparseMutualInformation(t *testing.T) {
+	// Mostly joint absence (0, 0), with rare coupled events.
+	var dataX, dataY []float64
+	for i := 0; i < 1000; i++ {
+		x, y := 0.0, 0.0
+		switch i % 20 {
+		case 0:
+			x, y = 50, 50
+		case 1:
+			x, y = 90, 90
+		}
+		dataX = append(dataX, x)
+		dataY = append(dataY, y)
+	}
+	result, err := SparseMutualInformation(100, 100, 0, 100, 0, 100, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dense, _ := MutualInformation(100, 100, 0, 100, 0, 100, dataX, dataY)
+	if !almostEqual(result.MI, dense, 1e-12) {
+		t.Errorf("sparse MI %v differs from dense MI %v", result.MI, dense)
+	}
+	if result.BaselineX != 0 || result.BaselineY != 0 || !almostEqual(result.BaselineMass, 0.9, 1e-12) {
+		t.Errorf("unexpected baseline %+v", result)
+	}
+	if !almostEqual(result.MIWithoutBaseline, 1, 1e-12) {
+		t.Errorf("expected 1 bit among the two event types, got %v", result.MIWithoutBaseline)
+	}
+}
+
+func TestSparseHistogram2D(t *testing.T) {
+	rng := rand.New(rand.NewSource(38))
+	dataX := make([]float64, 2000)
+	dataY := make([]float64, 2000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.7*dataX[i] + 0.3*rng.Float64()
+	}
+	dataX[3] = 2
+
+	dense := NewHistogram2D(50, 50, 0, 1, 0, 1)
+	sparse := NewSparseHistogram2D(50, 50, 0, 1, 0, 1)
+	for i := range dataX {
+		dense.Increment(dataX[i], dataY[i])
+		sparse.Increment(dataX[i], dataY[i])
+	}
+	if got, want := sparse.CalculateMutualInformation(), dense.CalculateMutualInformation(); !almostEqual(got, want, 1e-9) {
+		t.Errorf("sparse MI %v, dense %v", got, want)
+	}
+	if sparse.OutOfRange != 1 {
+		t.Errorf("OutOfRange = %d, want 1", sparse.OutOfRange)
+	}
+
+	for _, opts := range []ShiftOptions{{}, {Normalization: NormalizationSqrt}, {Binning: BinningQuantile}} {
+		want, err := ShiftedMutualInformationWithOptions(-5, 5, 40, 40, 0, 1, 0, 1, dataX, dataY, 1, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.Sparse = true
+		got, err := ShiftedMutualInformationWithOptions(-5, 5, 40, 40, 0, 1, 0, 1, dataX, dataY, 1, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if !almostEqual(got[i], want[i], 1e-9) {
+				t.Errorf("%+v, shift %d: sparse %v, dense %v", opts, i-5, got[i], want[i])
+			}
+		}
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-5, 5, 40, 40, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Sparse: true, BiasCorrection: BiasJackknife}); err == nil {
+		t.Error("expected error for a bias correction with sparse histograms")
+	}
+}
+
+func TestSparseHistogram2DEmpty(t *testing.T) {
+	sparse := NewSparseHistogram2D(4, 4, 0, 1, 0, 1)
+	dense := NewHistogram2D(4, 4, 0, 1, 0, 1)
+	if hx, hy, hxy := sparse.Entropies(); !math.IsNaN(hx) || !math.IsNaN(hy) || !math.IsNaN(hxy) {
+		t.Errorf("empty entropies %v %v %v, want NaN", hx, hy, hxy)
+	}
+	if mi := sparse.CalculateMutualInformation(); !math.IsNaN(mi) || !math.IsNaN(dense.CalculateMutualInformation()) {
+		t.Errorf("empty MI %v, want NaN like dense", mi)
+	}
+
+	// The trailing NaNs leave shifts 6 and 7 without pairs.
+	dataX := []float64{0.1, 0.4, 0.7, 0.2, 0.9, 0.5, math.NaN(), math.NaN()}
+	dataY := []float64{0.3, 0.8, 0.1, 0.6, 0.2, 0.9, 0.4, 0.7}
+	for _, norm := range []Normalization{NormalizationNone, NormalizationJoint} {
+		want, err := ShiftedMutualInformationResult(0, 7, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Normalization: norm})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ShiftedMutualInformationResult(0, 7, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Normalization: norm, Sparse: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, shift := range want.Shifts {
+			for _, v := range [][2]float64{{got.MI[i], want.MI[i]}, {got.HX[i], want.HX[i]}, {got.HY[i], want.HY[i]}, {got.HXY[i], want.HXY[i]}} {
+				if math.IsNaN(v[0]) != math.IsNaN(v[1]) || !math.IsNaN(v[1]) && !almostEqual(v[0], v[1], 1e-12) {
+					t.Errorf("normalization %v, shift %d: sparse %v, dense %v", norm, shift, v[0], v[1])
+				}
+			}
+		}
+		if !math.IsNaN(want.MI[6]) || !math.IsNaN(want.MI[7]) {
+			t.Errorf("MI of the empty shifts %v, want NaN", want.MI[6:])
+		}
+	}
+}
+
+func benchmarkShiftedBackend(b *testing.B, sparse bool) {
+	rng := rand.New(rand.NewSource(1))
+	dataX := make([]float64, 5000)
+	dataY := make([]float64, 5000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	opts := ShiftOptions{Sparse: sparse}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ShiftedMutualInformationWithOptions(-20, 20, 500, 500, 0, 1, 0, 1, dataX, dataY, 1, opts)
+	}
+}
+
+func BenchmarkShiftedMutualInformationDense500(b *testing.B)  { benchmarkShiftedBackend(b, false) }
+func BenchmarkShiftedMutualInformationSparse500(b *testing.B) { benchmarkShiftedBackend(b, true) }
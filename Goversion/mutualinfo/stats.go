@@ -0,0 +1,116 @@
+package mutualinfo
+
+import (
+	"math"
+	"sort"
+)
+
+// sortedCopy returns a sorted copy of data.
+func sortedCopy(data []float64) []float64 {
+	sorted := append([]float64(nil), data...)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// quantile returns the p-quantile of sorted data using linear interpolation
+// between the closest ranks. sorted must not be empty.
+func quantile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
+
+// winsorize clips data to the tail and 1-tail quantiles of its finite
+// values and returns the clipped copy together with the bounds. NaN is
+// kept.
+func winsorize(data []float64, tail float64) ([]float64, float64, float64, error) {
+	finite := finiteValues(data)
+	if len(finite) == 0 {
+		return nil, 0, 0, invalid(ErrTooFewSamples, "data", "data holds no finite value")
+	}
+	sorted := sortedCopy(finite)
+	lo := quantile(sorted, tail)
+	hi := quantile(sorted, 1-tail)
+	if lo >= hi {
+		return nil, 0, 0, invalid(ErrInvalidRange, "data", "winsorized data has zero range")
+	}
+	clipped := make([]float64, len(data))
+	for i, v := range data {
+		switch {
+		case v < lo:
+			clipped[i] = lo
+		case v > hi:
+			clipped[i] = hi
+		default:
+			clipped[i] = v
+		}
+	}
+	return clipped, lo, hi, nil
+}
+
+// ranks returns the 1-based ranks of data, assigning tied values their average rank.
+func ranks(data []float64) []float64 {
+	order := make([]int, len(data))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return data[order[a]] < data[order[b]] })
+
+	r := make([]float64, len(data))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && data[order[end]] == data[order[start]] {
+			end++
+		}
+		rank := float64(start+end+1) / 2
+		for k := start; k < end; k++ {
+			r[order[k]] = rank
+		}
+		start = end
+	}
+	return r
+}
+
+// pearson returns the sample correlation coefficient of x and y, or 0 if
+// either has zero variance. x and y must have the same size.
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx := x[i] - meanX
+		dy := y[i] - meanY
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// minMax returns the smallest and largest value of non-empty data.
+func minMax(data []float64) (float64, float64) {
+	lo, hi := data[0], data[0]
+	for _, v := range data[1:] {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
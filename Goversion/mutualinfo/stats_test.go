@@ -0,0 +1,42 @@
+package mutualinfo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	cases := []struct{ p, want float64 }{{0, 1}, {0.5, 3}, {0.125, 1.5}, {1, 5}}
+	for _, c := range cases {
+		if got := quantile(sorted, c.p); !almostEqual(got, c.want, 1e-12) {
+			t.Errorf("quantile(%v) = %v, want %v", c.p, got, c.want)
+		}
+	}
+}
+
+func TestWinsorize(t *testing.T) {
+	data := []float64{100, 1, 2, 3, 4, 5, 6, 7, 8, -100}
+	clipped, lo, hi, err := winsorize(data, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clipped[0] != hi || clipped[9] != lo || clipped[1] != 1 || len(clipped) != len(data) {
+		t.Errorf("unexpected clipping %v with bounds %v, %v", clipped, lo, hi)
+	}
+	wantLo, wantHi := lo, hi
+	if _, _, _, err := winsorize([]float64{1, 1, 1}, 0.1); err == nil {
+		t.Error("expected error for constant data")
+	}
+
+	// NaN, which sorts first, must not become the lower bound.
+	nan := math.NaN()
+	clipped, lo, hi, err = winsorize(append([]float64{nan, nan, nan}, data...), 0.1)
+	if err != nil || lo != wantLo || hi != wantHi || !math.IsNaN(clipped[0]) || clipped[3] != hi {
+		t.Errorf("with NaN: clipping %v with bounds %v, %v, %v, want %v, %v", clipped, lo, hi, err, wantLo, wantHi)
+	}
+	if _, _, _, err := winsorize([]float64{nan, math.Inf(1)}, 0.1); !errors.Is(err, ErrTooFewSamples) {
+		t.Errorf("no finite value: got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package mutualinfo
+
+import (
+	"math"
+	"sync"
+)
+
+// streamingResync is the number of updates after which StreamingMI
+// recomputes its running sums from the counts to stop rounding drift.
+const streamingResync = 1 << 20
+
+// StreamingMI counts a live stream of (x, y) pairs, optionally only the most
+// recent ones, and returns the current MI in O(1). It keeps the sums of
+// c*log2(c) over the rows, columns and cells of the histogram, which every
+// added or evicted pair changes in a single term.
+type StreamingMI struct {
+	binsX, binsY int
+	minX, maxX   float64
+	minY, maxY   float64
+	cells        []int
+	rows, cols   []int
+	n            int
+	sx, sy, sxy  float64
+	// window holds the cells of the last pairs, -1 for skipped ones, in a
+	// ring starting at next. It is nil without eviction.
+	window     []int
+	next       int
+	filled     bool
+	outOfRange int
+	updates    int
+	mutex      sync.Mutex
+}
+
+// NewStreamingMI creates a streaming estimator with the same binning as
+// NewHistogram2D. If window is positive, only the last window pairs passed
+// to AddPair count, including skipped ones, so the window always spans the
+// same stretch of the stream. A window of 0 counts all pairs.
+func NewStreamingMI(binsX, binsY int, minX, maxX, minY, maxY float64, window int) (*StreamingMI, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, nil, nil); err != nil {
+		return nil, err
+	}
+	if window < 0 {
+		return nil, invalid(ErrInvalidParameter, "window", "window must not be negative")
+	}
+	s := &StreamingMI{
+		binsX: binsX,
+		binsY: binsY,
+		minX:  minX,
+		maxX:  maxX,
+		minY:  minY,
+		maxY:  maxY,
+		cells: make([]int, binsX*binsY),
+		rows:  make([]int, binsX),
+		cols:  make([]int, binsY),
+	}
+	if window > 0 {
+		s.window = make([]int, window)
+	}
+	return s, nil
+}
+
+// AddPair counts the pair (x, y) and evicts the oldest pair once the window
+// is full. Pairs with a value outside the ranges, NaN or infinite are
+// skipped and counted in OutOfRange.
+func (s *StreamingMI) AddPair(x, y float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cell := -1
+	indexX := binIndex(x, s.minX, s.maxX, s.binsX)
+	indexY := binIndex(y, s.minY, s.maxY, s.binsY)
+	if indexX >= 0 && indexY >= 0 {
+		cell = indexX*s.binsY + indexY
+		s.update(cell, 1)
+	} else {
+		s.outOfRange++
+	}
+	if s.window != nil {
+		if s.filled && s.window[s.next] >= 0 {
+			s.update(s.window[s.next], -1)
+		}
+		s.window[s.next] = cell
+		s.next++
+		if s.next == len(s.window) {
+			s.next, s.filled = 0, true
+		}
+	}
+
+	s.updates++
+	if s.updates == streamingResync {
+		s.resync()
+	}
+}
+
+// update adds delta to the count of cell and its row and column.
+func (s *StreamingMI) update(cell, delta int) {
+	i, j := cell/s.binsY, cell%s.binsY
+	s.sx += cLogC(s.rows[i]+delta) - cLogC(s.rows[i])
+	s.sy += cLogC(s.cols[j]+delta) - cLogC(s.cols[j])
+	s.sxy += cLogC(s.cells[cell]+delta) - cLogC(s.cells[cell])
+	s.rows[i] += delta
+	s.cols[j] += delta
+	s.cells[cell] += delta
+	s.n += delta
+}
+
+func (s *StreamingMI) resync() {
+	s.sx, s.sy, s.sxy = 0, 0, 0
+	for _, c := range s.rows {
+		s.sx += cLogC(c)
+	}
+	for _, c := range s.cols {
+		s.sy += cLogC(c)
+	}
+	for _, c := range s.cells {
+		s.sxy += cLogC(c)
+	}
+	s.updates = 0
+}
+
+// MI returns the mutual information in bits of the pairs currently counted,
+// 0 if there are none.
+func (s *StreamingMI) MI() float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.n == 0 {
+		return 0
+	}
+	n := float64(s.n)
+	// Rounding of the running sums may leave a tiny negative value.
+	return math.Max(math.Log2(n)-(s.sx+s.sy-s.sxy)/n, 0)
+}
+
+// N returns the number of pairs currently counted.
+func (s *StreamingMI) N() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.n
+}
+
+// OutOfRange returns the number of skipped pairs passed to AddPair so far,
+// including those already evicted from the window.
+func (s *StreamingMI) OutOfRange() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.outOfRange
+}
+
+// WindowedMutualInformation calculates the mutual information of
+// consecutive windows of windowSize pairs, starting every stride samples, to
+// follow how the coupling of two signals evolves. Overlapping windows are
+// not rebuilt: moving to the next window only evicts the pairs that left it
+// and adds those that entered, and the sums are refreshed with one pass
+// over the cells, so the cost no longer grows with windowSize. Pairs
+// outside the given ranges are ignored; a window without any pair in range
+// has MI 0.
+func WindowedMutualInformation(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, windowSize, stride int) ([]float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return nil, err
+	}
+	if windowSize < 1 || windowSize > len(dataX) {
+		return nil, invalid(ErrInvalidParameter, "windowSize", "windowSize must be between 1 and the data size")
+	}
+	if stride < 1 {
+		return nil, invalid(ErrInvalidParameter, "stride", "stride must be greater or equal 1")
+	}
+
+	s, _ := NewStreamingMI(binsX, binsY, minX, maxX, minY, maxY, 0)
+	cells := make([]int, len(dataX))
+	for i := range dataX {
+		cells[i] = -1
+		indexX := binIndex(dataX[i], minX, maxX, binsX)
+		indexY := binIndex(dataY[i], minY, maxY, binsY)
+		if indexX >= 0 && indexY >= 0 {
+			cells[i] = indexX*binsY + indexY
+		}
+	}
+	apply := func(from, to, delta int) {
+		for i := from; i < to; i++ {
+			if cells[i] >= 0 {
+				s.update(cells[i], delta)
+			}
+		}
+	}
+
+	mi := make([]float64, 0, (len(dataX)-windowSize)/stride+1)
+	lo, hi := 0, 0 // the pairs [lo, hi) are counted
+	for start := 0; start+windowSize <= len(dataX); start += stride {
+		end := start + windowSize
+		if start >= hi {
+			apply(lo, hi, -1)
+			lo, hi = start, start
+		}
+		apply(lo, start, -1)
+		apply(hi, end, 1)
+		lo, hi = start, end
+		s.resync()
+		mi = append(mi, s.MI())
+	}
+	return mi, nil
+}
@@ -0,0 +1,105 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestStreamingMI(t *testing.T) {
+	rng := rand.New(rand.NewSource(35))
+	dataX := make([]float64, 3000)
+	dataY := make([]float64, 3000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.6*dataX[i] + 0.4*rng.Float64()
+	}
+	dataX[10] = math.NaN()
+	dataY[20] = 5
+
+	all, err := NewStreamingMI(6, 6, 0, 1, 0, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	windowed, err := NewStreamingMI(6, 6, 0, 1, 0, 1, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range dataX {
+		all.AddPair(dataX[i], dataY[i])
+		windowed.AddPair(dataX[i], dataY[i])
+		if i == 300 {
+			// The window is not full yet and holds all pairs so far.
+			want, _ := MutualInformation(6, 6, 0, 1, 0, 1, dataX[:i+1], dataY[:i+1])
+			if got := windowed.MI(); !almostEqual(got, want, 1e-9) {
+				t.Errorf("partial window MI %v, want %v", got, want)
+			}
+		}
+	}
+
+	want, _ := MutualInformation(6, 6, 0, 1, 0, 1, dataX, dataY)
+	if got := all.MI(); !almostEqual(got, want, 1e-9) {
+		t.Errorf("streaming MI %v, batch %v", got, want)
+	}
+	if all.N() != 2998 || all.OutOfRange() != 2 {
+		t.Errorf("counted %d pairs and skipped %d, want 2998 and 2", all.N(), all.OutOfRange())
+	}
+	want, _ = MutualInformation(6, 6, 0, 1, 0, 1, dataX[2500:], dataY[2500:])
+	if got := windowed.MI(); !almostEqual(got, want, 1e-9) {
+		t.Errorf("windowed MI %v, batch of the last 500 pairs %v", got, want)
+	}
+	if windowed.N() != 500 {
+		t.Errorf("window counts %d pairs, want 500", windowed.N())
+	}
+
+	empty, _ := NewStreamingMI(2, 2, 0, 1, 0, 1, 0)
+	if empty.MI() != 0 {
+		t.Errorf("empty MI %v, want 0", empty.MI())
+	}
+	if _, err := NewStreamingMI(2, 2, 0, 1, 0, 1, -1); err == nil {
+		t.Error("expected error for a negative window")
+	}
+}
+
+func TestWindowedMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(36))
+	n := 2000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+		if i >= n/2 {
+			// The signals couple in the second half.
+			dataY[i] = 0.8*dataX[i] + 0.2*dataY[i]
+		}
+	}
+	dataY[7] = math.Inf(1)
+
+	for _, c := range []struct{ windowSize, stride int }{{400, 100}, {300, 300}, {200, 350}} {
+		mi, err := WindowedMutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY, c.windowSize, c.stride)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (n-c.windowSize)/c.stride + 1; len(mi) != want {
+			t.Fatalf("window %d, stride %d: %d values, want %d", c.windowSize, c.stride, len(mi), want)
+		}
+		for w, v := range mi {
+			start := w * c.stride
+			want, _ := MutualInformation(5, 5, 0, 1, 0, 1, dataX[start:start+c.windowSize], dataY[start:start+c.windowSize])
+			if !almostEqual(v, want, 1e-9) {
+				t.Errorf("window %d, stride %d, start %d: MI %v, batch %v", c.windowSize, c.stride, start, v, want)
+			}
+		}
+		if first, last := mi[0], mi[len(mi)-1]; last < first+0.3 {
+			t.Errorf("window %d: coupling not visible, first %v, last %v", c.windowSize, first, last)
+		}
+	}
+
+	if _, err := WindowedMutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY, 0, 1); err == nil {
+		t.Error("expected error for windowSize 0")
+	}
+	if _, err := WindowedMutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY, 10, 0); err == nil {
+		t.Error("expected error for stride 0")
+	}
+}
@@ -0,0 +1,216 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"slices"
+	"sort"
+)
+
+// SurrogateFunc fills dst with a surrogate of data drawn from rng, a series
+// that keeps selected properties of data but is independent of any other
+// series. dst and data have the same size and do not overlap.
+//
+// Shuffling destroys the autocorrelation of a series, so against
+// autocorrelated data it finds spurious dependence; PhaseSurrogate,
+// IAAFTSurrogate and BlockShuffleSurrogate keep it.
+type SurrogateFunc func(dst, data []float64, rng *rand.Rand)
+
+// ShuffleSurrogate is a random permutation of data, which only keeps its
+// distribution.
+func ShuffleSurrogate(dst, data []float64, rng *rand.Rand) {
+	copy(dst, data)
+	rng.Shuffle(len(dst), func(i, j int) { dst[i], dst[j] = dst[j], dst[i] })
+}
+
+// PhaseSurrogate randomizes the Fourier phases of data, which keeps its
+// power spectrum and so its linear autocorrelation, but not its
+// distribution.
+func PhaseSurrogate(dst, data []float64, rng *rand.Rand) {
+	spectrum := dft(realToComplex(data), false)
+	randomizePhases(spectrum, rng)
+	for i, v := range dft(spectrum, true) {
+		dst[i] = real(v)
+	}
+}
+
+// IAAFTSurrogate returns a SurrogateFunc of the iterative amplitude adjusted
+// Fourier transform of Schreiber and Schmitz, which keeps the distribution
+// of data exactly and its power spectrum approximately. Starting from a
+// shuffle, it alternately imposes the Fourier amplitudes and the values of
+// data for up to iterations rounds, stopping early once the ranks are stable.
+func IAAFTSurrogate(iterations int) SurrogateFunc {
+	return func(dst, data []float64, rng *rand.Rand) {
+		n := len(data)
+		sorted := sortedCopy(data)
+		amplitudes := dft(realToComplex(data), false)
+		for i, v := range amplitudes {
+			amplitudes[i] = complex(cmplx.Abs(v), 0)
+		}
+		ShuffleSurrogate(dst, data, rng)
+		order := make([]int, n)
+		previous := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+		for it := 0; it < iterations; it++ {
+			spectrum := dft(realToComplex(dst), false)
+			for i, v := range spectrum {
+				if abs := cmplx.Abs(v); abs > 0 {
+					spectrum[i] = v * amplitudes[i] / complex(abs, 0)
+				} else {
+					spectrum[i] = amplitudes[i]
+				}
+			}
+			adjusted := dft(spectrum, true)
+			copy(previous, order)
+			sort.SliceStable(order, func(a, b int) bool { return real(adjusted[order[a]]) < real(adjusted[order[b]]) })
+			for rank, i := range order {
+				dst[i] = sorted[rank]
+			}
+			if it > 0 && slices.Equal(order, previous) {
+				return
+			}
+		}
+	}
+}
+
+// BlockShuffleSurrogate returns a SurrogateFunc that rotates data by a
+// random offset, cuts it into blocks of blockSize samples and concatenates
+// the blocks in random order. The values and the dependence within a block
+// are kept, so blockSize should exceed the autocorrelation time of data.
+// The rotation treats data as circular, so that every sample can start a
+// block.
+func BlockShuffleSurrogate(blockSize int) SurrogateFunc {
+	return func(dst, data []float64, rng *rand.Rand) {
+		n := len(data)
+		if n == 0 {
+			return
+		}
+		size := blockSize
+		if size < 1 {
+			size = 1
+		}
+		offset := rng.Intn(n)
+		blocks := make([]int, (n+size-1)/size)
+		for i := range blocks {
+			blocks[i] = i * size
+		}
+		rng.Shuffle(len(blocks), func(i, j int) { blocks[i], blocks[j] = blocks[j], blocks[i] })
+		k := 0
+		for _, start := range blocks {
+			for j := start; j < start+size && j < n; j++ {
+				dst[k] = data[(offset+j)%n]
+				k++
+			}
+		}
+	}
+}
+
+func realToComplex(data []float64) []complex128 {
+	c := make([]complex128, len(data))
+	for i, v := range data {
+		c[i] = complex(v, 0)
+	}
+	return c
+}
+
+// randomizePhases gives every frequency of the spectrum of a real series
+// a uniform random phase, keeping the spectrum conjugate symmetric so that
+// its inverse stays real. The mean and, for an even size, the Nyquist
+// component stay as they are.
+func randomizePhases(spectrum []complex128, rng *rand.Rand) {
+	n := len(spectrum)
+	for k := 1; k < (n+1)/2; k++ {
+		v := cmplx.Rect(cmplx.Abs(spectrum[k]), 2*math.Pi*rng.Float64())
+		spectrum[k], spectrum[n-k] = v, cmplx.Conj(v)
+	}
+}
+
+// dft returns the discrete Fourier transform of x, or its inverse scaled by
+// 1/len(x). Sizes that are powers of two use a radix-2 FFT, others
+// Bluestein's algorithm on top of it, so every size takes O(n log n).
+func dft(x []complex128, inverse bool) []complex128 {
+	n := len(x)
+	if n&(n-1) == 0 {
+		out := append([]complex128(nil), x...)
+		fft(out, inverse)
+		return scaleInverse(out, inverse)
+	}
+	// Bluestein: X[k] = w*[k] Σ x[j] w*[j] w[k-j] with w[j] = e^(iπj²/n).
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	chirp := make([]complex128, n)
+	for j := range chirp {
+		// j² mod 2n keeps the angle accurate for large j.
+		angle := sign * math.Pi * float64((j*j)%(2*n)) / float64(n)
+		chirp[j] = cmplx.Rect(1, angle)
+	}
+	m := 1
+	for m < 2*n-1 {
+		m <<= 1
+	}
+	a := make([]complex128, m)
+	b := make([]complex128, m)
+	for j := 0; j < n; j++ {
+		a[j] = x[j] * chirp[j]
+	}
+	b[0] = cmplx.Conj(chirp[0])
+	for j := 1; j < n; j++ {
+		b[j] = cmplx.Conj(chirp[j])
+		b[m-j] = b[j]
+	}
+	fft(a, false)
+	fft(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	fft(a, true)
+	out := make([]complex128, n)
+	for k := range out {
+		out[k] = a[k] / complex(float64(m), 0) * chirp[k]
+	}
+	return scaleInverse(out, inverse)
+}
+
+func scaleInverse(x []complex128, inverse bool) []complex128 {
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(len(x)), 0)
+		}
+	}
+	return x
+}
+
+// fft is an unscaled in-place radix-2 FFT; len(x) must be a power of two.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u, v := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = u+v, u-v
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for _, n := range []int{1, 8, 12, 37} {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rng.NormFloat64(), rng.NormFloat64())
+		}
+		got := dft(x, false)
+		for k := range got {
+			var want complex128
+			for j := range x {
+				want += x[j] * cmplx.Rect(1, -2*math.Pi*float64(j*k)/float64(n))
+			}
+			if cmplx.Abs(got[k]-want) > 1e-9 {
+				t.Errorf("n=%d: X[%d] = %v, want %v", n, k, got[k], want)
+			}
+		}
+		back := dft(got, true)
+		for i := range back {
+			if cmplx.Abs(back[i]-x[i]) > 1e-9 {
+				t.Errorf("n=%d: inverse[%d] = %v, want %v", n, i, back[i], x[i])
+			}
+		}
+	}
+}
+
+// ar1 returns an autocorrelated AR(1) series with coefficient phi.
+func ar1(rng *rand.Rand, n int, phi float64) []float64 {
+	data := make([]float64, n)
+	for i := 1; i < n; i++ {
+		data[i] = phi*data[i-1] + rng.NormFloat64()
+	}
+	return data
+}
+
+func lag1Autocorrelation(data []float64) float64 {
+	var mean float64
+	for _, v := range data {
+		mean += v
+	}
+	mean /= float64(len(data))
+	var num, den float64
+	for i := range data {
+		den += (data[i] - mean) * (data[i] - mean)
+		if i > 0 {
+			num += (data[i] - mean) * (data[i-1] - mean)
+		}
+	}
+	return num / den
+}
+
+func TestSurrogates(t *testing.T) {
+	rng := rand.New(rand.NewSource(47))
+	data := ar1(rng, 1000, 0.9)
+	want := lag1Autocorrelation(data)
+	dst := make([]float64, len(data))
+
+	for name, surrogate := range map[string]SurrogateFunc{
+		"phase": PhaseSurrogate,
+		"iaaft": IAAFTSurrogate(50),
+		"block": BlockShuffleSurrogate(50),
+	} {
+		surrogate(dst, data, rand.New(rand.NewSource(1)))
+		if got := lag1Autocorrelation(dst); !almostEqual(got, want, 0.05) {
+			t.Errorf("%s: lag-1 autocorrelation %v, original %v", name, got, want)
+		}
+		if name != "phase" {
+			// IAAFT and block shuffling keep the values.
+			if a, b := sortedCopy(dst), sortedCopy(data); !slices.Equal(a, b) {
+				t.Errorf("%s: values changed", name)
+			}
+		}
+	}
+	ShuffleSurrogate(dst, data, rand.New(rand.NewSource(1)))
+	if got := lag1Autocorrelation(dst); math.Abs(got) > 0.1 {
+		t.Errorf("shuffle keeps lag-1 autocorrelation %v", got)
+	}
+}
+
+func TestSignificanceTestWithSurrogates(t *testing.T) {
+	// Two independent but strongly autocorrelated series: shuffling finds
+	// spurious dependence, phase randomization does not.
+	rng := rand.New(rand.NewSource(48))
+	dataX := ar1(rng, 512, 0.98)
+	dataY := ar1(rng, 512, 0.98)
+	minX, maxX, _ := DataRange(dataX)
+	minY, maxY, _ := DataRange(dataY)
+	shuffled, err := SignificanceTestWithSurrogates(0, 0, 6, 6, minX, maxX, minY, maxY, dataX, dataY, 1, 200, 5, ShuffleSurrogate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := SignificanceTest(0, 0, 6, 6, minX, maxX, minY, maxY, dataX, dataY, 1, 200, 5)
+	if shuffled[0] != plain[0] {
+		t.Errorf("ShuffleSurrogate %+v differs from SignificanceTest %+v", shuffled[0], plain[0])
+	}
+	phase, err := SignificanceTestWithSurrogates(0, 0, 6, 6, minX, maxX, minY, maxY, dataX, dataY, 1, 200, 5, PhaseSurrogate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(phase[0].Surrogates.Mean > shuffled[0].Surrogates.Mean) || phase[0].PValue <= shuffled[0].PValue {
+		t.Errorf("phase surrogates %+v not more conservative than shuffles %+v", phase[0], shuffled[0])
+	}
+	if _, err := SignificanceTestWithSurrogates(0, 0, 6, 6, minX, maxX, minY, maxY, dataX, dataY, 1, 10, 5, nil); err == nil {
+		t.Error("expected error for a nil surrogate")
+	}
+}
@@ -0,0 +1,105 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// blockSymbols returns bins^length, or false if it exceeds maxBlockSymbols.
+func blockSymbols(bins, length int) (int, bool) {
+	symbols := 1
+	for i := 0; i < length; i++ {
+		if symbols > maxBlockSymbols/bins {
+			return 0, false
+		}
+		symbols *= bins
+	}
+	return symbols, true
+}
+
+// countsEntropy returns the entropy in bits of the distribution given by the
+// counts of occupied cells.
+func countsEntropy[K comparable](counts map[K]int) float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	var h neumaierSum
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		h.Add(-p * math.Log2(p))
+	}
+	return h.Value()
+}
+
+// TransferEntropy estimates the transfer entropy TE(X→Y) in bits, the
+// information that the last l values of dataX add about the current value of
+// dataY beyond its own last k values:
+// I(y(t); x(t-l..t-1) | y(t-k..t-1)). Blocks are reduced to symbols as in
+// MultiLagMutualInformation and time steps with a value outside the ranges
+// are skipped. Unlike shifted MI, the conditioning on the past of Y removes
+// the information that Y already carries about itself, so TE(X→Y) and
+// TE(Y→X) indicate the direction of influence. The estimate needs far more
+// samples than binsY^(k+1)*binsX^l to be reliable.
+func TransferEntropy(k, l, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) (float64, error) {
+	te, err := TransferEntropyLags(1, 1, k, l, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, 1)
+	if err != nil {
+		return 0, err
+	}
+	return te[0], nil
+}
+
+// TransferEntropyLags is TransferEntropy for every lag from lagFrom to lagTo
+// in steps of lagStep, where the source block of lag d is
+// x(t-d-l+1..t-d). Lag 1 gives TransferEntropy; the lag of the peak
+// estimates the delay of the interaction.
+func TransferEntropyLags(lagFrom, lagTo, k, l, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, lagStep int) ([]float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return nil, err
+	}
+	if k < 1 || l < 1 {
+		return nil, invalid(ErrInvalidParameter, "k", "history lengths k and l must be greater or equal 1")
+	}
+	if lagFrom < 1 {
+		return nil, invalid(ErrInvalidShift, "lagFrom", "lagFrom must be greater or equal 1")
+	}
+	if lagFrom > lagTo {
+		return nil, invalid(ErrInvalidShift, "lagFrom", "lagFrom must not be greater than lagTo")
+	}
+	if lagStep < 1 {
+		return nil, invalid(ErrInvalidShift, "lagStep", "lagStep must be greater or equal 1")
+	}
+	if maxInt(k, lagTo+l-1) >= len(dataY) {
+		return nil, invalid(ErrTooFewSamples, "data", "data must be longer than the histories at the largest lag")
+	}
+	if _, ok := blockSymbols(binsY, k); !ok {
+		return nil, invalid(ErrInvalidBins, "binsY", "binsY^k exceeds the supported number of block symbols")
+	}
+	if _, ok := blockSymbols(binsX, l); !ok {
+		return nil, invalid(ErrInvalidBins, "binsX", "binsX^l exceeds the supported number of block symbols")
+	}
+
+	indicesX, _ := CalculateIndices1D(binsX, minX, maxX, dataX)
+	indicesY, _ := CalculateIndices1D(binsY, minY, maxY, dataY)
+	type cell struct{ current, targetPast, sourcePast int }
+	te := make([]float64, 0, (lagTo-lagFrom)/lagStep+1)
+	for lag := lagFrom; lag <= lagTo; lag += lagStep {
+		counts := make(map[cell]int)
+		for t := maxInt(k, lag+l-1); t < len(dataY); t++ {
+			targetPast, okTarget := blockSymbol(indicesY[t-k:t], binsY)
+			sourcePast, okSource := blockSymbol(indicesX[t-lag-l+1:t-lag+1], binsX)
+			if okTarget && okSource && indicesY[t] >= 0 {
+				counts[cell{indicesY[t], targetPast, sourcePast}]++
+			}
+		}
+		current := make(map[IndexPair]int)
+		sources := make(map[IndexPair]int)
+		past := make(map[int]int)
+		for c, n := range counts {
+			current[IndexPair{First: c.current, Second: c.targetPast}] += n
+			sources[IndexPair{First: c.targetPast, Second: c.sourcePast}] += n
+			past[c.targetPast] += n
+		}
+		te = append(te, countsEntropy(current)+countsEntropy(sources)-countsEntropy(counts)-countsEntropy(past))
+	}
+	return te, nil
+}
@@ -0,0 +1,50 @@
+package mutualinfo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTransferEntropy(t *testing.T) {
+	// Y copies X with a delay of two steps, X is white noise.
+	rng := rand.New(rand.NewSource(30))
+	n := 20000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = float64(rng.Intn(2))
+	}
+	for i := 2; i < n; i++ {
+		dataY[i] = dataX[i-2]
+	}
+
+	forward, err := TransferEntropy(1, 2, 2, 2, 0, 1, 0, 1, dataX, dataY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backward, err := TransferEntropy(1, 2, 2, 2, 0, 1, 0, 1, dataY, dataX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(forward, 1, 1e-2) || !almostEqual(backward, 0, 1e-2) {
+		t.Errorf("TE(X→Y) = %v, TE(Y→X) = %v, want 1 and 0", forward, backward)
+	}
+
+	te, err := TransferEntropyLags(1, 4, 1, 1, 2, 2, 0, 1, 0, 1, dataX, dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shift, _, _ := PeakShift(1, 4, 1, te); shift != 2 || !almostEqual(te[1], 1, 1e-2) {
+		t.Errorf("lag sweep %v, want a peak of 1 bit at lag 2", te)
+	}
+
+	if _, err := TransferEntropyLags(0, 4, 1, 1, 2, 2, 0, 1, 0, 1, dataX, dataY, 1); err == nil {
+		t.Error("expected error for lag 0")
+	}
+	if _, err := TransferEntropy(0, 1, 2, 2, 0, 1, 0, 1, dataX, dataY); err == nil {
+		t.Error("expected error for k = 0")
+	}
+	if _, err := TransferEntropy(1, 1, 2, 2, 0, 1, 0, 1, dataX[:1], dataY[:1]); err == nil {
+		t.Error("expected error for too short data")
+	}
+}
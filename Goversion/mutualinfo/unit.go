@@ -0,0 +1,45 @@
+package mutualinfo
+
+import "math"
+
+// Unit selects the logarithm base in which entropies and mutual information
+// are reported.
+type Unit int
+
+const (
+	// UnitBits uses log base 2.
+	UnitBits Unit = iota
+	// UnitNats uses the natural logarithm, as most of the literature does.
+	UnitNats
+	// UnitDits uses log base 10; a dit is also called a hartley or ban.
+	UnitDits
+)
+
+func (u Unit) String() string {
+	switch u {
+	case UnitBits:
+		return "bits"
+	case UnitNats:
+		return "nats"
+	case UnitDits:
+		return "dits"
+	}
+	return "unknown"
+}
+
+// FromBits converts an entropy or mutual information in bits to the unit u.
+// Functions without a Unit option, such as TransferEntropy or
+// ConditionalMutualInformation, report bits and are converted with it.
+func (u Unit) FromBits(v float64) float64 {
+	switch u {
+	case UnitNats:
+		return v * math.Ln2
+	case UnitDits:
+		return v * math.Log10(2)
+	}
+	return v
+}
+
+func (u Unit) valid() bool {
+	return u >= UnitBits && u <= UnitDits
+}
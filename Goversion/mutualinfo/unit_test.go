@@ -0,0 +1,67 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestUnit(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	dataX := make([]float64, 500)
+	dataY := make([]float64, 500)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = dataX[i] + 0.3*rng.Float64()
+	}
+	bits, err := ShiftedMutualInformationResult(-2, 2, 8, 8, 0, 1, 0, 1.3, dataX, dataY, 1, ShiftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nats, err := ShiftedMutualInformationResult(-2, 2, 8, 8, 0, 1, 0, 1.3, dataX, dataY, 1, ShiftOptions{Unit: UnitNats})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range bits.MI {
+		if !almostEqual(nats.MI[i], bits.MI[i]*math.Ln2, 1e-12) || !almostEqual(nats.HXY[i], bits.HXY[i]*math.Ln2, 1e-12) {
+			t.Errorf("shift %d: nats %v, %v from bits %v, %v", bits.Shifts[i], nats.MI[i], nats.HXY[i], bits.MI[i], bits.HXY[i])
+		}
+	}
+	if nats.Settings.Unit != "nats" {
+		t.Errorf("unit setting %q, want nats", nats.Settings.Unit)
+	}
+
+	// Normalized values have no unit.
+	normalized, err := ShiftedMutualInformationWithOptions(-2, 2, 8, 8, 0, 1, 0, 1.3, dataX, dataY, 1, ShiftOptions{Normalization: NormalizationSymmetric})
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalizedDits, err := ShiftedMutualInformationWithOptions(-2, 2, 8, 8, 0, 1, 0, 1.3, dataX, dataY, 1, ShiftOptions{Normalization: NormalizationSymmetric, Unit: UnitDits})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range normalized {
+		if normalized[i] != normalizedDits[i] {
+			t.Errorf("normalized MI %v changed to %v in dits", normalized[i], normalizedDits[i])
+		}
+	}
+
+	ksgBits, err := KSGMutualInformation(dataX, dataY, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ksgDits, err := KSGMutualInformationWithOptions(dataX, dataY, 3, KSGOptions{Unit: UnitDits})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(ksgDits, ksgBits*math.Log10(2), 1e-12) {
+		t.Errorf("KSG %v dits from %v bits", ksgDits, ksgBits)
+	}
+
+	if _, err := ShiftedMutualInformationWithOptions(-2, 2, 8, 8, 0, 1, 0, 1.3, dataX, dataY, 1, ShiftOptions{Unit: 7}); err == nil {
+		t.Error("expected error for an unknown unit")
+	}
+	if got := UnitNats.FromBits(1); got != math.Ln2 {
+		t.Errorf("1 bit = %v nats, want ln 2", got)
+	}
+}
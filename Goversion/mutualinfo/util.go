@@ -0,0 +1,1154 @@
+// Package mutualinfo calculates histogram-based mutual information of two
+// signals, in particular while they are shifted against each other to find
+// delayed responses.
+//
+// The random routines, such as the bootstrap, the permutation tests and the
+// shuffle correction, never use the global random source. They take a seed
+// and draw every resample from its own source derived from the seed and the
+// index of the resample, so results are reproducible and do not depend on
+// GOMAXPROCS. Surrogates receive their source as a *rand.Rand.
+package mutualinfo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// Number is the set of element types accepted by the generic entry points.
+// Values are converted to float64 for binning.
+type Number interface {
+	~float32 | ~float64 | ~int | ~int64
+}
+
+// IndexPair holds the bin indices of an (x, y) pair, -1 marking out of range.
+type IndexPair struct {
+	First  int
+	Second int
+}
+
+// Histogram2D counts (x, y) pairs in BinsX x BinsY equally wide bins.
+type Histogram2D struct {
+	BinsX int
+	BinsY int
+	MinX  float64
+	MaxX  float64
+	MinY  float64
+	MaxY  float64
+	Data  [][]int
+	// WeightedData holds the summed weights per cell once IncrementWeighted
+	// has been called; Increment then adds a weight of 1. If set, Entropies
+	// and the mutual information use it instead of Data.
+	WeightedData [][]float64
+	// weightResiduals holds the per-cell residuals of the compensated sums
+	// in WeightedData, allocated by addWeight.
+	weightResiduals [][]float64
+	// EdgesX and EdgesY, if set by NewHistogram2DEdges, hold the bin edges
+	// used instead of equally wide bins.
+	EdgesX []float64
+	EdgesY []float64
+	// OutOfRange counts the pairs passed to Increment with a value outside
+	// the ranges, which are not counted in Data.
+	OutOfRange int
+	// Missing counts the pairs of OutOfRange that hold a NaN.
+	Missing int
+	// Outside counts the values of OutOfRange below and above the range of
+	// each axis, so that a range set too narrow shows on which side.
+	Outside RangeCounts
+	Mutex   sync.Mutex
+}
+
+// RangeCounts counts the values outside the ranges of a histogram by axis
+// and side. A pair outside on both axes counts on both, NaN on neither.
+type RangeCounts struct {
+	UnderX int `json:"under_x"`
+	OverX  int `json:"over_x"`
+	UnderY int `json:"under_y"`
+	OverY  int `json:"over_y"`
+}
+
+// count counts x and y in c if they lie outside [minX, maxX] and
+// [minY, maxY].
+func (c *RangeCounts) count(x, y, minX, maxX, minY, maxY float64) {
+	switch {
+	case x < minX:
+		c.UnderX++
+	case x > maxX:
+		c.OverX++
+	}
+	switch {
+	case y < minY:
+		c.UnderY++
+	case y > maxY:
+		c.OverY++
+	}
+}
+
+func (c *RangeCounts) add(other RangeCounts) {
+	c.UnderX += other.UnderX
+	c.OverX += other.OverX
+	c.UnderY += other.UnderY
+	c.OverY += other.OverY
+}
+
+func NewHistogram2D(binsX, binsY int, minX, maxX, minY, maxY float64) *Histogram2D {
+	// The rows share one contiguous allocation.
+	cells := make([]int, binsX*binsY)
+	data := make([][]int, binsX)
+	for i := range data {
+		data[i] = cells[i*binsY : (i+1)*binsY : (i+1)*binsY]
+	}
+	return &Histogram2D{
+		BinsX: binsX,
+		BinsY: binsY,
+		MinX:  minX,
+		MaxX:  maxX,
+		MinY:  minY,
+		MaxY:  maxY,
+		Data:  data,
+	}
+}
+
+// binIndex returns the bin of value among bins equally wide bins over
+// [min, max], or -1 if value lies outside. Both boundaries are inclusive,
+// value == max falls into the last bin, and NaN lies outside.
+func binIndex(value, min, max float64, bins int) int {
+	if !(value >= min && value <= max) {
+		return -1
+	}
+	index := int((value - min) / (max - min) * float64(bins))
+	if index >= bins {
+		index = bins - 1
+	}
+	return index
+}
+
+// Increment counts the pair (x, y). Pairs with a value outside the
+// histogram ranges are only counted in OutOfRange, with the same inclusive
+// boundaries as CalculateIndices1D and CalculateIndices2D. NaN is out of range.
+// With edges, the bins are found by binary search as in edgeIndex.
+func (h *Histogram2D) Increment(x, y float64) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.increment(x, y)
+}
+
+// increment is Increment for a histogram that the caller holds the mutex of
+// or does not share.
+func (h *Histogram2D) increment(x, y float64) {
+	h.incrementWeighted(x, y, 1)
+}
+
+func (h *Histogram2D) incrementWeighted(x, y, weight float64) {
+	var indexX, indexY int
+	if h.EdgesX != nil {
+		indexX = edgeIndex(h.EdgesX, x)
+	} else {
+		indexX = binIndex(x, h.MinX, h.MaxX, h.BinsX)
+	}
+	if h.EdgesY != nil {
+		indexY = edgeIndex(h.EdgesY, y)
+	} else {
+		indexY = binIndex(y, h.MinY, h.MaxY, h.BinsY)
+	}
+	if indexX < 0 || indexY < 0 {
+		h.OutOfRange++
+		if math.IsNaN(x) || math.IsNaN(y) {
+			h.Missing++
+		}
+		h.Outside.count(x, y, h.MinX, h.MaxX, h.MinY, h.MaxY)
+		return
+	}
+	h.Data[indexX][indexY]++
+	if h.WeightedData != nil {
+		h.addWeight(indexX, indexY, weight)
+	}
+}
+
+// addWeight adds weight to WeightedData[i][j] with compensated summation, so
+// that many small weights are not lost against a large cell.
+func (h *Histogram2D) addWeight(i, j int, weight float64) {
+	if h.weightResiduals == nil {
+		h.weightResiduals = make([][]float64, h.BinsX)
+		for k := range h.weightResiduals {
+			h.weightResiduals[k] = make([]float64, h.BinsY)
+		}
+	}
+	addCompensated(&h.WeightedData[i][j], &h.weightResiduals[i][j], weight)
+}
+
+// IncrementWeighted counts the pair (x, y) with the given weight, e.g. an
+// importance weight. Pairs outside the ranges are handled like in Increment.
+// Data keeps counting each pair once. An error is returned for a negative or
+// non-finite weight. A histogram with zero total weight has NaN entropies,
+// like an empty one.
+func (h *Histogram2D) IncrementWeighted(x, y, weight float64) error {
+	if !(weight >= 0) || math.IsInf(weight, 1) {
+		return invalid(ErrInvalidParameter, "weight", "weight must be finite and not negative")
+	}
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	if h.WeightedData == nil {
+		h.WeightedData = make([][]float64, h.BinsX)
+		for i := range h.WeightedData {
+			h.WeightedData[i] = make([]float64, h.BinsY)
+			for j, c := range h.Data[i] {
+				h.WeightedData[i][j] = float64(c)
+			}
+		}
+	}
+	h.incrementWeighted(x, y, weight)
+	return nil
+}
+
+// reset clears the counts of an unshared histogram and sets new ranges, so
+// that it can be refilled without allocating.
+func (h *Histogram2D) reset(minX, maxX, minY, maxY float64) {
+	for i := range h.Data {
+		for j := range h.Data[i] {
+			h.Data[i][j] = 0
+		}
+	}
+	h.OutOfRange = 0
+	h.Missing = 0
+	h.Outside = RangeCounts{}
+	h.WeightedData = nil
+	h.weightResiduals = nil
+	h.MinX, h.MaxX, h.MinY, h.MaxY = minX, maxX, minY, maxY
+}
+
+// marginalCounts returns the row and column totals and the overall total
+// of the histogram. The caller must hold the mutex.
+func (h *Histogram2D) marginalCounts() (rows, cols []int, total int) {
+	rows = make([]int, h.BinsX)
+	cols = make([]int, h.BinsY)
+	for i := 0; i < h.BinsX; i++ {
+		for j := 0; j < h.BinsY; j++ {
+			rows[i] += h.Data[i][j]
+			cols[j] += h.Data[i][j]
+			total += h.Data[i][j]
+		}
+	}
+	return rows, cols, total
+}
+
+func (h *Histogram2D) CalculateMutualInformation() float64 {
+	hx, hy, hxy := h.Entropies()
+	return hx + hy - hxy
+}
+
+// CalculateMutualInformationBase returns the mutual information with
+// logarithms to the given base, e.g. math.E for nats or 10 for hartleys.
+// CalculateMutualInformation is base 2. The result is NaN if base is not
+// positive and different from 1.
+func (h *Histogram2D) CalculateMutualInformationBase(base float64) float64 {
+	hx, hy, hxy := h.EntropiesBase(base)
+	return hx + hy - hxy
+}
+
+// Entropies returns the marginal entropies H(X) and H(Y) and the joint
+// entropy H(X,Y) in bits in a single pass, CalculateMutualInformation being
+// hx + hy - hxy. Empty bins are skipped.
+func (h *Histogram2D) Entropies() (hx, hy, hxy float64) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	if h.WeightedData != nil {
+		return entropiesOf(h.BinsX, h.BinsY, func(i, j int) float64 { return h.WeightedData[i][j] })
+	}
+	return entropiesOf(h.BinsX, h.BinsY, func(i, j int) float64 { return float64(h.Data[i][j]) })
+}
+
+// entropiesOf returns the marginal and joint entropies in bits of the
+// binsX x binsY weights given by cell. With integer counts the float64 sums
+// are exact, so counts and equal weights give identical results.
+func entropiesOf(binsX, binsY int, cell func(i, j int) float64) (hx, hy, hxy float64) {
+	var sum neumaierSum
+	for i := 0; i < binsX; i++ {
+		for j := 0; j < binsY; j++ {
+			sum.Add(cell(i, j))
+		}
+	}
+	total := sum.Value()
+
+	for i := 0; i < binsX; i++ {
+		var row neumaierSum
+		for j := 0; j < binsY; j++ {
+			row.Add(cell(i, j) / total)
+		}
+		if px := row.Value(); px != 0 {
+			hx -= px * math.Log2(px)
+		}
+	}
+
+	for j := 0; j < binsY; j++ {
+		var col neumaierSum
+		for i := 0; i < binsX; i++ {
+			col.Add(cell(i, j) / total)
+		}
+		if py := col.Value(); py != 0 {
+			hy -= py * math.Log2(py)
+		}
+	}
+
+	for i := 0; i < binsX; i++ {
+		for j := 0; j < binsY; j++ {
+			p := cell(i, j) / total
+			if p != 0 {
+				hxy -= p * math.Log2(p)
+			}
+		}
+	}
+
+	return hx, hy, hxy
+}
+
+// EntropiesBase is Entropies with logarithms to the given base, see
+// CalculateMutualInformationBase.
+func (h *Histogram2D) EntropiesBase(base float64) (hx, hy, hxy float64) {
+	if !(base > 0) || base == 1 || math.IsInf(base, 1) {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	hx, hy, hxy = h.Entropies()
+	scale := math.Ln2 / math.Log(base)
+	return hx * scale, hy * scale, hxy * scale
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// finiteValues returns the finite values of data, data itself if all are.
+func finiteValues(data []float64) []float64 {
+	for i, v := range data {
+		if !isFinite(v) {
+			finite := append([]float64(nil), data[:i]...)
+			for _, v := range data[i+1:] {
+				if isFinite(v) {
+					finite = append(finite, v)
+				}
+			}
+			return finite
+		}
+	}
+	return data
+}
+
+// resolvable reports whether each of the bins bins over [min, max] is at
+// least as wide as the float64 spacing at the magnitude of the range. A
+// narrower range, e.g. a band of 1e-12 around 1e6, cannot be told apart from
+// rounding noise and bins would collide. Subtracting the common offset from
+// the data before it is converted to float64 avoids this.
+func resolvable(min, max float64, bins int) bool {
+	magnitude := math.Max(math.Abs(min), math.Abs(max))
+	spacing := math.Nextafter(magnitude, math.Inf(1)) - magnitude
+	return (max-min)/float64(bins) >= spacing
+}
+
+// CheckFinite returns an error naming the first NaN or infinite value of
+// data, e.g. "dataX[17] is NaN", or nil if all values are finite. The
+// indexing and MI functions treat such values as out of range; call
+// CheckFinite first to reject them instead.
+func CheckFinite(name string, data []float64) error {
+	for i, v := range data {
+		if !isFinite(v) {
+			return invalid(ErrInvalidData, name, fmt.Sprintf("%s[%d] is %v", name, i, v))
+		}
+	}
+	return nil
+}
+
+// CalculateIndices1D returns the bin of every value of data, -1 marking
+// values outside [min, max] as well as NaN and infinite values.
+func CalculateIndices1D[T Number](bins int, min, max T, data []T) ([]int, error) {
+	lo, hi := float64(min), float64(max)
+	if !isFinite(lo) || !isFinite(hi) {
+		return nil, invalid(ErrInvalidRange, "min", "min and max must be finite")
+	}
+	if lo >= hi {
+		return nil, invalid(ErrInvalidRange, "min", "min has to be smaller than max")
+	}
+	if bins < 1 {
+		return nil, invalid(ErrInvalidBins, "bins", "there must be at least one bin")
+	}
+	if !resolvable(lo, hi, bins) {
+		return nil, invalid(ErrInvalidRange, "min", "range is too narrow for its magnitude, subtract a common offset from the data")
+	}
+
+	indices := make([]int, len(data))
+	for i, value := range data {
+		indices[i] = binIndex(float64(value), lo, hi, bins) // -1 indicates out of range
+	}
+
+	return indices, nil
+}
+
+// CalculateIndices2D returns the bins of every pair of dataX and dataY,
+// {-1, -1} marking pairs with a value out of range, NaN or infinite.
+func CalculateIndices2D[T Number](binsX, binsY int, minX, maxX, minY, maxY T, dataX, dataY []T) ([]IndexPair, error) {
+	loX, hiX, loY, hiY := float64(minX), float64(maxX), float64(minY), float64(maxY)
+	if !isFinite(loX) || !isFinite(hiX) {
+		return nil, invalid(ErrInvalidRange, "minX", "minX and maxX must be finite")
+	}
+	if !isFinite(loY) || !isFinite(hiY) {
+		return nil, invalid(ErrInvalidRange, "minY", "minY and maxY must be finite")
+	}
+	if loX >= hiX {
+		return nil, invalid(ErrInvalidRange, "minX", "minX has to be smaller than maxX")
+	}
+	if loY >= hiY {
+		return nil, invalid(ErrInvalidRange, "minY", "minY has to be smaller than maxY")
+	}
+	if binsX < 1 {
+		return nil, invalid(ErrInvalidBins, "binsX", "there must be at least one binX")
+	}
+	if binsY < 1 {
+		return nil, invalid(ErrInvalidBins, "binsY", "there must be at least one binY")
+	}
+	if !resolvable(loX, hiX, binsX) {
+		return nil, invalid(ErrInvalidRange, "minX", "X range is too narrow for its magnitude, subtract a common offset from dataX")
+	}
+	if !resolvable(loY, hiY, binsY) {
+		return nil, invalid(ErrInvalidRange, "minY", "Y range is too narrow for its magnitude, subtract a common offset from dataY")
+	}
+	if len(dataX) != len(dataY) {
+		return nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+
+	indices := make([]IndexPair, len(dataX))
+	for i := range dataX {
+		indexX := binIndex(float64(dataX[i]), loX, hiX, binsX)
+		indexY := binIndex(float64(dataY[i]), loY, hiY, binsY)
+		if indexX < 0 || indexY < 0 {
+			indices[i] = IndexPair{First: -1, Second: -1} // Indicates out of range
+			continue
+		}
+		indices[i] = IndexPair{First: indexX, Second: indexY}
+	}
+
+	return indices, nil
+}
+
+func validate2D(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64) error {
+	if !isFinite(minX) || !isFinite(maxX) {
+		return invalid(ErrInvalidRange, "minX", "minX and maxX must be finite")
+	}
+	if !isFinite(minY) || !isFinite(maxY) {
+		return invalid(ErrInvalidRange, "minY", "minY and maxY must be finite")
+	}
+	if minX >= maxX {
+		return invalid(ErrInvalidRange, "minX", "minX has to be smaller than maxX")
+	}
+	if minY >= maxY {
+		return invalid(ErrInvalidRange, "minY", "minY has to be smaller than maxY")
+	}
+	if binsX < 1 || binsY < 1 {
+		return invalid(ErrInvalidBins, "bins", "there must be at least one binX and one binY")
+	}
+	if !resolvable(minX, maxX, binsX) {
+		return invalid(ErrInvalidRange, "minX", "X range is too narrow for its magnitude, subtract a common offset from dataX")
+	}
+	if !resolvable(minY, maxY, binsY) {
+		return invalid(ErrInvalidRange, "minY", "Y range is too narrow for its magnitude, subtract a common offset from dataY")
+	}
+	if len(dataX) != len(dataY) {
+		return invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	return nil
+}
+
+// fillHistogram increments hist with all pairs of src. Pairs outside the
+// histogram ranges are only counted in hist.OutOfRange.
+func fillHistogram(hist *Histogram2D, src XYSource) {
+	for i := 0; i < src.Len(); i++ {
+		x, y := src.At(i)
+		hist.Increment(x, y)
+	}
+}
+
+// FillHistogram2D increments hist with all pairs of dataX and dataY, which
+// may hold any Number type, e.g. float32 samples without converting copies.
+// Pairs outside the histogram ranges are only counted in hist.OutOfRange.
+func FillHistogram2D[T Number](hist *Histogram2D, dataX, dataY []T) error {
+	if len(dataX) != len(dataY) {
+		return invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	fillHistogram(hist, NumberPairs[T]{X: dataX, Y: dataY})
+	return nil
+}
+
+// MutualInformation calculates the mutual information of dataX and dataY
+// without any shift. Pairs outside the given ranges are ignored.
+func MutualInformation[T Number](binsX, binsY int, minX, maxX, minY, maxY T, dataX, dataY []T) (float64, error) {
+	if len(dataX) != len(dataY) {
+		return 0, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	return MutualInformationSource(binsX, binsY, float64(minX), float64(maxX), float64(minY), float64(maxY), NumberPairs[T]{X: dataX, Y: dataY})
+}
+
+// MutualInformationSource is MutualInformation reading the pairs from src.
+func MutualInformationSource(binsX, binsY int, minX, maxX, minY, maxY float64, src XYSource) (float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, nil, nil); err != nil {
+		return 0, err
+	}
+	hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	fillHistogram(hist, src)
+	return hist.CalculateMutualInformation(), nil
+}
+
+// shiftedHistogram fills a histogram with the pairs (x[j+shift], y[j]) of src
+// for all j where both indices are valid. A positive shift thus moves Y
+// to the right relative to X and a negative shift to the left:
+//
+//	shift > 0:  |--------------------|      X
+//	                |--------------------|  Y
+//	shift < 0:      |--------------------|  X
+//	            |--------------------|      Y
+//
+// leaving src.Len()-|shift| pairs before any filtering selected by opts.
+func shiftedHistogram(shift, binsX, binsY int, minX, maxX, minY, maxY float64, src XYSource, opts ShiftOptions) *Histogram2D {
+	hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	fillShiftedHistogram(hist, shift, 0, src.Len(), minX, maxX, minY, maxY, src, opts)
+	return hist
+}
+
+// shiftHistogram is a histogram that a shift sweep refills for every shift
+// without locking, Histogram2D or SparseHistogram2D.
+type shiftHistogram interface {
+	reset(minX, maxX, minY, maxY float64)
+	increment(x, y float64)
+	addIndex(indexX, indexY int)
+	Entropies() (hx, hy, hxy float64)
+	counted() int
+}
+
+// counted returns the number of pairs in the bins. The caller must hold the
+// mutex.
+func (h *Histogram2D) counted() int {
+	_, _, total := h.marginalCounts()
+	return total
+}
+
+// addIndex counts a pair by its bin indices, a negative index counting it as
+// out of range. The caller must hold the mutex.
+func (h *Histogram2D) addIndex(indexX, indexY int) {
+	if indexX < 0 || indexY < 0 {
+		h.OutOfRange++
+		return
+	}
+	h.Data[indexX][indexY]++
+}
+
+// pairIndices returns the bins of the X and Y values of all pairs of src,
+// -1 for values outside or NaN. The bins of every value are then computed
+// once per sweep instead of once per shift.
+func pairIndices(src XYSource, edgesX, edgesY []float64, minX, maxX, minY, maxY float64, binsX, binsY int) (indicesX, indicesY []int32) {
+	indicesX = make([]int32, src.Len())
+	indicesY = make([]int32, src.Len())
+	if s, ok := src.(SlicePairs); ok {
+		for i := range indicesX {
+			indicesX[i] = int32(axisIndex(s.X[i], edgesX, minX, maxX, binsX))
+			indicesY[i] = int32(axisIndex(s.Y[i], edgesY, minY, maxY, binsY))
+		}
+		return indicesX, indicesY
+	}
+	for i := range indicesX {
+		x, y := src.At(i)
+		indicesX[i] = int32(axisIndex(x, edgesX, minX, maxX, binsX))
+		indicesY[i] = int32(axisIndex(y, edgesY, minY, maxY, binsY))
+	}
+	return indicesX, indicesY
+}
+
+// fillShiftedIndices is fillShiftedHistogram from precomputed bin indices,
+// see pairIndices. It does not apply a dead zone or recalibrate the range.
+func fillShiftedIndices(hist shiftHistogram, shift, lo, hi int, minX, maxX, minY, maxY float64, indicesX, indicesY []int32) {
+	hist.reset(minX, maxX, minY, maxY)
+	addShiftedIndices(hist, shift, lo, hi, indicesX, indicesY)
+}
+
+// addShiftedIndices adds the pairs with lo <= j < hi of a shift to hist, see
+// fillShiftedIndices.
+func addShiftedIndices(hist shiftHistogram, shift, lo, hi int, indicesX, indicesY []int32) {
+	from, to := maxInt(lo, -shift), minInt(hi, len(indicesX)-shift)
+	if dense, ok := hist.(*Histogram2D); ok {
+		// Index the rows directly, avoiding a call per pair.
+		for j := from; j < to; j++ {
+			ix, iy := indicesX[j+shift], indicesY[j]
+			if ix < 0 || iy < 0 {
+				dense.OutOfRange++
+				continue
+			}
+			dense.Data[ix][iy]++
+		}
+		return
+	}
+	for j := from; j < to; j++ {
+		hist.addIndex(int(indicesX[j+shift]), int(indicesY[j]))
+	}
+}
+
+// fillShiftedIndicesChunked is fillShiftedIndices splitting the pairs into
+// chunks of chunkSize, which goroutines count into the private histograms of
+// chunked before the counts are merged into hist.
+func fillShiftedIndicesChunked(hist *Histogram2D, chunked []*Histogram2D, shift, lo, hi, chunkSize int, minX, maxX, minY, maxY float64, indicesX, indicesY []int32) {
+	hist.reset(minX, maxX, minY, maxY)
+	from, to := maxInt(lo, -shift), minInt(hi, len(indicesX)-shift)
+	starts := make(chan int)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, private := range chunked {
+		wg.Add(1)
+		go func(private *Histogram2D) {
+			defer wg.Done()
+			private.reset(minX, maxX, minY, maxY)
+			for start := range starts {
+				addShiftedIndices(private, shift, start, minInt(start+chunkSize, to), indicesX, indicesY)
+			}
+			mutex.Lock()
+			hist.add(private)
+			mutex.Unlock()
+		}(private)
+	}
+	for start := from; start < to; start += chunkSize {
+		starts <- start
+	}
+	close(starts)
+	wg.Wait()
+}
+
+// fillShiftedHistogram is shiftedHistogram refilling hist, which must not be
+// shared, without locking or allocating. Only pairs with lo <= j < hi are used.
+func fillShiftedHistogram(hist shiftHistogram, shift, lo, hi int, minX, maxX, minY, maxY float64, src XYSource, opts ShiftOptions) {
+	n := src.Len()
+	from, to := maxInt(lo, -shift), minInt(hi, n-shift)
+	at := src.At
+	// Slices avoid an interface call per value.
+	if s, ok := src.(SlicePairs); ok {
+		at = func(i int) (float64, float64) { return s.X[i], s.Y[i] }
+	}
+	pairs := func(fn func(x, y float64)) {
+		for j := from; j < to; j++ {
+			x, _ := at(j + shift)
+			_, y := at(j)
+			if math.Abs(x) < opts.DeadZone && math.Abs(y) < opts.DeadZone {
+				continue
+			}
+			fn(x, y)
+		}
+	}
+
+	if opts.RecalibrateRange {
+		minX, maxX = math.Inf(1), math.Inf(-1)
+		minY, maxY = math.Inf(1), math.Inf(-1)
+		pairs(func(x, y float64) {
+			minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+			minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+		})
+		// A constant axis ends up in a single bin.
+		if !(minX < maxX) {
+			maxX = minX + 1
+		}
+		if !(minY < maxY) {
+			maxY = minY + 1
+		}
+	}
+
+	hist.reset(minX, maxX, minY, maxY)
+	pairs(hist.increment)
+}
+
+// ShiftOptions holds optional settings for ShiftedMutualInformationWithOptions.
+// The zero value gives the behaviour of ShiftedMutualInformation.
+type ShiftOptions struct {
+	// AlignShorter truncates dataX and dataY to their common length instead
+	// of returning an error when their lengths differ.
+	AlignShorter bool
+	// Winsorize in [0, 0.5) is the tail fraction clipped on each side of both
+	// series. If positive, values below the Winsorize and above the
+	// 1-Winsorize quantile are clipped to these bounds and the bins span the
+	// clipped range, replacing minX, maxX, minY and maxY.
+	Winsorize float64
+	// DeadZone skips pairs where both |x| and |y| are smaller than DeadZone,
+	// e.g. to exclude a sensor noise floor around zero.
+	DeadZone float64
+	// RecalibrateRange spans the bins of every shift over the range of the
+	// pairs remaining for that shift instead of minX..maxX and minY..maxY.
+	// This keeps the bin resolution optimal per shift, but an MI value then
+	// refers to slightly different bins in every shift, which makes values
+	// less comparable across the sweep.
+	RecalibrateRange bool
+	// Normalization selects the normalized mutual information returned for
+	// every shift, see CalculateNormalizedMutualInformation. The zero value
+	// returns the mutual information in Unit.
+	Normalization Normalization
+	// Unit selects the unit of the mutual information and of the entropies
+	// of a Result. Normalized values have no unit and are not affected.
+	Unit Unit
+	// BiasCorrection corrects the mutual information of every shift, see
+	// CalculateMutualInformationCorrected. It cannot be combined with a
+	// Normalization or Sparse.
+	BiasCorrection BiasCorrection
+	// Sparse counts every shift in a SparseHistogram2D, which only stores
+	// and visits occupied cells. It pays off when binsX*binsY is large
+	// compared to the number of pairs.
+	Sparse bool
+	// Shuffles, if positive, subtracts from every shift the mean MI of
+	// Shuffles sweeps with dataY shuffled, which estimates the bias of the
+	// shift at its own bins and pairs. This gives a debiased lag curve of
+	// about 0 for independent data but multiplies the run time by
+	// 1+Shuffles; BiasAnalytic approximates it cheaply. The shuffles only
+	// depend on ShuffleSeed.
+	Shuffles    int
+	ShuffleSeed int64
+	// Workers bounds the number of shifts computed concurrently, each worker
+	// holding one histogram at a time. If zero, runtime.NumCPU() is used.
+	Workers int
+	// ChunkSize, if positive, computes the shifts one after another instead
+	// and splits the pairs of every shift into chunks of ChunkSize pairs,
+	// which Workers goroutines count into private histograms that are then
+	// merged. This pays off for very long series with fewer shifts than
+	// CPUs. It cannot be combined with Sparse, DeadZone or RecalibrateRange.
+	ChunkSize int
+	// CommonWindow uses the same samples of dataY for every shift, those
+	// that have a partner in dataX at all shifts of the sweep. Otherwise a
+	// shift uses all len-|shift| overlapping pairs, and since the upward
+	// bias of the MI grows as pairs get fewer, the curve of independent
+	// signals rises towards large shifts. With CommonWindow every shift uses
+	// len-max(0, -shiftFrom)-max(0, shiftTo) pairs, which must be positive.
+	CommonWindow bool
+	// RejectNonFinite returns an error naming the first NaN or infinite
+	// value instead of skipping the pairs holding one.
+	RejectNonFinite bool
+	// NaNPolicy selects whether pairs holding a NaN are skipped per pair,
+	// whose time steps are dropped from both series, or rejected.
+	NaNPolicy NaNPolicy
+	// Preprocess transforms dataX and dataY after the NaN policy, e.g. to
+	// their empirical copula, which replaces minX, maxX, minY and maxY with
+	// [0, 1].
+	Preprocess Preprocess
+	// AutoRange replaces minX, maxX, minY and maxY with ClippedDataRange of
+	// dataX and dataY, after winsorizing, so that the ranges need not be
+	// known in advance. With a positive AutoRangeClip the values beyond the
+	// clipped range are out of range. The ranges used are recorded in the
+	// settings of a Result.
+	AutoRange     bool
+	AutoRangeClip float64
+	// AutoBins replaces binsX and binsY with the counts SuggestBins proposes
+	// under BinRule for the finite values of dataX and dataY separately,
+	// after winsorizing.
+	AutoBins bool
+	BinRule  BinRule
+	// Binning selects how the bin edges are placed. BinningQuantile places
+	// them at the quantiles of the finite values of each whole series, after
+	// winsorizing, replacing minX, maxX, minY and maxY; it cannot be combined
+	// with RecalibrateRange.
+	Binning BinningStrategy
+	// Warn receives warnings about adjustments made to the input.
+	// If nil, warnings are written with log.Print.
+	Warn func(msg string)
+	// Progress, if set, is called after every shift of the sweep.
+	Progress ProgressFunc
+
+	// observe is called with the filled histogram of the shift at index i
+	// of the sweep.
+	observe func(i int, hist shiftHistogram)
+}
+
+func (o ShiftOptions) warn(msg string) {
+	if o.Warn != nil {
+		o.Warn(msg)
+		return
+	}
+	log.Print(msg)
+}
+
+// ShiftedMutualInformation calculates the mutual information of dataX and
+// dataY for every shift from shiftFrom to shiftTo in steps of shiftStep, see
+// shiftedHistogram for the direction of a shift. Slices of other numeric
+// types than float64 are read without converting copies.
+func ShiftedMutualInformation[T Number](shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY T, dataX, dataY []T, shiftStep int) ([]float64, error) {
+	if x, ok := any(dataX).([]float64); ok {
+		y := any(dataY).([]float64)
+		return ShiftedMutualInformationWithOptions(shiftFrom, shiftTo, binsX, binsY, float64(minX), float64(maxX), float64(minY), float64(maxY), x, y, shiftStep, ShiftOptions{})
+	}
+	if len(dataX) != len(dataY) {
+		return nil, invalid(ErrSizeMismatch, "dataX", "dataX and dataY must have the same size")
+	}
+	return ShiftedMutualInformationSource(shiftFrom, shiftTo, binsX, binsY, float64(minX), float64(maxX), float64(minY), float64(maxY), NumberPairs[T]{X: dataX, Y: dataY}, shiftStep)
+}
+
+// ShiftedMutualInformationWithOptions is ShiftedMutualInformation of float64
+// data with the preprocessing, binning, normalization and bias correction
+// selected by opts, see ShiftOptions.
+func ShiftedMutualInformationWithOptions(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep int, opts ShiftOptions) ([]float64, error) {
+	return ShiftedMutualInformationContext(context.Background(), shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, opts)
+}
+
+// ShiftedMutualInformationContext is ShiftedMutualInformationWithOptions
+// stopping early when ctx is done. It then returns ctx.Err() and no partial
+// results. opts.Workers bounds the number of shifts computed concurrently.
+func ShiftedMutualInformationContext(ctx context.Context, shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep int, opts ShiftOptions) ([]float64, error) {
+	return shiftedMutualInformation(ctx, shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, dataX, dataY, shiftStep, opts, nil)
+}
+
+// shiftedMutualInformation is ShiftedMutualInformationContext that also
+// fills everything but the MI and the peak of result if it is not nil.
+func shiftedMutualInformation(ctx context.Context, shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY []float64, shiftStep int, opts ShiftOptions, result *Result) (mi []float64, err error) {
+	var edgesX, edgesY []float64
+	if opts.AlignShorter && len(dataX) != len(dataY) {
+		n := len(dataX)
+		if len(dataY) < n {
+			n = len(dataY)
+		}
+		opts.warn(fmt.Sprintf("dataX (%d) and dataY (%d) differ in size, using the first %d samples", len(dataX), len(dataY), n))
+		dataX, dataY = dataX[:n], dataY[:n]
+	}
+	if opts.RejectNonFinite {
+		if err := CheckFinite("dataX", dataX); err != nil {
+			return nil, err
+		}
+		if err := CheckFinite("dataY", dataY); err != nil {
+			return nil, err
+		}
+	}
+	switch opts.NaNPolicy {
+	case DropNaNPairwise:
+	case DropNaNListwise:
+		if len(dataX) == len(dataY) {
+			dataX, dataY, _ = dropListwise(dataX, dataY)
+		}
+	case RejectNaN:
+		if err := checkNaN("dataX", dataX); err != nil {
+			return nil, err
+		}
+		if err := checkNaN("dataY", dataY); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalid(ErrInvalidOption, "NaNPolicy", "unknown NaN policy")
+	}
+	if !opts.Preprocess.valid() {
+		return nil, invalid(ErrInvalidOption, "Preprocess", "unknown preprocessing")
+	}
+	if opts.Preprocess != PreprocessNone {
+		dataX, dataY = opts.Preprocess.apply(dataX), opts.Preprocess.apply(dataY)
+		minX, maxX, minY, maxY = 0, 1, 0, 1
+	}
+	if opts.Winsorize < 0 || opts.Winsorize >= 0.5 {
+		return nil, invalid(ErrInvalidParameter, "winsorize", "winsorize must be in [0, 0.5)")
+	}
+	if opts.DeadZone < 0 {
+		return nil, invalid(ErrInvalidParameter, "deadZone", "deadZone must not be negative")
+	}
+	if opts.Winsorize > 0 {
+		if dataX, minX, maxX, err = winsorize(dataX, opts.Winsorize); err != nil {
+			return nil, err
+		}
+		if dataY, minY, maxY, err = winsorize(dataY, opts.Winsorize); err != nil {
+			return nil, err
+		}
+	}
+	if opts.AutoRange {
+		if minX, maxX, err = ClippedDataRange(dataX, opts.AutoRangeClip); err != nil {
+			return nil, fmt.Errorf("dataX: %w", err)
+		}
+		if minY, maxY, err = ClippedDataRange(dataY, opts.AutoRangeClip); err != nil {
+			return nil, fmt.Errorf("dataY: %w", err)
+		}
+	}
+	if opts.AutoBins {
+		if binsX, err = SuggestBins(finiteValues(dataX), opts.BinRule); err != nil {
+			return nil, err
+		}
+		if binsY, err = SuggestBins(finiteValues(dataY), opts.BinRule); err != nil {
+			return nil, err
+		}
+	}
+	switch opts.Binning {
+	case BinningUniform:
+	case BinningQuantile:
+		if opts.RecalibrateRange {
+			return nil, invalid(ErrInvalidOption, "Binning", "quantile binning cannot be combined with RecalibrateRange")
+		}
+		if edgesX, err = QuantileEdges(binsX, finiteValues(dataX)); err != nil {
+			return nil, err
+		}
+		if edgesY, err = QuantileEdges(binsY, finiteValues(dataY)); err != nil {
+			return nil, err
+		}
+		binsX, minX, maxX = len(edgesX)-1, edgesX[0], edgesX[len(edgesX)-1]
+		binsY, minY, maxY = len(edgesY)-1, edgesY[0], edgesY[len(edgesY)-1]
+	default:
+		return nil, invalid(ErrInvalidOption, "strategy", "unknown binning strategy")
+	}
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return nil, err
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if abs(shiftFrom) >= len(dataX) || abs(shiftTo) >= len(dataX) {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shifts must be smaller than the data size")
+	}
+	if opts.Workers < 0 {
+		return nil, invalid(ErrInvalidParameter, "workers", "workers must not be negative")
+	}
+	if opts.ChunkSize < 0 {
+		return nil, invalid(ErrInvalidParameter, "chunkSize", "chunkSize must not be negative")
+	}
+	if opts.Shuffles < 0 {
+		return nil, invalid(ErrInvalidParameter, "shuffles", "shuffles must not be negative")
+	}
+	if opts.ChunkSize > 0 && (opts.Sparse || opts.DeadZone > 0 || opts.RecalibrateRange) {
+		return nil, invalid(ErrInvalidOption, "ChunkSize", "chunked filling cannot be combined with Sparse, DeadZone or RecalibrateRange")
+	}
+	if opts.Normalization < NormalizationNone || opts.Normalization > NormalizationRedundancy {
+		return nil, invalid(ErrInvalidOption, "Normalization", "unknown normalization")
+	}
+	if opts.BiasCorrection < BiasNone || opts.BiasCorrection > BiasAnalytic {
+		return nil, invalid(ErrInvalidOption, "BiasCorrection", "unknown bias correction")
+	}
+	if !opts.Unit.valid() {
+		return nil, invalid(ErrInvalidOption, "Unit", "unknown unit")
+	}
+	if opts.BiasCorrection != BiasNone && opts.Normalization != NormalizationNone {
+		return nil, invalid(ErrInvalidOption, "BiasCorrection", "bias correction cannot be combined with a normalization")
+	}
+	if opts.BiasCorrection != BiasNone && opts.Sparse {
+		return nil, invalid(ErrInvalidOption, "BiasCorrection", "bias correction is not supported with sparse histograms")
+	}
+	if opts.CommonWindow && len(dataX)-maxInt(0, -shiftFrom)-maxInt(0, shiftTo) < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "the shifts leave no common window")
+	}
+
+	var sweepEdgesX, sweepEdgesY []float64
+	if opts.Binning == BinningQuantile {
+		sweepEdgesX, sweepEdgesY = edgesX, edgesY
+	} else if !opts.RecalibrateRange {
+		edgesX, edgesY = uniformEdges(binsX, minX, maxX), uniformEdges(binsY, minY, maxY)
+	}
+	if result != nil {
+		numShifts := (shiftTo-shiftFrom)/shiftStep + 1
+		*result = Result{
+			Shifts: make([]int, numShifts),
+			HX:     make([]float64, numShifts),
+			HY:     make([]float64, numShifts),
+			HXY:    make([]float64, numShifts),
+			Pairs:  make([]int, numShifts),
+			EdgesX: edgesX,
+			EdgesY: edgesY,
+			Settings: ResultSettings{
+				BinsX: binsX, BinsY: binsY,
+				MinX: minX, MaxX: maxX, MinY: minY, MaxY: maxY,
+				ShiftFrom: shiftFrom, ShiftTo: shiftTo, ShiftStep: shiftStep,
+				Binning:          opts.Binning.String(),
+				Normalization:    opts.Normalization.String(),
+				BiasCorrection:   opts.BiasCorrection.String(),
+				Unit:             opts.Unit.String(),
+				NaNPolicy:        opts.NaNPolicy.String(),
+				Preprocess:       opts.Preprocess.String(),
+				Shuffles:         opts.Shuffles,
+				Winsorize:        opts.Winsorize,
+				DeadZone:         opts.DeadZone,
+				RecalibrateRange: opts.RecalibrateRange,
+				CommonWindow:     opts.CommonWindow,
+			},
+		}
+		for i := range result.Shifts {
+			result.Shifts[i] = shiftFrom + i*shiftStep
+		}
+		lo, hi := 0, len(dataX)
+		if opts.CommonWindow {
+			lo, hi = maxInt(0, -shiftFrom), minInt(hi, hi-shiftTo)
+		}
+		result.Dropped = shiftedNaNPairs(shiftFrom, shiftTo, shiftStep, lo, hi, dataX, dataY)
+		if !opts.RecalibrateRange {
+			result.OutOfRange, result.Outside = shiftedOutside(shiftFrom, shiftTo, shiftStep, lo, hi, minX, maxX, minY, maxY, opts.DeadZone, dataX, dataY)
+		}
+		opts.observe = func(i int, hist shiftHistogram) {
+			hx, hy, hxy := hist.Entropies()
+			result.HX[i], result.HY[i], result.HXY[i] = opts.Unit.FromBits(hx), opts.Unit.FromBits(hy), opts.Unit.FromBits(hxy)
+			result.Pairs[i] = hist.counted()
+		}
+	}
+	if opts.Shuffles == 0 {
+		return shiftSweep(ctx, shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, sweepEdgesX, sweepEdgesY, SlicePairs{X: dataX, Y: dataY}, shiftStep, opts)
+	}
+
+	// The sweep and its shuffles report their shifts as one computation.
+	tick := progressTicker(opts.Progress, ((shiftTo-shiftFrom)/shiftStep+1)*(opts.Shuffles+1))
+	if opts.Progress != nil {
+		opts.Progress = func(int, int) { tick() }
+	}
+	if mi, err = shiftSweep(ctx, shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, sweepEdgesX, sweepEdgesY, SlicePairs{X: dataX, Y: dataY}, shiftStep, opts); err != nil {
+		return nil, err
+	}
+	opts.observe = nil
+	bias := make([]neumaierSum, len(mi))
+	shuffled := make([]float64, len(dataY))
+	for p := 0; p < opts.Shuffles; p++ {
+		ShuffleSurrogate(shuffled, dataY, permutationRand(opts.ShuffleSeed, p))
+		null, err := shiftSweep(ctx, shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, sweepEdgesX, sweepEdgesY, SlicePairs{X: dataX, Y: shuffled}, shiftStep, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range null {
+			bias[i].Add(v)
+		}
+	}
+	if result != nil {
+		result.ShuffleBias = make([]float64, len(mi))
+	}
+	for i := range mi {
+		b := bias[i].Value() / float64(opts.Shuffles)
+		mi[i] -= b
+		if result != nil {
+			result.ShuffleBias[i] = b
+		}
+	}
+	return mi, nil
+}
+
+// ShiftedMutualInformationSource is ShiftedMutualInformation reading the
+// pairs from src.
+func ShiftedMutualInformationSource(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, src XYSource, shiftStep int) ([]float64, error) {
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, nil, nil); err != nil {
+		return nil, err
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if abs(shiftFrom) >= src.Len() || abs(shiftTo) >= src.Len() {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shifts must be smaller than the data size")
+	}
+	return shiftSweep(context.Background(), shiftFrom, shiftTo, binsX, binsY, minX, maxX, minY, maxY, nil, nil, src, shiftStep, ShiftOptions{})
+}
+
+// shiftSweep calculates the mutual information for every shift of the
+// validated sweep on a pool of opts.Workers goroutines.
+func shiftSweep(ctx context.Context, shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, edgesX, edgesY []float64, src XYSource, shiftStep int, opts ShiftOptions) ([]float64, error) {
+	numShifts := (shiftTo-shiftFrom)/shiftStep + 1
+	mi := make([]float64, numShifts)
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	// Chunked filling spends the workers within each shift instead.
+	var chunked []*Histogram2D
+	if opts.ChunkSize > 0 {
+		chunked = make([]*Histogram2D, workers)
+		for i := range chunked {
+			chunked[i] = NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+		}
+		workers = 1
+	}
+	if workers > numShifts {
+		workers = numShifts
+	}
+
+	lo, hi := 0, src.Len()
+	if opts.CommonWindow {
+		lo, hi = maxInt(0, -shiftFrom), minInt(hi, hi-shiftTo)
+	}
+
+	// Without a dead zone or recalibration the bins of a value are the same
+	// in every shift.
+	var indicesX, indicesY []int32
+	precomputed := !opts.RecalibrateRange && opts.DeadZone == 0
+	if precomputed {
+		indicesX, indicesY = pairIndices(src, edgesX, edgesY, minX, maxX, minY, maxY, binsX, binsY)
+	}
+	fill := func(hist shiftHistogram, shift int) {
+		if chunked != nil {
+			fillShiftedIndicesChunked(hist.(*Histogram2D), chunked, shift, lo, hi, opts.ChunkSize, minX, maxX, minY, maxY, indicesX, indicesY)
+			return
+		}
+		if precomputed {
+			fillShiftedIndices(hist, shift, lo, hi, minX, maxX, minY, maxY, indicesX, indicesY)
+			return
+		}
+		fillShiftedHistogram(hist, shift, lo, hi, minX, maxX, minY, maxY, src, opts)
+	}
+
+	tick := progressTicker(opts.Progress, numShifts)
+	shifts := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if opts.Sparse {
+				hist := NewSparseHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+				hist.EdgesX, hist.EdgesY = edgesX, edgesY
+				for shift := range shifts {
+					fill(hist, shift)
+					if opts.observe != nil {
+						opts.observe((shift-shiftFrom)/shiftStep, hist)
+					}
+					hx, hy, hxy := hist.Entropies()
+					mi[(shift-shiftFrom)/shiftStep] = normalizeMutualInformation(hx, hy, hxy, opts.Normalization)
+					tick()
+				}
+				return
+			}
+			hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+			hist.EdgesX, hist.EdgesY = edgesX, edgesY
+			for shift := range shifts {
+				fill(hist, shift)
+				if opts.observe != nil {
+					opts.observe((shift-shiftFrom)/shiftStep, hist)
+				}
+				if opts.BiasCorrection != BiasNone {
+					mi[(shift-shiftFrom)/shiftStep], _, _ = hist.CalculateMutualInformationCorrected(opts.BiasCorrection)
+				} else {
+					mi[(shift-shiftFrom)/shiftStep] = hist.CalculateNormalizedMutualInformation(opts.Normalization)
+				}
+				tick()
+			}
+		}()
+	}
+send:
+	for i := shiftFrom; i <= shiftTo; i += shiftStep {
+		select {
+		case shifts <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(shifts)
+
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Normalization == NormalizationNone && opts.Unit != UnitBits {
+		for i := range mi {
+			mi[i] = opts.Unit.FromBits(mi[i])
+		}
+	}
+	return mi, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,700 @@
+package mutualinfo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// almostEqual reports whether a and b differ by at most tol.
+func almostEqual(a, b, tol float64) bool {
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= tol
+}
+
+func TestShiftedMutualInformationAlignShorter(t *testing.T) {
+	dataX := []float64{0, 1, 2, 3, 4, 5, 6, 7}
+	dataY := []float64{0, 1, 2, 3, 4, 5}
+	if _, err := ShiftedMutualInformation(-1, 1, 4, 4, 0, 7, 0, 7, dataX, dataY, 1); err == nil {
+		t.Fatal("expected error for differing lengths by default")
+	}
+
+	var warnings []string
+	opts := ShiftOptions{AlignShorter: true, Warn: func(msg string) { warnings = append(warnings, msg) }}
+	got, err := ShiftedMutualInformationWithOptions(-1, 1, 4, 4, 0, 7, 0, 7, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ShiftedMutualInformation(-1, 1, 4, 4, 0, 7, 0, 7, dataX[:6], dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if !almostEqual(got[i], want[i], 1e-12) {
+			t.Errorf("shift %d: got %v, want %v", i-1, got[i], want[i])
+		}
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected one warning, got %v", warnings)
+	}
+}
+
+func TestShiftedMutualInformationSingleShift(t *testing.T) {
+	dataX := []float64{0, 1, 2, 3, 4, 5, 6, 7}
+	dataY := []float64{1, 2, 3, 4, 5, 6, 7, 0}
+	single, err := ShiftedMutualInformation(1, 1, 4, 4, 0, 7, 0, 7, dataX, dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sweep, err := ShiftedMutualInformation(-1, 1, 4, 4, 0, 7, 0, 7, dataX, dataY, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single) != 1 || single[0] != sweep[2] {
+		t.Errorf("single shift gave %v, sweep gave %v", single, sweep)
+	}
+	if _, err := ShiftedMutualInformation(2, 1, 4, 4, 0, 7, 0, 7, dataX, dataY, 1); err == nil {
+		t.Error("expected error for shiftFrom > shiftTo")
+	}
+}
+
+func TestShiftedMutualInformationWinsorize(t *testing.T) {
+	dataX := make([]float64, 100)
+	dataY := make([]float64, 100)
+	for i := range dataX {
+		dataX[i] = float64(i)
+		dataY[i] = float64(i)
+	}
+	dataX[99] = 1e6
+	mi, err := ShiftedMutualInformationWithOptions(0, 0, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Winsorize: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without clipping the outlier would squeeze all other samples into the first bin.
+	if mi[0] < 1.5 {
+		t.Errorf("expected winsorized MI to resolve the linear relation, got %v", mi[0])
+	}
+	if _, err := ShiftedMutualInformationWithOptions(0, 0, 4, 4, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Winsorize: 0.5}); err == nil {
+		t.Error("expected error for winsorize >= 0.5")
+	}
+}
+
+func TestShiftedHistogramAlignment(t *testing.T) {
+	dataX := []float64{0, 1, 2, 3, 4, 5, 6, 7}
+	dataY := []float64{2, 3, 4, 5, 6, 7, 0, 1}
+	for _, shift := range []int{-5, -2, 0, 2, 5} {
+		hist := shiftedHistogram(shift, 8, 8, 0, 8, 0, 8, SlicePairs{X: dataX, Y: dataY}, ShiftOptions{})
+		if _, _, total := hist.marginalCounts(); total != len(dataX)-abs(shift) {
+			t.Errorf("shift %d: expected %d pairs, got %d", shift, len(dataX)-abs(shift), total)
+		}
+	}
+	// dataY[t] == dataX[t+2] for t < 6, so shift 2 puts every pair on the diagonal.
+	hist := shiftedHistogram(2, 8, 8, 0, 8, 0, 8, SlicePairs{X: dataX, Y: dataY}, ShiftOptions{})
+	for i := range hist.Data {
+		for j := range hist.Data[i] {
+			if i != j && hist.Data[i][j] != 0 {
+				t.Errorf("shift 2: off-diagonal pair (%d, %d)", i, j)
+			}
+		}
+	}
+}
+
+func TestShiftedMutualInformationOneSidedRanges(t *testing.T) {
+	n := 3000
+	rng := rand.New(rand.NewSource(5))
+	dataX := make([]float64, n)
+	for i := range dataX {
+		dataX[i] = float64(rng.Intn(8)) + 0.5
+	}
+	// lead[j] = dataX[j+7] and lag[j] = dataX[j-7], wrapping around at the ends.
+	lead := make([]float64, n)
+	lag := make([]float64, n)
+	for j := range dataX {
+		lead[j] = dataX[(j+7)%n]
+		lag[j] = dataX[(j-7+n)%n]
+	}
+
+	cases := []struct {
+		from, to int
+		dataY    []float64
+		peak     int
+	}{
+		{5, 20, lead, 7},
+		{-20, -5, lag, -7},
+	}
+	for _, c := range cases {
+		mi, err := ShiftedMutualInformation(c.from, c.to, 8, 8, 0, 8, 0, 8, dataX, c.dataY, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		best := 0
+		for i := range mi {
+			if mi[i] > mi[best] {
+				best = i
+			}
+		}
+		if shift := c.from + best; shift != c.peak {
+			t.Errorf("range %d..%d: expected peak at %d, got %d (%v)", c.from, c.to, c.peak, shift, mi)
+		}
+	}
+}
+
+func TestShiftedMutualInformationDeadZone(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	n := 4000
+	dataX := make([]float64, n)
+	dataY := make([]float64, n)
+	for i := range dataX {
+		if i%2 == 0 {
+			// Independent noise floor around zero.
+			dataX[i] = (rng.Float64() - 0.5) * 0.1
+			dataY[i] = (rng.Float64() - 0.5) * 0.1
+		} else {
+			dataX[i] = rng.Float64()*2 - 1
+			dataY[i] = dataX[i]
+		}
+	}
+	plain, _ := ShiftedMutualInformation(0, 0, 8, 8, -1, 1, -1, 1, dataX, dataY, 1)
+	filtered, err := ShiftedMutualInformationWithOptions(0, 0, 8, 8, -1, 1, -1, 1, dataX, dataY, 1, ShiftOptions{DeadZone: 0.05})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered[0] <= plain[0] || filtered[0] < 2.9 {
+		t.Errorf("expected dead zone to remove the noise floor: plain %v, filtered %v", plain[0], filtered[0])
+	}
+}
+
+func TestBinBoundaries(t *testing.T) {
+	min, max := -1.0, 3.0
+	beyond := math.Nextafter(max, math.Inf(1))
+	below := math.Nextafter(min, math.Inf(-1))
+	values := []float64{min, max, beyond, below, math.Nextafter(max, math.Inf(-1))}
+	want := []int{0, 3, -1, -1, 3}
+
+	indices, err := CalculateIndices1D(4, min, max, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs, err := CalculateIndices2D(4, 4, min, max, min, max, values, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		if indices[i] != want[i] || pairs[i].First != want[i] || pairs[i].Second != want[i] {
+			t.Errorf("value %v: 1D index %d, 2D index %+v, want %d", v, indices[i], pairs[i], want[i])
+		}
+
+		hist := NewHistogram2D(4, 4, min, max, min, max)
+		hist.Increment(v, v)
+		if want[i] < 0 {
+			if _, _, total := hist.marginalCounts(); total != 0 {
+				t.Errorf("value %v: Increment counted an out-of-range pair", v)
+			}
+		} else if hist.Data[want[i]][want[i]] != 1 {
+			t.Errorf("value %v: Increment disagrees with CalculateIndices2D", v)
+		}
+	}
+
+	// Only one axis beyond its maximum drops the pair in both code paths.
+	pairs, _ = CalculateIndices2D(4, 4, min, max, min, max, []float64{max}, []float64{beyond})
+	hist := NewHistogram2D(4, 4, min, max, min, max)
+	hist.Increment(max, beyond)
+	if _, _, total := hist.marginalCounts(); pairs[0].First != -1 || total != 0 {
+		t.Errorf("mixed boundary pair: indices %+v, histogram total %d", pairs[0], total)
+	}
+}
+
+func TestNonFiniteRanges(t *testing.T) {
+	data := []float64{0, 1, 2}
+	for _, bad := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := CalculateIndices1D(2, bad, 2, data); err == nil {
+			t.Errorf("CalculateIndices1D: expected error for min=%v", bad)
+		}
+		if _, err := CalculateIndices1D(2, 0, bad, data); err == nil {
+			t.Errorf("CalculateIndices1D: expected error for max=%v", bad)
+		}
+		if _, err := CalculateIndices2D(2, 2, 0, 2, bad, 2, data, data); err == nil {
+			t.Errorf("CalculateIndices2D: expected error for minY=%v", bad)
+		}
+		if _, err := ShiftedMutualInformation(0, 1, 2, 2, 0, bad, 0, 2, data, data, 1); err == nil {
+			t.Errorf("ShiftedMutualInformation: expected error for maxX=%v", bad)
+		}
+	}
+}
+
+func TestShiftedMutualInformationRecalibrateRange(t *testing.T) {
+	// Shift 1 drops dataX[0] and dataY[9], the only extreme values.
+	dataX := []float64{-100, 0, 1, 2, 3, 0, 1, 2, 3, 0}
+	dataY := []float64{0, 1, 2, 3, 0, 1, 2, 3, 0, 100}
+	global, _ := ShiftedMutualInformation(1, 1, 4, 4, -100, 100, -100, 100, dataX, dataY, 1)
+	recalibrated, err := ShiftedMutualInformationWithOptions(1, 1, 4, 4, -100, 100, -100, 100, dataX, dataY, 1, ShiftOptions{RecalibrateRange: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With the global range all remaining values share a single bin.
+	if global[0] != 0 || !almostEqual(recalibrated[0], entropyOf([]float64{3, 2, 2, 2}), 1e-12) {
+		t.Errorf("expected finer bins for the shift: global %v, recalibrated %v", global, recalibrated)
+	}
+}
+
+func TestNarrowRangeAtLargeOffset(t *testing.T) {
+	data := []float64{1e6, 1e6, 1e6}
+	if _, err := MutualInformation(4, 4, 1e6, 1e6+1e-12, 0, 1, data, []float64{0, 0.5, 1}); err == nil {
+		t.Error("expected error for a range below float64 resolution")
+	}
+	if _, err := CalculateIndices1D(4, 1e6, 1e6+1e-12, data); err == nil {
+		t.Error("CalculateIndices1D: expected error for a range below float64 resolution")
+	}
+
+	// The same band without the offset bins fine.
+	residuals := []float64{0, 0.5e-12, 1e-12}
+	indices, err := CalculateIndices1D(4, 0, 1e-12, residuals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indices[0] != 0 || indices[1] != 2 || indices[2] != 3 {
+		t.Errorf("unexpected indices %v", indices)
+	}
+}
+
+func TestEntropies(t *testing.T) {
+	// Both marginals are uniform over two bins, the joint cells hold 2, 1, 1, 2 pairs.
+	hist := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	for _, p := range [][2]float64{{0.5, 0.5}, {0.5, 0.5}, {0.5, 1.5}, {1.5, 0.5}, {1.5, 1.5}, {1.5, 1.5}} {
+		hist.Increment(p[0], p[1])
+	}
+	hx, hy, hxy := hist.Entropies()
+	wantHXY := -2*(1.0/3)*math.Log2(1.0/3) - 2*(1.0/6)*math.Log2(1.0/6)
+	if !almostEqual(hx, 1, 1e-12) || !almostEqual(hy, 1, 1e-12) || !almostEqual(hxy, wantHXY, 1e-12) {
+		t.Errorf("entropies = %v, %v, %v, want 1, 1, %v", hx, hy, hxy, wantHXY)
+	}
+	if mi := hist.CalculateMutualInformation(); mi != hx+hy-hxy {
+		t.Errorf("MI %v differs from hx + hy - hxy = %v", mi, hx+hy-hxy)
+	}
+}
+
+func TestEntropiesBase(t *testing.T) {
+	hist := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	hist.Increment(0.5, 0.5)
+	hist.Increment(1.5, 1.5)
+	if mi := hist.CalculateMutualInformationBase(math.E); !almostEqual(mi, math.Ln2, 1e-12) {
+		t.Errorf("MI in nats = %v, want ln 2", mi)
+	}
+	if mi := hist.CalculateMutualInformationBase(2); mi != hist.CalculateMutualInformation() {
+		t.Errorf("MI in base 2 = %v, want %v", mi, hist.CalculateMutualInformation())
+	}
+	hx, _, hxy := hist.EntropiesBase(10)
+	if !almostEqual(hx, math.Log10(2), 1e-12) || !almostEqual(hxy, math.Log10(2), 1e-12) {
+		t.Errorf("entropies in base 10 = %v, %v, want log10(2)", hx, hxy)
+	}
+	for _, bad := range []float64{0, 1, -2, math.Inf(1), math.NaN()} {
+		if mi := hist.CalculateMutualInformationBase(bad); !math.IsNaN(mi) {
+			t.Errorf("base %v: got %v, want NaN", bad, mi)
+		}
+	}
+}
+
+func TestIncrementOutOfRange(t *testing.T) {
+	hist := NewHistogram2D(4, 4, 0, 4, 0, 4)
+	for _, p := range [][2]float64{
+		{0, 0}, {4, 4}, {2, 2}, // inside, including both boundaries
+		{-1e300, 2}, {2, -0.5}, // below min
+		{4.5, 2}, {2, 1e300}, // above max
+		{math.NaN(), 2}, {2, math.Inf(1)},
+	} {
+		hist.Increment(p[0], p[1])
+	}
+	if _, _, total := hist.marginalCounts(); total != 3 {
+		t.Errorf("counted %d pairs, want 3", total)
+	}
+	if hist.OutOfRange != 6 {
+		t.Errorf("OutOfRange = %d, want 6", hist.OutOfRange)
+	}
+	if want := (RangeCounts{UnderX: 1, OverX: 1, UnderY: 1, OverY: 2}); hist.Outside != want {
+		t.Errorf("Outside = %+v, want %+v", hist.Outside, want)
+	}
+	if hist.Data[0][0] != 1 || hist.Data[3][3] != 1 || hist.Data[2][2] != 1 {
+		t.Errorf("unexpected counts %v", hist.Data)
+	}
+}
+
+func TestShiftedMutualInformationWorkers(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	dataX := make([]float64, 500)
+	dataY := make([]float64, 500)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	want, err := ShiftedMutualInformation(-20, 20, 8, 8, 0, 1, 0, 1, dataX, dataY, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{1, 2, 100} {
+		got, err := ShiftedMutualInformationWithOptions(-20, 20, 8, 8, 0, 1, 0, 1, dataX, dataY, 3, ShiftOptions{Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("workers=%d: shift %d gives %v, want %v", workers, -20+3*i, got[i], want[i])
+			}
+		}
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-2, 2, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{Workers: -1}); err == nil {
+		t.Error("expected error for negative workers")
+	}
+}
+
+func TestShiftedMutualInformationChunked(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	dataX := make([]float64, 1000)
+	dataY := make([]float64, 1000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()*1.2 - 0.1
+		dataY[i] = dataX[(i+3)%len(dataX)] + 0.1*rng.NormFloat64()
+	}
+	want, err := ShiftedMutualInformationWithOptions(-10, 10, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, ShiftOptions{CommonWindow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunkSize := range []int{1, 7, 100, 5000} {
+		got, err := ShiftedMutualInformationWithOptions(-10, 10, 8, 8, 0, 1, 0, 1, dataX, dataY, 2, ShiftOptions{CommonWindow: true, ChunkSize: chunkSize, Workers: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("chunkSize=%d: shift %d gives %v, want %v", chunkSize, -10+2*i, got[i], want[i])
+			}
+		}
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-2, 2, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{ChunkSize: -1}); err == nil {
+		t.Error("expected error for negative chunkSize")
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-2, 2, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{ChunkSize: 10, Sparse: true}); err == nil {
+		t.Error("expected error for chunked sparse histograms")
+	}
+}
+
+func BenchmarkShiftedMutualInformationChunked(b *testing.B) {
+	rng := rand.New(rand.NewSource(17))
+	dataX := make([]float64, 1000000)
+	dataY := make([]float64, len(dataX))
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ShiftedMutualInformationWithOptions(0, 0, 16, 16, 0, 1, 0, 1, dataX, dataY, 1, ShiftOptions{ChunkSize: 65536}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkShiftedMutualInformationWideSweep(b *testing.B) {
+	rng := rand.New(rand.NewSource(7))
+	dataX := make([]float64, 20000)
+	dataY := make([]float64, 20000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ShiftedMutualInformation(-2000, 2000, 50, 50, 0, 1, 0, 1, dataX, dataY, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkShiftedMutualInformation(b *testing.B) {
+	rng := rand.New(rand.NewSource(7))
+	dataX := make([]float64, 100000)
+	dataY := make([]float64, 100000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ShiftedMutualInformation(-100, 100, 50, 50, 0, 1, 0, 1, dataX, dataY, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestShiftSweepReusesHistogram(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	dataX := make([]float64, 300)
+	dataY := make([]float64, 300)
+	for i := range dataX {
+		dataX[i] = rng.NormFloat64()
+		dataY[i] = rng.NormFloat64()
+	}
+	opts := ShiftOptions{Workers: 1, RecalibrateRange: true, DeadZone: 0.2}
+	got, err := ShiftedMutualInformationWithOptions(-10, 10, 6, 6, -4, 4, -4, 4, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, shift := 0, -10; shift <= 10; i, shift = i+1, shift+1 {
+		// A fresh histogram per shift, read through the XYSource interface.
+		hist := shiftedHistogram(shift, 6, 6, -4, 4, -4, 4, pairFunc{len(dataX), func(j int) (float64, float64) { return dataX[j], dataY[j] }}, opts)
+		if want := hist.CalculateMutualInformation(); got[i] != want {
+			t.Errorf("shift %d: reused histogram gives %v, fresh one %v", shift, got[i], want)
+		}
+	}
+}
+
+// pairFunc is an XYSource backed by a function.
+type pairFunc struct {
+	n  int
+	at func(i int) (float64, float64)
+}
+
+func (p pairFunc) Len() int { return p.n }
+
+func (p pairFunc) At(i int) (x, y float64) { return p.at(i) }
+
+func TestNonFiniteData(t *testing.T) {
+	for _, bad := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		data := []float64{0.5, bad, 1.5, 2.5}
+		clean := []float64{0.5, 1.5, 1.5, 2.5}
+
+		indices, err := CalculateIndices1D(4, 0, 4, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if indices[1] != -1 {
+			t.Errorf("CalculateIndices1D(%v) = %d, want -1", bad, indices[1])
+		}
+		pairs, err := CalculateIndices2D(4, 4, 0, 4, 0, 4, clean, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pairs[1] != (IndexPair{First: -1, Second: -1}) {
+			t.Errorf("CalculateIndices2D(%v) = %+v, want {-1, -1}", bad, pairs[1])
+		}
+
+		mi, err := ShiftedMutualInformation(0, 0, 4, 4, 0, 4, 0, 4, data, clean, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := ShiftedMutualInformation(0, 0, 4, 4, 0, 4, 0, 4, []float64{0.5, 1.5, 2.5}, []float64{0.5, 1.5, 2.5}, 1)
+		if mi[0] != want[0] {
+			t.Errorf("%v: MI %v, want %v without the pair", bad, mi[0], want[0])
+		}
+		_, err = ShiftedMutualInformationWithOptions(0, 0, 4, 4, 0, 4, 0, 4, clean, data, 1, ShiftOptions{RejectNonFinite: true})
+		if err == nil || err.Error() != fmt.Sprintf("dataY[1] is %v", bad) {
+			t.Errorf("%v: RejectNonFinite gives error %v", bad, err)
+		}
+	}
+	if err := CheckFinite("data", []float64{1, 2}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestGenericNumberInput(t *testing.T) {
+	ints := []int{0, 1, 2, 3, 4, 5, 6, 7, 0, 2, 4, 6, 1, 3, 5, 7}
+	floats := make([]float64, len(ints))
+	singles := make([]float32, len(ints))
+	for i, v := range ints {
+		floats[i] = float64(v)
+		singles[i] = float32(v)
+	}
+
+	want, err := ShiftedMutualInformation(-2, 2, 4, 4, 0, 8, 0, 8, floats, floats, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromInts, err := ShiftedMutualInformation(-2, 2, 4, 4, 0, 8, 0, 8, ints, ints, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSingles, err := ShiftedMutualInformation[float32](-2, 2, 4, 4, 0, 8, 0, 8, singles, singles, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if fromInts[i] != want[i] || fromSingles[i] != want[i] {
+			t.Errorf("shift %d: int %v, float32 %v, want %v", i-2, fromInts[i], fromSingles[i], want[i])
+		}
+	}
+
+	indices, err := CalculateIndices1D(4, 0, 8, ints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs, err := CalculateIndices2D[int64](4, 4, 0, 8, 0, 8, []int64{1, 9}, []int64{7, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indices[7] != 3 || pairs[0] != (IndexPair{First: 0, Second: 3}) || pairs[1].First != -1 {
+		t.Errorf("unexpected indices %v and %v", indices, pairs)
+	}
+	if _, err := ShiftedMutualInformation(0, 1, 4, 4, 0, 8, 0, 8, ints, ints[1:], 1); err == nil {
+		t.Error("expected error for a length mismatch")
+	}
+}
+
+func TestShiftedMutualInformationCommonWindow(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	dataX := make([]float64, 2000)
+	dataY := make([]float64, 2000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	// Mean MI of the outer and of the inner four shifts.
+	edgeOverCenter := func(mi []float64) (edge, center float64) {
+		m := len(mi) / 2
+		edge = (mi[0] + mi[1] + mi[len(mi)-2] + mi[len(mi)-1]) / 4
+		center = (mi[m-2] + mi[m-1] + mi[m+1] + mi[m+2]) / 4
+		return edge, center
+	}
+
+	plain, err := ShiftedMutualInformation(-900, 900, 8, 8, 0, 1, 0, 1, dataX, dataY, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edge, center := edgeOverCenter(plain); edge < 1.5*center {
+		t.Errorf("expected the usual rise at the edges: edge %v, center %v", edge, center)
+	}
+	common, err := ShiftedMutualInformationWithOptions(-900, 900, 8, 8, 0, 1, 0, 1, dataX, dataY, 100, ShiftOptions{CommonWindow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edge, center := edgeOverCenter(common); math.Abs(edge-center) > 0.1*center {
+		t.Errorf("common window curve is not flat: edge %v, center %v", edge, center)
+	}
+
+	// Shift 0 of a one-sided sweep equals the plain MI of the window.
+	one, _ := ShiftedMutualInformationWithOptions(0, 10, 8, 8, 0, 1, 0, 1, dataX, dataY, 10, ShiftOptions{CommonWindow: true})
+	want, _ := MutualInformation(8, 8, 0, 1, 0, 1, dataX[:1990], dataY[:1990])
+	if one[0] != want {
+		t.Errorf("shift 0 gives %v, want %v", one[0], want)
+	}
+	if _, err := ShiftedMutualInformationWithOptions(-1000, 1000, 8, 8, 0, 1, 0, 1, dataX, dataY, 100, ShiftOptions{CommonWindow: true}); err == nil {
+		t.Error("expected error when no common window remains")
+	}
+}
+
+func TestFillShiftedIndicesMatchesPerPair(t *testing.T) {
+	rng := rand.New(rand.NewSource(39))
+	dataX := make([]float64, 500)
+	dataY := make([]float64, 500)
+	for i := range dataX {
+		dataX[i] = rng.Float64()*1.2 - 0.1
+		dataY[i] = rng.Float64()
+	}
+	dataX[4], dataY[9] = math.NaN(), math.Inf(-1)
+	src := SlicePairs{X: dataX, Y: dataY}
+	edges := []float64{0, 0.1, 0.5, 1}
+	for _, e := range [][]float64{nil, edges} {
+		indicesX, indicesY := pairIndices(src, e, e, 0, 1, 0, 1, 7, 7)
+		bins := 7
+		if e != nil {
+			bins = len(e) - 1
+		}
+		for _, shift := range []int{-20, 0, 13} {
+			perPair := NewHistogram2D(bins, bins, 0, 1, 0, 1)
+			perPair.EdgesX, perPair.EdgesY = e, e
+			fillShiftedHistogram(perPair, shift, 0, 500, 0, 1, 0, 1, src, ShiftOptions{})
+			indexed := NewHistogram2D(bins, bins, 0, 1, 0, 1)
+			fillShiftedIndices(indexed, shift, 0, 500, 0, 1, 0, 1, indicesX, indicesY)
+			if indexed.OutOfRange != perPair.OutOfRange || indexed.CalculateMutualInformation() != perPair.CalculateMutualInformation() {
+				t.Errorf("edges %v, shift %d: indexed %v (%d out), per pair %v (%d out)", e, shift,
+					indexed.CalculateMutualInformation(), indexed.OutOfRange, perPair.CalculateMutualInformation(), perPair.OutOfRange)
+			}
+		}
+	}
+}
+
+func benchmarkFill1e7(b *testing.B, indexed bool) {
+	rng := rand.New(rand.NewSource(7))
+	dataX := make([]float64, 10000000)
+	dataY := make([]float64, 10000000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	src := SlicePairs{X: dataX, Y: dataY}
+	hist := NewHistogram2D(50, 50, 0, 1, 0, 1)
+	indicesX, indicesY := pairIndices(src, nil, nil, 0, 1, 0, 1, 50, 50)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if indexed {
+			fillShiftedIndices(hist, 5, 0, len(dataX), 0, 1, 0, 1, indicesX, indicesY)
+		} else {
+			fillShiftedHistogram(hist, 5, 0, len(dataX), 0, 1, 0, 1, src, ShiftOptions{})
+		}
+	}
+}
+
+// The per-shift cost of a sweep over 1e7 pairs with and without precomputed
+// bin indices.
+func BenchmarkFillShift1e7PerPair(b *testing.B) { benchmarkFill1e7(b, false) }
+func BenchmarkFillShift1e7Indexed(b *testing.B) { benchmarkFill1e7(b, true) }
+
+func TestShiftedMutualInformationContext(t *testing.T) {
+	rng := rand.New(rand.NewSource(40))
+	dataX := make([]float64, 2000)
+	dataY := make([]float64, 2000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = rng.Float64()
+	}
+	opts := ShiftOptions{Workers: 2}
+	got, err := ShiftedMutualInformationContext(context.Background(), -50, 50, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ShiftedMutualInformationWithOptions(-50, 50, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, opts)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("shift %d: %v with context, %v without", i-50, got[i], want[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if mi, err := ShiftedMutualInformationContext(ctx, -50, 50, 8, 8, 0, 1, 0, 1, dataX, dataY, 1, opts); err != context.Canceled || mi != nil {
+		t.Errorf("cancelled sweep returned %v, %v, want no results and context.Canceled", mi, err)
+	}
+}
+
+func TestFloat32Estimators(t *testing.T) {
+	rng := rand.New(rand.NewSource(18))
+	dataX := make([]float64, 300)
+	dataY := make([]float64, len(dataX))
+	dataX32 := make([]float32, len(dataX))
+	dataY32 := make([]float32, len(dataX))
+	for i := range dataX {
+		dataX32[i] = float32(rng.Float64())
+		dataY32[i] = float32(rng.Float64())*0.5 + dataX32[i]*0.5
+		dataX[i], dataY[i] = float64(dataX32[i]), float64(dataY32[i])
+	}
+	want, _ := MutualInformation(8, 8, 0, 1, 0, 1, dataX, dataY)
+	if got, err := MutualInformation[float32](8, 8, 0, 1, 0, 1, dataX32, dataY32); err != nil || got != want {
+		t.Errorf("MutualInformation gives %v, %v, want %v", got, err, want)
+	}
+	wantH, _ := JointEntropy2D(8, 8, 0, 1, 0, 1, dataX, dataY)
+	if got, err := JointEntropy2D[float32](8, 8, 0, 1, 0, 1, dataX32, dataY32); err != nil || got != wantH {
+		t.Errorf("JointEntropy2D gives %v, %v, want %v", got, err, wantH)
+	}
+	hist := NewHistogram2D(8, 8, 0, 1, 0, 1)
+	if err := FillHistogram2D(hist, dataX32, dataY32); err != nil {
+		t.Fatal(err)
+	}
+	if got := hist.CalculateMutualInformation(); got != want {
+		t.Errorf("FillHistogram2D gives MI %v, want %v", got, want)
+	}
+	if err := FillHistogram2D(hist, dataX32, dataY32[1:]); err == nil {
+		t.Error("expected error for slices of different sizes")
+	}
+}
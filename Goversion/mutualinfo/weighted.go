@@ -0,0 +1,98 @@
+package mutualinfo
+
+import (
+	"math"
+)
+
+// validateWeights checks that there is one non-negative, finite weight per
+// sample and that the weights do not all vanish.
+func validateWeights(weights []float64, n int) error {
+	if len(weights) != n {
+		return invalid(ErrSizeMismatch, "weights", "there must be one weight per sample")
+	}
+	var total neumaierSum
+	for _, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 1) {
+			return invalid(ErrInvalidData, "weights", "weights must be finite and not negative")
+		}
+		total.Add(w)
+	}
+	if !(total.Value() > 0) {
+		return invalid(ErrInvalidData, "weights", "weights must have a positive sum")
+	}
+	return nil
+}
+
+// WeightedEntropy1D is Entropy1D with every value of data counted with its
+// weight, e.g. an inverse sampling probability.
+func WeightedEntropy1D(bins int, min, max float64, data, weights []float64) (float64, error) {
+	indices, err := CalculateIndices1D(bins, min, max, data)
+	if err != nil {
+		return 0, err
+	}
+	if err := validateWeights(weights, len(data)); err != nil {
+		return 0, err
+	}
+	sums := make([]neumaierSum, bins)
+	for i, index := range indices {
+		if index >= 0 {
+			sums[index].Add(weights[i])
+		}
+	}
+	totals := make([]float64, bins)
+	for i := range sums {
+		totals[i] = sums[i].Value()
+	}
+	return entropyOf(totals), nil
+}
+
+// WeightedMutualInformation is MutualInformation with the pair (dataX[i],
+// dataY[i]) counted with weights[i], see Histogram2D.IncrementWeighted. The
+// result is NaN if all pairs in range have zero weight.
+func WeightedMutualInformation(binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY, weights []float64) (float64, error) {
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return 0, err
+	}
+	if err := validateWeights(weights, len(dataX)); err != nil {
+		return 0, err
+	}
+	hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	for i := range dataX {
+		hist.IncrementWeighted(dataX[i], dataY[i], weights[i])
+	}
+	return hist.CalculateMutualInformation(), nil
+}
+
+// ShiftedWeightedMutualInformation is ShiftedMutualInformation with weighted
+// pairs. The pair (dataX[j+shift], dataY[j]) of a shift is counted with
+// weights[j], the weight of its dataY sample, so that dataY is weighted the
+// same way in every shift.
+func ShiftedWeightedMutualInformation(shiftFrom, shiftTo, binsX, binsY int, minX, maxX, minY, maxY float64, dataX, dataY, weights []float64, shiftStep int) ([]float64, error) {
+	if shiftFrom > shiftTo {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shiftFrom must not be greater than shiftTo")
+	}
+	if err := validate2D(binsX, binsY, minX, maxX, minY, maxY, dataX, dataY); err != nil {
+		return nil, err
+	}
+	if shiftStep < 1 {
+		return nil, invalid(ErrInvalidShift, "shiftStep", "shiftStep must be greater or equal 1")
+	}
+	if abs(shiftFrom) >= len(dataX) || abs(shiftTo) >= len(dataX) {
+		return nil, invalid(ErrInvalidShift, "shiftFrom", "shifts must be smaller than the data size")
+	}
+	if err := validateWeights(weights, len(dataY)); err != nil {
+		return nil, err
+	}
+
+	n := len(dataX)
+	mi := make([]float64, 0, (shiftTo-shiftFrom)/shiftStep+1)
+	hist := NewHistogram2D(binsX, binsY, minX, maxX, minY, maxY)
+	for shift := shiftFrom; shift <= shiftTo; shift += shiftStep {
+		hist.reset(minX, maxX, minY, maxY)
+		for j := maxInt(0, -shift); j < minInt(n, n-shift); j++ {
+			hist.IncrementWeighted(dataX[j+shift], dataY[j], weights[j])
+		}
+		mi = append(mi, hist.CalculateMutualInformation())
+	}
+	return mi, nil
+}
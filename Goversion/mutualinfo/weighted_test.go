@@ -0,0 +1,145 @@
+package mutualinfo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIncrementWeighted(t *testing.T) {
+	rng := rand.New(rand.NewSource(15))
+	dataX := make([]float64, 1000)
+	dataY := make([]float64, 1000)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.5*dataX[i] + 0.5*rng.Float64()
+	}
+	plain := NewHistogram2D(6, 6, 0, 1, 0, 1)
+	for i := range dataX {
+		plain.Increment(dataX[i], dataY[i])
+	}
+	want := plain.CalculateMutualInformation()
+
+	for _, w := range []float64{1, 0.25} {
+		hist := NewHistogram2D(6, 6, 0, 1, 0, 1)
+		for i := range dataX {
+			if err := hist.IncrementWeighted(dataX[i], dataY[i], w); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := hist.CalculateMutualInformation(); got != want {
+			t.Errorf("uniform weight %v: MI %v, want exactly %v", w, got, want)
+		}
+	}
+
+	// Doubling the weight of a pair equals counting it twice.
+	weighted := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	weighted.IncrementWeighted(0.5, 0.5, 2)
+	weighted.Increment(1.5, 1.5)
+	weighted.Increment(0.5, 1.5)
+	counted := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	for _, p := range [][2]float64{{0.5, 0.5}, {0.5, 0.5}, {1.5, 1.5}, {0.5, 1.5}} {
+		counted.Increment(p[0], p[1])
+	}
+	if got, want := weighted.CalculateMutualInformation(), counted.CalculateMutualInformation(); !almostEqual(got, want, 1e-12) {
+		t.Errorf("weighted MI %v, want %v", got, want)
+	}
+
+	for _, bad := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if err := weighted.IncrementWeighted(0.5, 0.5, bad); err == nil {
+			t.Errorf("expected error for weight %v", bad)
+		}
+	}
+	zero := NewHistogram2D(2, 2, 0, 2, 0, 2)
+	zero.IncrementWeighted(0.5, 0.5, 0)
+	if mi := zero.CalculateMutualInformation(); !math.IsNaN(mi) {
+		t.Errorf("zero total weight gives MI %v, want NaN", mi)
+	}
+}
+
+func TestIncrementWeightedTinyWeights(t *testing.T) {
+	// 10⁷ weights of 1e-9 on a cell of 1e6 must not be lost to rounding.
+	hist := NewHistogram2D(2, 2, 0, 1, 0, 1)
+	hist.IncrementWeighted(0.1, 0.1, 1e6)
+	for i := 0; i < 10000000; i++ {
+		hist.IncrementWeighted(0.1, 0.1, 1e-9)
+	}
+	if got := hist.WeightedData[0][0]; got != 1000000.01 {
+		t.Errorf("cell weight %.17g, want 1000000.01", got)
+	}
+	merged := NewHistogram2D(2, 2, 0, 1, 0, 1)
+	merged.IncrementWeighted(0.9, 0.9, 1)
+	merged.Merge(hist)
+	if got := merged.WeightedData[0][0]; got != 1000000.01 {
+		t.Errorf("merged cell weight %.17g, want 1000000.01", got)
+	}
+
+	data := []float64{0.1, 0.9}
+	weights := []float64{1e6, 1e6 + 0.01}
+	for i := 0; i < 10000; i++ {
+		data = append(data, 0.1)
+		weights = append(weights, 1e-6)
+	}
+	// Both bins sum to 1000000.01, which gives exactly 1 bit.
+	if h, err := WeightedEntropy1D(2, 0, 1, data, weights); err != nil || h != 1 {
+		t.Errorf("WeightedEntropy1D = %.17g, %v, want 1", h, err)
+	}
+}
+
+func TestWeightedMutualInformation(t *testing.T) {
+	rng := rand.New(rand.NewSource(43))
+	dataX := make([]float64, 400)
+	dataY := make([]float64, 400)
+	ones := make([]float64, 400)
+	for i := range dataX {
+		dataX[i] = rng.Float64()
+		dataY[i] = 0.6*dataX[i] + 0.4*rng.Float64()
+		ones[i] = 1
+	}
+	want, _ := MutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY)
+	if got, err := WeightedMutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY, ones); err != nil || got != want {
+		t.Errorf("unit weights: MI %v (%v), want %v", got, err, want)
+	}
+
+	// An integer weight equals repeating the sample.
+	weights := make([]float64, len(dataX))
+	var repeatedX, repeatedY []float64
+	for i := range dataX {
+		weights[i] = float64(i % 3)
+		for r := 0; r < i%3; r++ {
+			repeatedX = append(repeatedX, dataX[i])
+			repeatedY = append(repeatedY, dataY[i])
+		}
+	}
+	got, err := WeightedMutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := MutualInformation(5, 5, 0, 1, 0, 1, repeatedX, repeatedY); !almostEqual(got, want, 1e-12) {
+		t.Errorf("weighted MI %v, repeated samples %v", got, want)
+	}
+	h, err := WeightedEntropy1D(5, 0, 1, dataX, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := Entropy1D(5, 0, 1, repeatedX); !almostEqual(h, want, 1e-12) {
+		t.Errorf("weighted entropy %v, repeated samples %v", h, want)
+	}
+
+	shifted, err := ShiftedWeightedMutualInformation(-2, 2, 5, 5, 0, 1, 0, 1, dataX, dataY, ones, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := ShiftedMutualInformation(-2, 2, 5, 5, 0, 1, 0, 1, dataX, dataY, 1)
+	for i := range plain {
+		if !almostEqual(shifted[i], plain[i], 1e-12) {
+			t.Errorf("shift %d: weighted %v, plain %v", i-2, shifted[i], plain[i])
+		}
+	}
+
+	for _, bad := range [][]float64{ones[1:], make([]float64, len(dataX)), append([]float64{-1}, ones[1:]...)} {
+		if _, err := WeightedMutualInformation(5, 5, 0, 1, 0, 1, dataX, dataY, bad); err == nil {
+			t.Error("expected error for invalid weights")
+		}
+	}
+}